@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal"
+	ssabuilder "symbolic-execution-course/internal/ssa"
+)
+
+// analyseFunctions исследует выбранные функции в SSA их пакетов. Ошибка
+// или паника анализатора в одной функции не прерывает анализ остальных:
+// отчёты возвращаются для всех успешно исследованных функций вместе с
+// ошибками остальных.
+func analyseFunctions(cfg *config, patterns []string) ([]functionReport, error) {
+	functions, err := loadFunctions(patterns, cfg.run)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []functionReport
+	var errs []error
+	for _, function := range functions {
+		report, err := analyseFunction(cfg, function)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", function.File, function.Function.Name(), err))
+			continue
+		}
+		reports = append(reports, report)
+	}
+	return reports, errors.Join(errs...)
+}
+
+// analyseFunction исследует одну функцию, превращая панику анализатора в
+// ошибку этой функции
+func analyseFunction(cfg *config, function packageFunction) (report functionReport, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("analysis panicked: %v", r)
+		}
+	}()
+	options, err := cfg.solverOptions()
+	if err != nil {
+		return functionReport{}, err
+	}
+	analyser := internal.NewAnalyser(options...)
+	analyser.Package = function.Function.Pkg
+	paths, cached, err := analyser.AnalyseFunctionCached(function.Function)
+	if err != nil {
+		return functionReport{}, err
+	}
+	name := function.Function.Name()
+	if tree := analyser.ExecutionTree(); tree != nil && !cached {
+		if err := writeTree(cfg.treeDir, function.Package+"."+name, tree); err != nil {
+			return functionReport{}, err
+		}
+	}
+	return functionReport{
+		Package:  function.Package,
+		File:     function.File,
+		Function: name,
+		Cached:   cached,
+		Paths:    paths,
+	}, nil
+}
+
+// writeTree сохраняет дерево исполнения функции в name.dot и name.json
+func writeTree(dir string, name string, tree *internal.ExecutionTree) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	base := filepath.Join(dir, strings.NewReplacer("/", "_", "\\", "_").Replace(name))
+	if err := os.WriteFile(base+".dot", []byte(tree.DOT(name)), 0o644); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(base+".json", data, 0o644)
+}
+
+// runAnalyse выводит отчёты исследованных функций и тогда, когда анализ
+// других завершился ошибкой; код возврата в этом случае — exitError.
+// Так же поступает runCheck.
+func runAnalyse(cfg *config, patterns []string, stdout io.Writer) (int, error) {
+	reports, analyseErr := analyseFunctions(cfg, patterns)
+	if err := writeReports(stdout, cfg.format, reports); err != nil {
+		return exitError, err
+	}
+	if analyseErr != nil {
+		return exitError, analyseErr
+	}
+	return exitOK, nil
+}
+
+func runCheck(cfg *config, patterns []string, stdout io.Writer) (int, error) {
+	reports, analyseErr := analyseFunctions(cfg, patterns)
+	if err := writeFindings(stdout, cfg.format, reports); err != nil {
+		return exitError, err
+	}
+	if analyseErr != nil {
+		return exitError, analyseErr
+	}
+	for _, report := range reports {
+		for _, path := range report.Paths {
+			if len(path.Findings) > 0 {
+				return exitFindings, nil
+			}
+		}
+	}
+	return exitOK, nil
+}
+
+// generateTests создаёт тестовые файлы для файлов с выбранными функциями.
+// Тесты генерируются для всего файла, -run лишь отбирает файлы.
+func generateTests(cfg *config, patterns []string) ([]string, error) {
+	files, err := loadFiles(patterns, cfg.run)
+	if err != nil {
+		return nil, err
+	}
+
+	var testFiles []string
+	for _, file := range files {
+		options, err := cfg.solverOptions()
+		if err != nil {
+			return nil, err
+		}
+		testFiles = append(testFiles, internal.GenerateTestFile(file.Path, options...))
+	}
+	return testFiles, nil
+}
+
+func runGenTests(cfg *config, patterns []string, stdout io.Writer) (int, error) {
+	testFiles, err := generateTests(cfg, patterns)
+	if err != nil {
+		return exitError, err
+	}
+	for _, testFile := range testFiles {
+		fmt.Fprintln(stdout, testFile)
+	}
+	return exitOK, nil
+}
+
+func runCoverage(cfg *config, patterns []string, stdout io.Writer) (int, error) {
+	testFiles, err := generateTests(cfg, patterns)
+	if err != nil {
+		return exitError, err
+	}
+
+	profile, err := os.CreateTemp("", "symgo-coverage-*.out")
+	if err != nil {
+		return exitError, err
+	}
+	profile.Close()
+	defer os.Remove(profile.Name())
+
+	dirs := make(map[string]bool)
+	for _, testFile := range testFiles {
+		dir := filepath.Dir(testFile)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+
+		test := exec.Command("go", "test", "-coverprofile="+profile.Name(), ".")
+		test.Dir = dir
+		test.Stdout, test.Stderr = stdout, os.Stderr
+		if err := test.Run(); err != nil {
+			return exitError, fmt.Errorf("go test %s: %w", dir, err)
+		}
+		cover := exec.Command("go", "tool", "cover", "-func="+profile.Name())
+		cover.Dir = dir
+		cover.Stdout, cover.Stderr = stdout, os.Stderr
+		if err := cover.Run(); err != nil {
+			return exitError, fmt.Errorf("go tool cover: %w", err)
+		}
+	}
+	return exitOK, nil
+}
+
+// fuzzReport — итоги гибридного фаззинга одной функции
+type fuzzReport struct {
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	internal.HybridReport
+}
+
+func runFuzz(cfg *config, patterns []string, stdout io.Writer) (int, error) {
+	if cfg.format == "sarif" {
+		return exitError, fmt.Errorf("format %q is not supported by fuzz", cfg.format)
+	}
+	files, err := loadFiles(patterns, cfg.run)
+	if err != nil {
+		return exitError, err
+	}
+
+	var reports []fuzzReport
+	for _, file := range files {
+		for _, function := range file.Functions {
+			options, err := cfg.solverOptions()
+			if err != nil {
+				return exitError, err
+			}
+			report, err := internal.NewAnalyser(options...).HybridFuzz(file.Path, function,
+				internal.HybridConfig{FuzzTime: cfg.fuzzTime, Rounds: cfg.rounds})
+			if errors.Is(err, internal.ErrNotFuzzable) {
+				continue
+			}
+			if err != nil {
+				return exitError, fmt.Errorf("%s: %s: %w", file.Path, function, err)
+			}
+			reports = append(reports, fuzzReport{Package: file.Package, Function: function, HybridReport: report})
+		}
+	}
+
+	code := exitOK
+	for _, report := range reports {
+		if report.Failure != "" {
+			code = exitFindings
+		}
+	}
+	if cfg.format == "json" {
+		return code, writeJSON(stdout, reports)
+	}
+	for _, report := range reports {
+		fmt.Fprintf(stdout, "%s.%s: %d rounds, %d fuzzer inputs, %d solver inputs\n",
+			report.Package, report.Function, report.Rounds, report.FuzzerInputs, report.SolverInputs)
+		for _, corpusErr := range report.CorpusErrors {
+			fmt.Fprintf(stdout, "  skipped corpus entry: %s\n", corpusErr)
+		}
+		if report.Failure != "" {
+			fmt.Fprintf(stdout, "%s\ncrashing input: %s\n", report.Failure, report.Crasher)
+		}
+	}
+	return code, nil
+}
+
+func runCFG(cfg *config, patterns []string, stdout io.Writer) (int, error) {
+	functions, err := loadFunctions(patterns, cfg.run)
+	if err != nil {
+		return exitError, err
+	}
+	builder := ssabuilder.NewBuilder()
+	var errs []error
+	for _, function := range functions {
+		var options []ssabuilder.DotOption
+		if cfg.overlay {
+			counts, err := blockCounts(cfg, function.Function)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", function.File, function.Function.Name(), err))
+				continue
+			}
+			options = append(options, ssabuilder.WithBlockCoverage(counts))
+		}
+		fmt.Fprint(stdout, builder.DOT(function.Function, options...))
+	}
+	if len(errs) > 0 {
+		return exitError, errors.Join(errs...)
+	}
+	return exitOK, nil
+}
+
+// blockCounts исследует функцию и считает проходы её блоков на всех путях
+func blockCounts(cfg *config, function *ssa.Function) (counts map[int]int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("analysis panicked: %v", r)
+		}
+	}()
+	options, err := cfg.solverOptions()
+	if err != nil {
+		return nil, err
+	}
+	analyser := internal.NewAnalyser(options...)
+	analyser.Package = function.Pkg
+	return internal.BlockCounts(function, analyser.AnalyseFunction(function)), nil
+}
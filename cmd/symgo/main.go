@@ -0,0 +1,158 @@
+// Команда symgo запускает символьный анализатор на пакетах Go.
+//
+// Использование:
+//
+//	symgo analyse  [флаги] [пакеты]   — пути исполнения функций с входными данными
+//	symgo gentests [флаги] [пакеты]   — генерация тестов рядом с исходными файлами
+//	symgo coverage [флаги] [пакеты]   — генерация тестов и покрытие ими кода
+//	symgo check    [флаги] [пакеты]   — поиск ошибок; код возврата 1, если они найдены
+//	symgo fuzz     [флаги] [пакеты]   — гибридный фаззинг: go test -fuzz вместе с солвером
+//	symgo cfg      [флаги] [пакеты]   — граф потока управления SSA в формате DOT
+//
+// Пакеты задаются шаблонами go list (по умолчанию "."), функции — флагом -run.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"symbolic-execution-course/internal/solver"
+)
+
+// Коды возврата
+const (
+	exitOK       = 0
+	exitFindings = 1
+	exitError    = 2
+)
+
+// config — общие флаги подкоманд
+type config struct {
+	run             *regexp.Regexp
+	selector        string
+	solver          string
+	queryTimeout    time.Duration
+	resourceLimit   uint
+	unknownPolicy   string
+	contextSwitches int
+	races           bool
+	minimize        bool
+	merge           bool
+	fuzz            bool
+	fuzzTime        time.Duration
+	rounds          int
+	cacheDir        string
+	dumpDir         string
+	treeDir         string
+	overlay         bool
+	format          string
+
+	// backend — внешний солвер, общий для всех функций запуска
+	backend *solver.ProcessBackend
+}
+
+// command — подкоманда symgo
+type command struct {
+	name    string
+	summary string
+	run     func(cfg *config, patterns []string, stdout io.Writer) (int, error)
+}
+
+// formats — поддерживаемые форматы вывода
+var formats = map[string]bool{"text": true, "json": true, "sarif": true}
+
+var commands = []command{
+	{"analyse", "print execution paths with inputs and results", runAnalyse},
+	{"gentests", "generate test files next to the analysed sources", runGenTests},
+	{"coverage", "generate tests and report the coverage they achieve", runCoverage},
+	{"check", "report findings and exit with status 1 if any", runCheck},
+	{"fuzz", "alternate go test -fuzz and the solver; exit with status 1 on a crash", runFuzz},
+	{"cfg", "print SSA control-flow graphs as Graphviz DOT", runCFG},
+}
+
+func main() {
+	os.Exit(execute(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// execute разбирает аргументы и запускает подкоманду, возвращая код возврата
+func execute(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+	var selected *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			selected = &commands[i]
+		}
+	}
+	if selected == nil {
+		fmt.Fprintf(stderr, "symgo: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+
+	flags := flag.NewFlagSet(selected.name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	cfg := &config{}
+	run := flags.String("run", "", "analyse only functions matching the regular expression")
+	flags.StringVar(&cfg.selector, "selector", "dfs", "path selector: dfs, bfs or random")
+	flags.StringVar(&cfg.solver, "solver", "z3", "SMT solver: z3 (in-process), z3-process, cvc5 or bitwuzla")
+	flags.DurationVar(&cfg.queryTimeout, "query-timeout", 0, "time limit for a single solver query")
+	flags.UintVar(&cfg.resourceLimit, "rlimit", 0, "solver resource limit for a single query")
+	flags.StringVar(&cfg.unknownPolicy, "unknown", "drop", "handling of UNKNOWN solver results: drop, keep or concretize")
+	flags.IntVar(&cfg.contextSwitches, "context-switches", 2, "maximum number of goroutine preemptions per path")
+	flags.BoolVar(&cfg.races, "race", false, "detect data races between goroutines")
+	flags.BoolVar(&cfg.minimize, "minimize", false, "minimise generated test inputs")
+	flags.BoolVar(&cfg.merge, "merge", false, "merge states that reach the same join point")
+	flags.BoolVar(&cfg.fuzz, "fuzz", false, "also generate Fuzz functions and seed corpora under testdata/fuzz")
+	flags.DurationVar(&cfg.fuzzTime, "fuzztime", 10*time.Second, "duration of a single go test -fuzz run")
+	flags.IntVar(&cfg.rounds, "rounds", 5, "maximum number of fuzzer/solver rounds per function")
+	flags.StringVar(&cfg.cacheDir, "cache", "", "directory for cached per-function results")
+	flags.StringVar(&cfg.dumpDir, "dump-queries", "", "directory to dump solver queries in SMT-LIB2")
+	flags.StringVar(&cfg.treeDir, "tree", "", "directory to write execution trees of analysed functions (DOT and JSON)")
+	flags.BoolVar(&cfg.overlay, "overlay", false, "cfg: overlay per-block path counts from an analysis run")
+	flags.StringVar(&cfg.format, "format", "text", "output format: text, json or sarif (findings only)")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: symgo %s [flags] [packages]\n\n%s\n\nflags:\n", selected.name, selected.summary)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitError
+	}
+
+	var err error
+	if cfg.run, err = regexp.Compile(*run); err != nil {
+		fmt.Fprintf(stderr, "symgo: invalid -run: %v\n", err)
+		return exitError
+	}
+	if !formats[cfg.format] {
+		fmt.Fprintf(stderr, "symgo: unsupported format %q\n", cfg.format)
+		return exitError
+	}
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	defer cfg.closeSolver()
+	code, err := selected.run(cfg, patterns, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "symgo %s: %v\n", selected.name, err)
+		return exitError
+	}
+	return code
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: symgo <command> [flags] [packages]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, command := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", command.name, command.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'symgo <command> -h' for the command flags.")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"no command", nil, exitError, "usage: symgo <command>"},
+		{"unknown command", []string{"run"}, exitError, `unknown command "run"`},
+		{"help", []string{"analyse", "-h"}, exitError, "usage: symgo analyse [flags] [packages]"},
+		{"invalid run", []string{"analyse", "-run", "("}, exitError, "invalid -run"},
+		{"invalid format", []string{"check", "-format", "xml"}, exitError, `unsupported format "xml"`},
+		{"unknown selector", []string{"analyse", "-selector", "greedy", "./testdata/sample"}, exitError, `unknown path selector "greedy"`},
+		{"unknown policy", []string{"check", "-unknown", "ignore", "./testdata/sample"}, exitError, `unknown -unknown policy "ignore"`},
+		{"unknown solver", []string{"gentests", "-solver", "yices", "./testdata/sample"}, exitError, `unknown solver "yices"`},
+		{"fuzz rejects sarif", []string{"fuzz", "-format", "sarif", "./testdata/sample"}, exitError, "not supported by fuzz"},
+		{"missing package", []string{"analyse", "./testdata/missing"}, exitError, "go list"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := execute(test.args, &stdout, &stderr); code != test.code {
+				t.Errorf("exit code = %d, want %d", code, test.code)
+			}
+			if !strings.Contains(stderr.String(), test.stderr) {
+				t.Errorf("stderr = %q, want %q", stderr.String(), test.stderr)
+			}
+		})
+	}
+}
+
+func TestAnalyserOptions(t *testing.T) {
+	base := &config{selector: "dfs", unknownPolicy: "drop"}
+	options, err := analyserOptions(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extended, err := analyserOptions(&config{selector: "bfs", unknownPolicy: "keep", minimize: true, merge: true, races: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(extended) != len(options)+3 {
+		t.Errorf("%d options for -minimize -merge -race, want %d", len(extended), len(options)+3)
+	}
+}
+
+func TestSolverOptions(t *testing.T) {
+	cfg := &config{selector: "dfs", unknownPolicy: "drop", solver: "z3"}
+	defer cfg.closeSolver()
+	for i := 0; i < 2; i++ {
+		if _, err := cfg.solverOptions(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfg.backend != nil {
+		t.Error("the in-process solver started an external process")
+	}
+
+	cfg.solver = "yices"
+	if _, err := cfg.solverOptions(); err == nil {
+		t.Error("expected an error for an unknown solver")
+	}
+}
+
+func TestLoadFunctions(t *testing.T) {
+	functions, err := loadFunctions([]string{"./testdata/split"}, regexp.MustCompile("."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, function := range functions {
+		files[function.Function.Name()] = filepath.Base(function.File)
+	}
+	if len(functions) != 2 || files["Clamp"] != "split.go" || files["limit"] != "limit.go" {
+		t.Fatalf("functions = %v, want Clamp in split.go and limit in limit.go", files)
+	}
+
+	// Вызов функции из другого файла пакета указывает на её построенное тело
+	for _, function := range functions {
+		if function.Function.Name() != "Clamp" {
+			continue
+		}
+		for _, block := range function.Function.Blocks {
+			for _, instruction := range block.Instrs {
+				if call, ok := instruction.(*ssa.Call); ok && call.Call.StaticCallee().Blocks == nil {
+					t.Errorf("callee %s has no body", call.Call.StaticCallee())
+				}
+			}
+		}
+	}
+}
+
+func TestAnalyseFunctionsContinues(t *testing.T) {
+	cfg := &config{run: regexp.MustCompile("."), selector: "dfs", unknownPolicy: "drop", solver: "z3", treeDir: t.TempDir()}
+	defer cfg.closeSolver()
+	reports, err := analyseFunctions(cfg, []string{"./testdata/split"})
+
+	// Ошибка или паника в одной функции не прерывает анализ остальных
+	var failed []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		failed = joined.Unwrap()
+	}
+	if len(reports)+len(failed) != 2 {
+		t.Errorf("%d reports and errors %v, want one of them for each of 2 functions", len(reports), err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+
+	"symbolic-execution-course/internal"
+	"symbolic-execution-course/internal/solver"
+)
+
+// analyserOptions переводит флаги в опции Analyser, кроме выбора солвера
+func analyserOptions(cfg *config) ([]internal.Option, error) {
+	var options []internal.Option
+
+	switch cfg.selector {
+	case "dfs":
+		options = append(options, internal.WithPathSelector(&internal.DfsPathSelector{}))
+	case "bfs":
+		options = append(options, internal.WithPathSelector(&internal.BfsPathSelector{}))
+	case "random":
+		options = append(options, internal.WithPathSelector(&internal.RandomPathSelector{}))
+	default:
+		return nil, fmt.Errorf("unknown path selector %q", cfg.selector)
+	}
+
+	switch cfg.unknownPolicy {
+	case "drop":
+		options = append(options, internal.WithUnknownPolicy(internal.DropUnknown))
+	case "keep":
+		options = append(options, internal.WithUnknownPolicy(internal.KeepUnknown))
+	case "concretize":
+		options = append(options, internal.WithUnknownPolicy(internal.ConcretizeUnknown))
+	default:
+		return nil, fmt.Errorf("unknown -unknown policy %q", cfg.unknownPolicy)
+	}
+
+	if cfg.queryTimeout > 0 {
+		options = append(options, internal.WithQueryTimeout(cfg.queryTimeout))
+	}
+	if cfg.resourceLimit > 0 {
+		options = append(options, internal.WithResourceLimit(cfg.resourceLimit))
+	}
+	options = append(options, internal.WithContextSwitchBound(cfg.contextSwitches))
+	if cfg.races {
+		options = append(options, internal.WithRaceDetection())
+	}
+	if cfg.minimize {
+		options = append(options, internal.WithModelMinimization())
+	}
+	if cfg.merge {
+		options = append(options, internal.WithStateMerging())
+	}
+	if cfg.fuzz {
+		options = append(options, internal.WithFuzzHarness())
+	}
+	if cfg.cacheDir != "" {
+		options = append(options, internal.WithCache(cfg.cacheDir))
+	}
+	if cfg.treeDir != "" {
+		options = append(options, internal.WithExecutionTree())
+	}
+	if cfg.dumpDir != "" {
+		options = append(options, internal.WithQueryDump(cfg.dumpDir))
+	}
+	return options, nil
+}
+
+// processSolver возвращает конфигурацию внешнего солвера для -solver
+// (ok = false для встроенного Z3)
+func processSolver(name string) (solver.ProcessConfig, bool, error) {
+	switch name {
+	case "z3":
+		return solver.ProcessConfig{}, false, nil
+	case "z3-process":
+		return solver.Z3Process, true, nil
+	case "cvc5":
+		return solver.CVC5, true, nil
+	case "bitwuzla":
+		return solver.Bitwuzla, true, nil
+	default:
+		return solver.ProcessConfig{}, false, fmt.Errorf("unknown solver %q", name)
+	}
+}
+
+// solverOptions возвращает опции Analyser вместе с солвером, выбранным
+// флагом -solver. Опции создаются заново для каждой функции, так как
+// селектор путей хранит состояние, а внешний солвер запускается один раз
+// на весь запуск и останавливается closeSolver.
+func (cfg *config) solverOptions() ([]internal.Option, error) {
+	options, err := analyserOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	processConfig, external, err := processSolver(cfg.solver)
+	if err != nil || !external {
+		return options, err
+	}
+	if cfg.backend == nil {
+		if cfg.backend, err = solver.NewProcessBackend(processConfig); err != nil {
+			return nil, err
+		}
+	}
+	return append(options, internal.WithSolver(cfg.backend)), nil
+}
+
+// closeSolver останавливает внешний солвер, если он был запущен
+func (cfg *config) closeSolver() {
+	if cfg.backend != nil {
+		_ = cfg.backend.Close()
+		cfg.backend = nil
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// sourceFile — файл пакета и функции в нём, выбранные для анализа
+type sourceFile struct {
+	Package   string
+	Path      string
+	Source    string
+	Functions []string
+}
+
+// packageFunction — функция, выбранная для анализа, в SSA всего пакета
+type packageFunction struct {
+	Package  string
+	File     string
+	Function *ssa.Function
+}
+
+// listedPackage — часть вывода go list -json
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+}
+
+// listPackages находит пакеты по шаблонам go list
+func listPackages(patterns []string) ([]listedPackage, error) {
+	cmd := exec.Command("go", append([]string{"list", "-json"}, patterns...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w\n%s", err, stderr.String())
+	}
+
+	var pkgs []listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go list: %w", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+// loadFiles находит исходные файлы пакетов по шаблонам go list и функции
+// верхнего уровня в них, имена которых подходят под run
+func loadFiles(patterns []string, run *regexp.Regexp) ([]sourceFile, error) {
+	pkgs, err := listPackages(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []sourceFile
+	for _, pkg := range pkgs {
+		for _, name := range pkg.GoFiles {
+			file, err := loadFile(pkg.ImportPath, filepath.Join(pkg.Dir, name), run)
+			if err != nil {
+				return nil, err
+			}
+			if len(file.Functions) > 0 {
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
+
+// loadFile читает файл и выбирает функции для анализа. Методы и обобщённые
+// функции пропускаются: анализ начинается с функций по имени.
+func loadFile(pkg, path string, run *regexp.Regexp) (sourceFile, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return sourceFile{}, err
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), path, source, parser.SkipObjectResolution)
+	if err != nil {
+		return sourceFile{}, err
+	}
+
+	result := sourceFile{Package: pkg, Path: path, Source: string(source)}
+	for _, decl := range file.Decls {
+		if name, ok := selected(decl, run); ok {
+			result.Functions = append(result.Functions, name)
+		}
+	}
+	return result, nil
+}
+
+// selected сообщает, выбрана ли функция decl для анализа, и возвращает её имя
+func selected(decl ast.Decl, run *regexp.Regexp) (string, bool) {
+	function, ok := decl.(*ast.FuncDecl)
+	if !ok || function.Recv != nil || function.Type.TypeParams != nil || function.Body == nil {
+		return "", false
+	}
+	name := function.Name.Name
+	if name == "main" || name == "init" || strings.HasPrefix(name, "_") || !run.MatchString(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// loadFunctions находит пакеты по шаблонам go list, один раз строит SSA
+// каждого пакета из всех его файлов и выбирает функции так же, как
+// loadFile. Вызовы функций из других файлов пакета исследуются вместе с
+// вызывающей.
+func loadFunctions(patterns []string, run *regexp.Regexp) ([]packageFunction, error) {
+	pkgs, err := listPackages(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	// GlobalDebug сохраняет синтаксис функций, из которого читаются контракты
+	program := ssa.NewProgram(fset, ssa.GlobalDebug)
+	imports := importer.Default()
+	created := make(map[*types.Package]bool)
+	var functions []packageFunction
+	for _, listed := range pkgs {
+		files := make([]*ast.File, len(listed.GoFiles))
+		for i, name := range listed.GoFiles {
+			files[i], err = parser.ParseFile(fset, filepath.Join(listed.Dir, name), nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+		}
+		pkg, err := buildPackage(program, imports, listed.ImportPath, files, created)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			for _, decl := range file.Decls {
+				if name, ok := selected(decl, run); ok {
+					functions = append(functions, packageFunction{
+						Package:  listed.ImportPath,
+						File:     fset.File(file.Pos()).Name(),
+						Function: pkg.Func(name),
+					})
+				}
+			}
+		}
+	}
+	return functions, nil
+}
+
+// buildPackage проверяет типы файлов пакета и строит его SSA. Импортируемые
+// пакеты создаются без тел функций; общий для пакетов imports не даёт
+// создать один пакет дважды, created — уже созданные в program пакеты.
+func buildPackage(program *ssa.Program, imports types.Importer, path string, files []*ast.File, created map[*types.Package]bool) (*ssa.Package, error) {
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	pkg, err := (&types.Config{Importer: imports}).Check(path, program.Fset, files, info)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var createImports func(*types.Package)
+	createImports = func(pkg *types.Package) {
+		for _, imported := range pkg.Imports() {
+			if !created[imported] {
+				created[imported] = true
+				createImports(imported)
+				program.CreatePackage(imported, nil, nil, true)
+			}
+		}
+	}
+	createImports(pkg)
+	built := program.CreatePackage(pkg, files, info, true)
+	built.Build()
+	return built, nil
+}
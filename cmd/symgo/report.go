@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"symbolic-execution-course/internal"
+)
+
+// functionReport — результаты анализа одной функции
+type functionReport struct {
+	Package  string                `json:"package"`
+	File     string                `json:"file"`
+	Function string                `json:"function"`
+	Cached   bool                  `json:"cached,omitempty"`
+	Paths    []internal.CachedPath `json:"paths"`
+}
+
+// writeReports выводит пути исполнения функций
+func writeReports(w io.Writer, format string, reports []functionReport) error {
+	switch format {
+	case "json":
+		return writeJSON(w, reports)
+	case "sarif":
+		return writeFindings(w, format, reports)
+	case "text":
+		for _, report := range reports {
+			cached := ""
+			if report.Cached {
+				cached = " (cached)"
+			}
+			fmt.Fprintf(w, "%s.%s: %d paths%s\n", report.Package, report.Function, len(report.Paths), cached)
+			for i, path := range report.Paths {
+				fmt.Fprintf(w, "  path %d: %s\n", i+1, path.PathCondition)
+				fmt.Fprintf(w, "    inputs: %s\n", internal.FormatInputs(path.Inputs))
+				if path.Result != "" {
+					fmt.Fprintf(w, "    result: %s\n", path.Result)
+				}
+				if path.Incomplete {
+					fmt.Fprintln(w, "    incomplete: true")
+				}
+				for _, finding := range path.Findings {
+					fmt.Fprintf(w, "    %s: %s\n", finding.Kind, finding.Message)
+				}
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// collectFindings собирает ошибки всех путей. Позиции без имени файла
+// (например, из кэша, записанного при разборе отдельного исходного текста)
+// привязываются к файлу функции; позиции в других файлах пакета сохраняются.
+func collectFindings(reports []functionReport) []internal.ReportedFinding {
+	var findings []internal.ReportedFinding
+	for _, report := range reports {
+		for _, path := range report.Paths {
+			for _, found := range path.Findings {
+				found.Path = append([]internal.Location{}, found.Path...)
+				for i := range found.Path {
+					if found.Path[i].File == "" {
+						found.Path[i].File = report.File
+					}
+				}
+				if found.Location != nil {
+					location := *found.Location
+					if location.File == "" {
+						location.File = report.File
+					}
+					found.Location = &location
+				}
+				findings = append(findings, internal.ReportedFinding{
+					Package:  report.Package,
+					File:     report.File,
+					Function: report.Function,
+					Inputs:   path.Inputs,
+					Finding:  found,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// writeFindings выводит найденные ошибки
+func writeFindings(w io.Writer, format string, reports []functionReport) error {
+	findings := collectFindings(reports)
+	switch format {
+	case "json":
+		return writeJSON(w, findings)
+	case "sarif":
+		baseDir, _ := os.Getwd()
+		return internal.WriteSARIF(w, baseDir, findings)
+	case "text":
+		for _, found := range findings {
+			fmt.Fprintf(w, "%s: %s: %s: %s (inputs: %s)\n",
+				found.File, found.Function, found.Kind, found.Message, internal.FormatInputs(found.Inputs))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,5 @@
+package split
+
+func limit() int {
+	return 10
+}
@@ -0,0 +1,8 @@
+package split
+
+func Clamp(x int) int {
+	if x > limit() {
+		return limit()
+	}
+	return x
+}
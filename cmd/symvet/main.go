@@ -0,0 +1,14 @@
+// Команда symvet запускает символьный анализатор в составе go vet:
+//
+//	go build -o symvet ./cmd/symvet
+//	go vet -vettool=$(pwd)/symvet ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+	"symbolic-execution-course/pkg/symanalysis"
+)
+
+func main() {
+	unitchecker.Main(symanalysis.Analyzer)
+}
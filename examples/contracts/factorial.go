@@ -0,0 +1,24 @@
+// Пример: контракты функции в аннотациях //sym:requires и //sym:ensures.
+// Анализатор принимает предусловие анализируемой функции как допущение,
+// а при вызовах и возвратах проверяет контракты вызываемых функций.
+package contracts
+
+//sym:requires n >= 0
+//sym:ensures result >= 1
+func Factorial(n int) int {
+	if n < 0 {
+		return 0
+	}
+	if n == 0 {
+		return 1
+	}
+	result := Factorial(n - 1)
+	return n * result
+}
+
+// Sum нарушает предусловие Factorial при отрицательном k
+//
+//sym:ensures result >= 2
+func Sum(k int) int {
+	return Factorial(k) + Factorial(1)
+}
@@ -33,4 +33,7 @@ func testFunction(x int) int {
 		log.Fatalf("Ошибка построения SSA: %v", err)
 	}
 	fmt.Printf("CFG построен для функции с %d блоками\n", len(graph.Blocks))
+
+	// Граф потока управления в формате Graphviz (отрисовка: dot -Tsvg)
+	fmt.Print(builder.DOT(graph))
 }
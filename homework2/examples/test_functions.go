@@ -107,6 +107,11 @@ func abs(x int) int {
 	return x
 }
 
+// Версия abs без ветвлений для проверки эквивалентности
+func absBranchFree(x int) int {
+	return (x ^ (x >> 63)) - (x >> 63)
+}
+
 // Функция с несколькими return'ами
 func signFunction(x int) int {
 	if x > 0 {
@@ -22,7 +22,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	testFilePath := internal.GenerateTestFile(sourceFilePath)
+	testFilePath := internal.GenerateTestFile(sourceFilePath, internal.WithModelMinimization())
 
 	fmt.Printf("Generated test file: %s\n", testFilePath)
 
@@ -33,6 +33,14 @@ func main() {
 	}
 
 	fmt.Printf("\n=== Code Coverage ===\n%s\n", coverage)
+
+	reports, err := internal.AnalyseCoverage(sourceFilePath, internal.WithModelMinimization())
+	if err != nil {
+		fmt.Printf("Error analysing branch coverage: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\n=== Branch Coverage ===\n")
+	internal.WriteCoverage(os.Stdout, reports)
 }
 
 func runTestsWithCoverage(testFilePath string) (string, error) {
@@ -58,6 +66,12 @@ func runTestsWithCoverage(testFilePath string) (string, error) {
 		return "", fmt.Errorf("failed to analyze coverage: %w", err)
 	}
 
+	htmlFile := filepath.Join(testDir, "coverage.html")
+	if err := renderCoverageHTML(coverageFile, htmlFile); err != nil {
+		return "", err
+	}
+	fmt.Printf("HTML coverage report: %s\n", htmlFile)
+
 	return coverage, nil
 }
 
@@ -74,3 +88,14 @@ func analyzeCoverage(coverageFile string) (string, error) {
 
 	return fmt.Sprintf("%s", coverageOutput), nil
 }
+
+func renderCoverageHTML(coverageFile, htmlFile string) error {
+	cmd := exec.Command("go", "tool", "cover",
+		"-html="+coverageFile, "-o", htmlFile)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to render HTML coverage: %w\nOutput: %s", err, output)
+	}
+	return nil
+}
@@ -1,7 +1,12 @@
 package internal
 
 import (
+	"time"
+
 	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	ssabuilder "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/symbolic"
 	"symbolic-execution-course/internal/translator"
 )
 
@@ -11,9 +16,225 @@ type Analyser struct {
 	PathSelector PathSelector
 	Results      []Interpreter
 	Z3Translator *translator.Z3Translator
+	Solver       solver.SolverBackend
+
+	// QueryDumpDir — каталог для сохранения запросов к солверу в формате SMT-LIB2.
+	// Пустая строка отключает сохранение.
+	QueryDumpDir string
+	queryCounter int
+
+	// QueryTimeout и ResourceLimit ограничивают один запрос к солверу (0 — без ограничения)
+	QueryTimeout  time.Duration
+	ResourceLimit uint
+	// UnknownPolicy определяет, что делать с состоянием, выполнимость которого неизвестна
+	UnknownPolicy UnknownPolicy
+	solverReady   bool
+
+	// MinimizeModels включает подбор небольших значений входных данных для тестов
+	MinimizeModels bool
+
+	// ConcreteInputs задаёт входы текущего конколического запуска (nil — символьный режим)
+	ConcreteInputs solver.Model
+	// ConcolicRuns ограничивает число конкретных запусков в AnalyseConcolic
+	ConcolicRuns int
+	// repairs — входы, построенные для запусков текущего поколения, которые
+	// отброшены из-за ложного на конкретных входах допущения (см. concolicAssume)
+	repairs []solver.Model
+
+	// UnsupportedPolicy определяет обработку инструкций, которые нельзя смоделировать
+	UnsupportedPolicy UnsupportedPolicy
+	opaqueCounter     int
+	// opaquePrefix отличает свежие переменные анализаторов, пути которых
+	// сравниваются в одном запросе (AnalyseEquivalence)
+	opaquePrefix string
+
+	// Models — символьные модели функций, используемые вместо их тел
+	Models ModelRegistry
+
+	// ContextSwitchBound ограничивает число вытеснений горутин на одном пути
+	ContextSwitchBound int
+	// DetectRaces включает поиск гонок данных
+	DetectRaces bool
+
+	// CacheDir — каталог кэша результатов анализа функций (пустая строка — без кэша)
+	CacheDir string
+
+	// Builder строит SSA анализируемой программы и читает контракты функций;
+	// Analyse может построить SSA через него и передать в AnalyseFunction
+	Builder   *ssabuilder.Builder
+	contracts map[*ssa.Function]*ssabuilder.Contract
+
+	// StepBudget ограничивает число исполненных инструкций за анализ (0 — без ограничения)
+	StepBudget int
+	// BudgetExhausted означает, что анализ остановлен по StepBudget и
+	// часть путей не исследована
+	BudgetExhausted bool
+	steps           int
+	coverageLog     *coverageLog
+
+	// FuzzHarness включает генерацию fuzz-тестов и корпуса в GenerateTestFile
+	FuzzHarness bool
+	// Tree — дерево исполнения (nil — не записывается)
+	Tree *ExecutionTree
+
+	// CorpusDir — каталог пакета, корпус которого дополняет входы
+	// конколического анализа (пустая строка — без корпуса)
+	CorpusDir string
+
+	// MergeStates включает слияние состояний в точках соединения путей
+	MergeStates bool
+	postponed   int
+}
+
+// Option настраивает Analyser перед запуском анализа
+type Option func(analyser *Analyser)
+
+// WithPathSelector задаёт стратегию выбора следующего состояния
+func WithPathSelector(selector PathSelector) Option {
+	return func(analyser *Analyser) {
+		analyser.PathSelector = selector
+	}
+}
+
+// WithSolver задаёт SMT солвер вместо Z3 по умолчанию
+func WithSolver(backend solver.SolverBackend) Option {
+	return func(analyser *Analyser) {
+		analyser.Solver = backend
+	}
+}
+
+// WithQueryDump включает сохранение каждого запроса к солверу в каталог dir
+func WithQueryDump(dir string) Option {
+	return func(analyser *Analyser) {
+		analyser.QueryDumpDir = dir
+	}
 }
 
-func Analyse(source string, functionName string) []Interpreter {
+// WithQueryTimeout ограничивает время одного запроса к солверу
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(analyser *Analyser) {
+		analyser.QueryTimeout = timeout
+	}
+}
+
+// WithResourceLimit ограничивает ресурсы солвера на один запрос
+func WithResourceLimit(limit uint) Option {
+	return func(analyser *Analyser) {
+		analyser.ResourceLimit = limit
+	}
+}
+
+// WithUnknownPolicy задаёт обработку состояний с результатом UNKNOWN
+func WithUnknownPolicy(policy UnknownPolicy) Option {
+	return func(analyser *Analyser) {
+		analyser.UnknownPolicy = policy
+	}
+}
+
+// WithModelMinimization включает минимизацию моделей при генерации тестов
+func WithModelMinimization() Option {
+	return func(analyser *Analyser) {
+		analyser.MinimizeModels = true
+	}
+}
+
+// WithStepBudget ограничивает число исполненных инструкций за анализ
+func WithStepBudget(budget int) Option {
+	return func(analyser *Analyser) {
+		analyser.StepBudget = budget
+	}
+}
+
+// NewAnalyser создаёт Analyser с настройками по умолчанию и применяет опции
+func NewAnalyser(options ...Option) *Analyser {
+	analyser := &Analyser{
+		PathSelector:       &DfsPathSelector{},
+		Z3Translator:       translator.NewZ3Translator(),
+		Models:             DefaultModels(),
+		ContextSwitchBound: defaultContextSwitchBound,
+	}
+	for _, option := range options {
+		option(analyser)
+	}
+	if analyser.Solver == nil {
+		analyser.Solver = solver.NewZ3Backend(analyser.Z3Translator)
+	}
+	return analyser
+}
+
+func Analyse(source string, functionName string, options ...Option) []Interpreter {
+	return NewAnalyser(options...).Analyse(source, functionName)
+}
+
+func (analyser *Analyser) Analyse(source string, functionName string) []Interpreter {
 	// TODO implement me
+	// Выполнимость новых состояний проверяйте через analyser.resolveState.
+	// Хуки возможностей анализатора описаны в комментариях их файлов.
+	// В конечном состоянии оставляйте нижний кадр стека: его ReturnValue
+	// (ReturnValues при нескольких результатах) — результат функции
+	// (используется в AnalyseEquivalence).
 	panic("implement me")
 }
+
+// AnalyseFunction исследует пути уже построенной SSA функции (например,
+// полученной от go/analysis) так же, как Analyse
+func (analyser *Analyser) AnalyseFunction(function *ssa.Function) []Interpreter {
+	// TODO implement me
+	panic("implement me")
+}
+
+// spend учитывает исполнение инструкции и сообщает, остался ли бюджет.
+// Его вызывают перед каждой инструкцией; когда бюджет исчерпан, оставшиеся
+// в очереди состояния переходят в Results с Incomplete = true.
+func (analyser *Analyser) spend() bool {
+	if analyser.StepBudget > 0 && analyser.steps >= analyser.StepBudget {
+		analyser.BudgetExhausted = true
+		return false
+	}
+	analyser.steps++
+	return true
+}
+
+// checkSat проверяет выполнимость условия пути и возвращает модель для SAT
+func (analyser *Analyser) checkSat(condition symbolic.SymbolicExpression) (solver.Result, solver.Model, error) {
+	if err := analyser.configureSolver(); err != nil {
+		return solver.UNKNOWN, nil, err
+	}
+	if err := analyser.dumpQuery(condition); err != nil {
+		return solver.UNKNOWN, nil, err
+	}
+
+	if err := analyser.Solver.Push(); err != nil {
+		return solver.UNKNOWN, nil, err
+	}
+	defer analyser.Solver.Pop()
+
+	if err := analyser.Solver.Assert(condition); err != nil {
+		return solver.UNKNOWN, nil, err
+	}
+	result, err := analyser.Solver.Check()
+	if err != nil || result != solver.SAT {
+		return result, nil, err
+	}
+	model, err := analyser.Solver.Model()
+	return result, model, err
+}
+
+// configureSolver один раз передаёт солверу ограничения на запрос
+func (analyser *Analyser) configureSolver() error {
+	if analyser.solverReady {
+		return nil
+	}
+	if analyser.QueryTimeout > 0 {
+		if err := analyser.Solver.SetTimeout(analyser.QueryTimeout); err != nil {
+			return err
+		}
+	}
+	if analyser.ResourceLimit > 0 {
+		if err := analyser.Solver.SetResourceLimit(analyser.ResourceLimit); err != nil {
+			return err
+		}
+	}
+	analyser.solverReady = true
+	return nil
+}
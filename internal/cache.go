@@ -0,0 +1,266 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	ssabuilder "symbolic-execution-course/internal/ssa"
+)
+
+// WithCache включает сохранение результатов анализа функций в каталог dir
+// и их повторное использование, пока не изменились SSA функции и её
+// вызываемых, их контракты и настройки анализатора
+func WithCache(dir string) Option {
+	return func(analyser *Analyser) {
+		analyser.CacheDir = dir
+	}
+}
+
+// CachedPath — сохранённый результат одного пути
+type CachedPath struct {
+	PathCondition string `json:"pathCondition"`
+	// Inputs — входные данные теста в виде литералов Go
+	Inputs     map[string]string `json:"inputs"`
+	Result     string            `json:"result,omitempty"`
+	Incomplete bool              `json:"incomplete,omitempty"`
+	Findings   []Finding         `json:"findings,omitempty"`
+}
+
+// CacheEntry — результаты анализа функции и хэш, при котором они получены
+type CacheEntry struct {
+	Function string       `json:"function"`
+	Hash     string       `json:"hash"`
+	Paths    []CachedPath `json:"paths"`
+}
+
+// AnalyseCached возвращает результаты анализа функции из кэша, если её
+// хэш не изменился, иначе исследует функцию заново и обновляет кэш.
+// Второй результат сообщает, были ли результаты взяты из кэша.
+func (analyser *Analyser) AnalyseCached(source string, functionName string) ([]CachedPath, bool, error) {
+	if analyser.Builder == nil {
+		analyser.Builder = ssabuilder.NewBuilder()
+	}
+	function, err := analyser.Builder.ParseAndBuildSSA(source, functionName)
+	if err != nil {
+		return nil, false, err
+	}
+	return analyser.AnalyseFunctionCached(function)
+}
+
+// AnalyseFunctionCached работает как AnalyseCached для уже построенной SSA
+// функции (например, из SSA всего пакета)
+func (analyser *Analyser) AnalyseFunctionCached(function *ssa.Function) ([]CachedPath, bool, error) {
+	hash, err := analyser.cacheKey(function)
+	if err != nil {
+		return nil, false, err
+	}
+	if entry, err := analyser.loadCache(function); err != nil {
+		return nil, false, err
+	} else if entry != nil && entry.Hash == hash {
+		return entry.Paths, true, nil
+	}
+
+	entry := &CacheEntry{Function: function.String(), Hash: hash}
+	for _, final := range analyser.AnalyseFunction(function) {
+		path, err := analyser.Summary(final)
+		if err != nil {
+			return nil, false, err
+		}
+		entry.Paths = append(entry.Paths, path)
+	}
+	return entry.Paths, false, analyser.storeCache(entry)
+}
+
+// cacheKey возвращает ключ кэша функции: хэш SSA (FunctionHash), контракты
+// функции и всех вызываемых ею, а также настройки анализатора, от которых
+// зависят результаты
+func (analyser *Analyser) cacheKey(function *ssa.Function) (string, error) {
+	hash := sha256.New()
+	hasher := newFunctionHasher(hash)
+	hasher.write(function)
+
+	// Контракты не отражаются в SSA, но меняют результаты анализа
+	for _, hashed := range hasher.order {
+		contract, err := analyser.contract(hashed)
+		if err != nil {
+			return "", err
+		}
+		if contract == nil {
+			continue
+		}
+		fmt.Fprintf(hash, "contract %s\n", hashed)
+		for _, clause := range contract.Requires {
+			fmt.Fprintln(hash, "requires", clause.Text)
+		}
+		for _, clause := range contract.Ensures {
+			fmt.Fprintln(hash, "ensures", clause.Text)
+		}
+	}
+	analyser.writeConfiguration(hash)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeConfiguration записывает настройки, влияющие на результаты анализа.
+// Пользовательские модели функций учитываются только по именам.
+func (analyser *Analyser) writeConfiguration(w io.Writer) {
+	solverName := ""
+	if analyser.Solver != nil {
+		solverName = analyser.Solver.Name()
+	}
+	fmt.Fprintf(w, "selector %T\nsolver %s\n", analyser.PathSelector, solverName)
+	fmt.Fprintf(w, "budget %d\ntimeout %s\nresources %d\n", analyser.StepBudget, analyser.QueryTimeout, analyser.ResourceLimit)
+	fmt.Fprintf(w, "unknown %s\nunsupported %s\n", analyser.UnknownPolicy, analyser.UnsupportedPolicy)
+	fmt.Fprintf(w, "minimize %t\nmerge %t\nraces %t\nswitches %d\n",
+		analyser.MinimizeModels, analyser.MergeStates, analyser.DetectRaces, analyser.ContextSwitchBound)
+	models := slices.Sorted(maps.Keys(analyser.Models))
+	fmt.Fprintf(w, "models %s\n", strings.Join(models, " "))
+}
+
+// Summary сводит конечное состояние к результату пути с входными данными для теста
+func (analyser *Analyser) Summary(final Interpreter) (CachedPath, error) {
+	inputs, err := analyser.testInputs(final)
+	if err != nil {
+		return CachedPath{}, err
+	}
+	path := CachedPath{
+		Inputs:     make(map[string]string, len(inputs)),
+		Incomplete: final.Incomplete,
+		Findings:   final.Findings,
+	}
+	if final.PathCondition != nil {
+		path.PathCondition = final.PathCondition.String()
+	}
+	for name, value := range inputs {
+		path.Inputs[name] = value.String()
+	}
+	if results := returnValues(final); results != nil {
+		path.Result = Outcome{Results: results}.String()
+	}
+	return path, nil
+}
+
+// loadCache читает результаты функции из кэша (nil, если их нет)
+func (analyser *Analyser) loadCache(function *ssa.Function) (*CacheEntry, error) {
+	if analyser.CacheDir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(analyser.cacheFile(function.String()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("corrupted cache entry for %s: %w", function, err)
+	}
+	return &entry, nil
+}
+
+// storeCache сохраняет результаты функции в кэш
+func (analyser *Analyser) storeCache(entry *CacheEntry) error {
+	if analyser.CacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(analyser.CacheDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(analyser.cacheFile(entry.Function), data, 0o644)
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// cacheFile возвращает путь файла кэша функции
+func (analyser *Analyser) cacheFile(function string) string {
+	return filepath.Join(analyser.CacheDir, unsafeFileChars.ReplaceAllString(function, "_")+".json")
+}
+
+// FunctionHash возвращает хэш SSA тела функции, её замыканий, тел всех
+// статически вызываемых функций и инициализации пакетов, глобальные
+// переменные которых они используют. Позиции в исходном коде не
+// учитываются, поэтому правка других функций файла не меняет хэш.
+func FunctionHash(function *ssa.Function) string {
+	hash := sha256.New()
+	newFunctionHasher(hash).write(function)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// functionHasher записывает SSA функций для хэширования
+type functionHasher struct {
+	w       io.Writer
+	visited map[*ssa.Function]bool
+	// order — функции с телами в порядке записи
+	order    []*ssa.Function
+	packages map[*ssa.Package]bool
+}
+
+func newFunctionHasher(w io.Writer) *functionHasher {
+	return &functionHasher{w: w, visited: make(map[*ssa.Function]bool), packages: make(map[*ssa.Package]bool)}
+}
+
+func (hasher *functionHasher) write(function *ssa.Function) {
+	if hasher.visited[function] || function.Blocks == nil {
+		// Рекурсивные вызовы и внешние функции учитываются по имени
+		fmt.Fprintf(hasher.w, "ref %s\n", function)
+		return
+	}
+	hasher.visited[function] = true
+	hasher.order = append(hasher.order, function)
+
+	var body bytes.Buffer
+	ssa.WriteFunction(&body, function)
+	scanner := bufio.NewScanner(&body)
+	for scanner.Scan() {
+		// Позиции в заголовке и в DebugRef меняются при сдвиге кода
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "# Location:") && !strings.HasPrefix(strings.TrimSpace(line), "; ") {
+			fmt.Fprintln(hasher.w, line)
+		}
+	}
+
+	for _, anon := range function.AnonFuncs {
+		hasher.write(anon)
+	}
+	var initialized []*ssa.Package
+	for _, block := range function.Blocks {
+		for _, instruction := range block.Instrs {
+			if call, ok := instruction.(ssa.CallInstruction); ok {
+				if callee := call.Common().StaticCallee(); callee != nil {
+					hasher.write(callee)
+				}
+			}
+			for _, operand := range instruction.Operands(nil) {
+				global, ok := (*operand).(*ssa.Global)
+				if ok && global.Pkg != nil && !hasher.packages[global.Pkg] {
+					hasher.packages[global.Pkg] = true
+					initialized = append(initialized, global.Pkg)
+				}
+			}
+		}
+	}
+	// Начальные значения глобальных переменных задаёт init их пакета
+	for _, pkg := range initialized {
+		if init := pkg.Func("init"); init != nil {
+			hasher.write(init)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cacheSource = `package main
+
+var limit = 10
+
+func target(x int) int {
+	if x > limit {
+		return helper(x)
+	}
+	return 0
+}
+
+//sym:requires x > 0
+func helper(x int) int { return x + 1 }
+
+func unrelated() int { return 1 }
+`
+
+func TestFunctionHash(t *testing.T) {
+	base := FunctionHash(buildFunction(t, cacheSource, "target"))
+	tests := []struct {
+		name    string
+		source  string
+		changes bool
+	}{
+		{"same source", cacheSource, false},
+		{"other function edited", strings.Replace(cacheSource, "return 1 }", "return 2 }", 1), false},
+		{"code moved down", strings.Replace(cacheSource, "var limit", "\n\n\nvar limit", 1), false},
+		{"callee edited", strings.Replace(cacheSource, "x + 1", "x + 2", 1), true},
+		{"global initializer edited", strings.Replace(cacheSource, "limit = 10", "limit = 11", 1), true},
+		{"body edited", strings.Replace(cacheSource, "return 0", "return -1", 1), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hash := FunctionHash(buildFunction(t, test.source, "target"))
+			if (hash != base) != test.changes {
+				t.Errorf("hash changed = %t, want %t", hash != base, test.changes)
+			}
+		})
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	requireExpressions(t)
+	key := func(source string, options ...Option) string {
+		t.Helper()
+		analyser := newTestAnalyser(newFakeSolver(2), options...)
+		hash, err := analyser.cacheKey(buildFunction(t, source, "target"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	base := key(cacheSource)
+	tests := []struct {
+		name    string
+		source  string
+		options []Option
+	}{
+		{"callee contract", strings.Replace(cacheSource, "x > 0", "x > 1", 1), nil},
+		{"step budget", cacheSource, []Option{WithStepBudget(100)}},
+		{"path selector", cacheSource, []Option{WithPathSelector(&BfsPathSelector{})}},
+		{"unknown policy", cacheSource, []Option{WithUnknownPolicy(KeepUnknown)}},
+		{"unsupported policy", cacheSource, []Option{WithUnsupportedPolicy(ConcretizeUnsupported)}},
+		{"minimisation", cacheSource, []Option{WithModelMinimization()}},
+		{"context switch bound", cacheSource, []Option{WithContextSwitchBound(5)}},
+		{"user model", cacheSource, []Option{WithModel("main.helper", modelUnknownBool)}},
+	}
+	for _, test := range tests {
+		if key(test.source, test.options...) == base {
+			t.Errorf("%s does not change the cache key", test.name)
+		}
+	}
+	if key(cacheSource) != base {
+		t.Error("cache key is not deterministic")
+	}
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	function := buildFunction(t, cacheSource, "target")
+	analyser := newTestAnalyser(newFakeSolver(2), WithCache(filepath.Join(t.TempDir(), "cache")))
+
+	if entry, err := analyser.loadCache(function); entry != nil || err != nil {
+		t.Fatalf("empty cache returned %v, %v", entry, err)
+	}
+	stored := &CacheEntry{
+		Function: function.String(),
+		Hash:     "abc",
+		Paths:    []CachedPath{{PathCondition: "x > 10", Inputs: map[string]string{"x": "11"}, Result: "12"}},
+	}
+	if err := analyser.storeCache(stored); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := analyser.loadCache(function)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Hash != "abc" || len(loaded.Paths) != 1 || loaded.Paths[0].Inputs["x"] != "11" || loaded.Paths[0].Result != "12" {
+		t.Errorf("loaded entry = %+v", loaded)
+	}
+
+	if err := os.WriteFile(analyser.cacheFile(function.String()), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := analyser.loadCache(function); err == nil || !strings.Contains(err.Error(), "corrupted cache entry") {
+		t.Errorf("corrupted entry error = %v", err)
+	}
+}
+
+func TestCacheFile(t *testing.T) {
+	analyser := newTestAnalyser(newFakeSolver(2), WithCache("cache"))
+	if got := analyser.cacheFile("(*pkg/sub.T).Method"); got != filepath.Join("cache", "_pkg_sub.T_.Method.json") {
+		t.Errorf("cacheFile = %s", got)
+	}
+}
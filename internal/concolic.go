@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// Подключение к интерпретатору. Analyse передаёт analyser.ConcreteInputs
+// начальному состоянию, и в конколическом режиме (interpreter.IsConcolic())
+// обработчик *ssa.If не разветвляет состояние, а следует ветви, выбранной
+// interpreter.concolicBranch: каждый запуск проходит единственный путь, а
+// условия его ветвлений накапливаются в Branches для expandExecution.
+
+// defaultConcolicRuns ограничивает число конкретных запусков по умолчанию
+const defaultConcolicRuns = 100
+
+// concolicInput — входные данные очередного запуска и граница поколения:
+// условия ветвлений с индексом меньше bound уже были инвертированы предками
+type concolicInput struct {
+	inputs solver.Model
+	bound  int
+}
+
+// WithConcolicRuns ограничивает число конкретных запусков в конколическом режиме
+func WithConcolicRuns(runs int) Option {
+	return func(analyser *Analyser) {
+		analyser.ConcolicRuns = runs
+	}
+}
+
+// AnalyseConcolic исследует функцию конколически, начиная с seeds и записей
+// корпуса WithFuzzCorpus (пустой набор означает запуск с нулевыми значениями
+// входов). Вместе с ошибками возвращаются все пройденные пути.
+func AnalyseConcolic(source string, functionName string, seeds []solver.Model, options ...Option) ([]Interpreter, error) {
+	return NewAnalyser(options...).AnalyseConcolic(source, functionName, seeds)
+}
+
+// AnalyseConcolic выполняет поколенческий поиск в стиле SAGE: каждый запуск
+// проходит один путь по конкретным входам, после чего для каждого ветвления
+// пути, начиная с границы поколения, инвертируется его условие и солвер
+// строит входы для нового запуска. Ошибки чтения корпуса и построения
+// входов не прерывают поиск, а возвращаются вместе с пройденными путями.
+func (analyser *Analyser) AnalyseConcolic(source string, functionName string, seeds []solver.Model) ([]Interpreter, error) {
+	// Повреждённый корпус не мешает анализу: используются прочитанные записи
+	corpus, corpusErr := analyser.corpusSeeds(source, functionName)
+	seeds = append(slices.Clip(seeds), corpus...)
+	defer func() { analyser.ConcreteInputs = nil }()
+	results, err := analyser.concolicSearch(seeds, func(inputs solver.Model) []Interpreter {
+		analyser.ConcreteInputs = inputs
+		return analyser.Analyse(source, functionName)
+	})
+	return results, errors.Join(corpusErr, err)
+}
+
+// concolicSearch выполняет поиск AnalyseConcolic, получая пути запусков от run
+func (analyser *Analyser) concolicSearch(seeds []solver.Model, run func(inputs solver.Model) []Interpreter) ([]Interpreter, error) {
+	if len(seeds) == 0 {
+		seeds = []solver.Model{{}}
+	}
+	runs := analyser.ConcolicRuns
+	if runs <= 0 {
+		runs = defaultConcolicRuns
+	}
+
+	var worklist []concolicInput
+	seen := make(map[string]bool)
+	for _, seed := range seeds {
+		if key := modelKey(seed); !seen[key] {
+			seen[key] = true
+			worklist = append(worklist, concolicInput{inputs: seed})
+		}
+	}
+
+	var results []Interpreter
+	var errs []error
+	for executed := 0; executed < runs && len(worklist) > 0; executed++ {
+		input := worklist[0]
+		worklist = worklist[1:]
+
+		analyser.repairs = nil
+		var children []concolicInput
+		for _, final := range run(input.inputs) {
+			results = append(results, final)
+
+			// Входы, построенные до ошибки, всё равно исследуются
+			expanded, err := analyser.expandExecution(final, input.bound)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("expanding run on inputs {%s}: %w", modelKey(input.inputs), err))
+			}
+			children = append(children, expanded...)
+		}
+		// Исправленный запуск проходит тот же префикс пути, ветвления
+		// которого в отброшенном запуске не инвертировались
+		for _, repaired := range analyser.repairs {
+			children = append(children, concolicInput{inputs: repaired, bound: input.bound})
+		}
+		for _, child := range children {
+			if key := modelKey(child.inputs); !seen[key] {
+				seen[key] = true
+				worklist = append(worklist, child)
+			}
+		}
+	}
+	analyser.repairs = nil
+	return results, errors.Join(errs...)
+}
+
+// expandExecution строит входы для путей, отличающихся от пройденного
+// в одном ветвлении с индексом не меньше bound. Допущения не инвертируются:
+// они остаются в префиксе каждого нового пути.
+func (analyser *Analyser) expandExecution(final Interpreter, bound int) ([]concolicInput, error) {
+	var children []concolicInput
+	for j := bound; j < len(final.Branches); j++ {
+		if slices.Contains(final.Assumptions, j) {
+			continue
+		}
+		constraints := append([]symbolic.SymbolicExpression{}, final.Branches[:j]...)
+		constraints = append(constraints, negate(final.Branches[j]))
+
+		result, model, err := analyser.checkSat(symbolic.NewLogicalOperation(constraints, symbolic.AND))
+		if err != nil {
+			return children, err
+		}
+		if result != solver.SAT {
+			continue
+		}
+
+		children = append(children, concolicInput{inputs: childInputs(final.ConcreteInputs, model), bound: j + 1})
+	}
+	return children, nil
+}
+
+// childInputs строит входы нового запуска из модели солвера. Входы, не
+// участвующие в условиях, сохраняют значения родителя.
+func childInputs(parent solver.Model, model solver.Model) solver.Model {
+	inputs := make(solver.Model, len(parent)+len(model))
+	maps.Copy(inputs, parent)
+	maps.Copy(inputs, model)
+	return inputs
+}
+
+// IsConcolic проверяет, исполняется ли состояние по конкретным входам
+func (interpreter *Interpreter) IsConcolic() bool {
+	return interpreter.ConcreteInputs != nil
+}
+
+// concolicBranch выбирает ветвь по конкретным входам вместо разветвления
+// состояния и добавляет к условию пути условие выбранной ветви.
+// Обработчик *ssa.If в конколическом режиме следует единственной ветви.
+func (interpreter *Interpreter) concolicBranch(condition symbolic.SymbolicExpression) (bool, error) {
+	value, err := symbolic.Evaluate(condition, interpreter.ConcreteInputs)
+	if err != nil {
+		return false, err
+	}
+	taken, ok := value.(*symbolic.BoolConstant)
+	if !ok {
+		return false, fmt.Errorf("branch condition %s is not boolean", condition)
+	}
+
+	constraint := condition
+	if !taken.Value {
+		constraint = negate(condition)
+	}
+	interpreter.Branches = append(interpreter.Branches, constraint)
+	interpreter.addConstraint(constraint)
+	return taken.Value, nil
+}
+
+// concolicAssume добавляет допущение к пути в конколическом режиме.
+// Допущение записывается в Branches, чтобы пути, построенные
+// expandExecution, его сохраняли, и отмечается в Assumptions, чтобы оно
+// само не инвертировалось. Если на конкретных входах допущение ложно,
+// возвращается false и запуск отбрасывается, а входы, проходящие тот же
+// путь с выполненным допущением, откладываются в analyser.repairs.
+func (interpreter *Interpreter) concolicAssume(condition symbolic.SymbolicExpression) (bool, error) {
+	value, err := symbolic.Evaluate(condition, interpreter.ConcreteInputs)
+	if err != nil {
+		return false, err
+	}
+	holds, ok := value.(*symbolic.BoolConstant)
+	if !ok {
+		return false, fmt.Errorf("assumption %s is not boolean", condition)
+	}
+
+	if !holds.Value {
+		path := condition
+		if interpreter.PathCondition != nil {
+			path = symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{interpreter.PathCondition, condition}, symbolic.AND)
+		}
+		result, model, err := interpreter.Analyser.checkSat(path)
+		if err != nil || result != solver.SAT {
+			return false, err
+		}
+		interpreter.Analyser.repairs = append(interpreter.Analyser.repairs, childInputs(interpreter.ConcreteInputs, model))
+		return false, nil
+	}
+
+	interpreter.Assumptions = append(interpreter.Assumptions, len(interpreter.Branches))
+	interpreter.Branches = append(interpreter.Branches, condition)
+	interpreter.addConstraint(condition)
+	return true, nil
+}
+
+// negate строит отрицание условия
+func negate(condition symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{condition}, symbolic.NOT)
+}
+
+// modelKey строит канонический ключ набора входов для исключения повторных запусков
+func modelKey(model solver.Model) string {
+	entries := make([]string, 0, len(model))
+	for name, value := range model {
+		entries = append(entries, name+"="+value.String())
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ";")
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestConcolicBranch(t *testing.T) {
+	condition := compare(intVar("x"), symbolic.GT, intConst(0))
+	tests := []struct {
+		name      string
+		inputs    solver.Model
+		want      bool
+		negated   bool
+		wantError bool
+	}{
+		{"taken", solver.Model{"x": intConst(5)}, true, false, false},
+		{"missing input is zero", solver.Model{}, false, true, false},
+		{"not taken", solver.Model{"x": intConst(-1)}, false, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.negated {
+				requireExpressions(t)
+			}
+			interpreter := Interpreter{ConcreteInputs: test.inputs}
+			if !interpreter.IsConcolic() {
+				t.Fatal("state with concrete inputs is not concolic")
+			}
+			taken, err := interpreter.concolicBranch(condition)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if taken != test.want {
+				t.Errorf("concolicBranch = %t, want %t", taken, test.want)
+			}
+			if len(interpreter.Branches) != 1 || interpreter.PathCondition != interpreter.Branches[0] {
+				t.Fatalf("branch constraint was not recorded: %v", interpreter.Branches)
+			}
+			negation, isNegation := interpreter.Branches[0].(*symbolic.LogicalOperation)
+			if test.negated != (isNegation && negation.Operator == symbolic.NOT) {
+				t.Errorf("recorded constraint %v, negated = %t", interpreter.Branches[0], test.negated)
+			}
+		})
+	}
+}
+
+func TestConcolicBranchRejectsNonBoolean(t *testing.T) {
+	interpreter := Interpreter{ConcreteInputs: solver.Model{}}
+	if _, err := interpreter.concolicBranch(intVar("x")); err == nil {
+		t.Error("expected an error for an integer branch condition")
+	}
+}
+
+func TestModelKey(t *testing.T) {
+	first := solver.Model{"x": intConst(1), "b": symbolic.NewBoolConstant(true)}
+	second := solver.Model{"b": symbolic.NewBoolConstant(true), "x": intConst(1)}
+	if modelKey(first) != modelKey(second) {
+		t.Errorf("keys differ for equal models: %q, %q", modelKey(first), modelKey(second))
+	}
+	if modelKey(first) == modelKey(solver.Model{"x": intConst(2), "b": symbolic.NewBoolConstant(true)}) {
+		t.Error("different models share a key")
+	}
+}
+
+func TestExpandExecution(t *testing.T) {
+	requireExpressions(t)
+	x, y := intVar("x"), intVar("y")
+	final := Interpreter{
+		ConcreteInputs: solver.Model{"x": intConst(1), "y": intConst(1), "z": intConst(9)},
+		Branches: []symbolic.SymbolicExpression{
+			compare(x, symbolic.GT, intConst(0)),
+			compare(y, symbolic.GT, intConst(0)),
+		},
+	}
+	analyser := newTestAnalyser(newFakeSolver(4))
+
+	children, err := analyser.expandExecution(final, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("expandExecution returned %d children, want 2", len(children))
+	}
+	for i, child := range children {
+		if child.bound != i+1 {
+			t.Errorf("child %d has bound %d, want %d", i, child.bound, i+1)
+		}
+		if child.inputs["z"].String() != "9" {
+			t.Errorf("child %d lost the unconstrained input: %v", i, child.inputs)
+		}
+	}
+	if value := children[0].inputs["x"].(*symbolic.IntConstant).Value; value > 0 {
+		t.Errorf("first child keeps x = %d, want the first branch negated", value)
+	}
+	if x, y := children[1].inputs["x"].(*symbolic.IntConstant).Value, children[1].inputs["y"].(*symbolic.IntConstant).Value; x <= 0 || y > 0 {
+		t.Errorf("second child x = %d, y = %d, want only the second branch negated", x, y)
+	}
+
+	// Ветвления до границы поколения уже инвертированы предками
+	children, err = analyser.expandExecution(final, 2)
+	if err != nil || len(children) != 0 {
+		t.Errorf("expandExecution above the last branch = %v, %v", children, err)
+	}
+}
+
+// branchRun имитирует запуск функции, ветвящейся по x > 0 и y > 0
+func branchRun(inputs solver.Model) []Interpreter {
+	final := Interpreter{ConcreteInputs: inputs}
+	for _, name := range []string{"x", "y"} {
+		_, _ = final.concolicBranch(compare(intVar(name), symbolic.GT, intConst(0)))
+	}
+	return []Interpreter{final}
+}
+
+func TestConcolicSearch(t *testing.T) {
+	requireExpressions(t)
+	analyser := newTestAnalyser(newFakeSolver(2))
+	results, err := analyser.concolicSearch(nil, branchRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Четыре комбинации знаков x и y
+	if len(results) != 4 {
+		t.Errorf("concolicSearch ran %d paths, want 4", len(results))
+	}
+}
+
+func TestConcolicSearchReportsExpansionErrors(t *testing.T) {
+	requireExpressions(t)
+	backend := newFakeSolver(2)
+	backend.failAfter = 1
+	analyser := newTestAnalyser(backend)
+	results, err := analyser.concolicSearch([]solver.Model{{}}, branchRun)
+	if err == nil {
+		t.Fatal("expected the solver failure to be reported")
+	}
+	// Вход, построенный до ошибки, всё равно исполняется
+	if len(results) != 2 {
+		t.Errorf("concolicSearch ran %d paths, want 2", len(results))
+	}
+}
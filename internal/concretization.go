@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// UnsupportedPolicy определяет обработку инструкций, которые интерпретатор
+// не умеет моделировать: вызовы fmt, math, sort, арифметика unsafe.Pointer и т.п.
+type UnsupportedPolicy int
+
+const (
+	// FailOnUnsupported завершает состояние ошибкой UnsupportedError
+	FailOnUnsupported UnsupportedPolicy = iota
+	// ConcretizeUnsupported подставляет вместо операндов их значения из модели
+	// текущего пути и продолжает исполнение с недоаппроксимацией
+	ConcretizeUnsupported
+)
+
+// String возвращает строковое представление политики
+func (policy UnsupportedPolicy) String() string {
+	switch policy {
+	case FailOnUnsupported:
+		return "fail"
+	case ConcretizeUnsupported:
+		return "concretize"
+	default:
+		return "unknown"
+	}
+}
+
+// WithUnsupportedPolicy задаёт обработку неподдерживаемых инструкций
+func WithUnsupportedPolicy(policy UnsupportedPolicy) Option {
+	return func(analyser *Analyser) {
+		analyser.UnsupportedPolicy = policy
+	}
+}
+
+// UnsupportedError сообщает об инструкции, которую нельзя смоделировать
+type UnsupportedError struct {
+	Instruction ssa.Instruction
+}
+
+func (ue *UnsupportedError) Error() string {
+	return fmt.Sprintf("unsupported instruction %s", ue.Instruction)
+}
+
+// Concretization — запись о замене символьного выражения конкретным значением.
+// Условие Expression == Value добавляется к условию пути. Instruction равна
+// nil, если переменная зафиксирована политикой ConcretizeUnknown.
+type Concretization struct {
+	Instruction ssa.Instruction
+	Expression  symbolic.SymbolicExpression
+	Value       symbolic.SymbolicExpression
+}
+
+// unsupported обрабатывает инструкцию, которую интерпретатор не умеет
+// моделировать, согласно UnsupportedPolicy. Возвращает конкретизированные
+// операнды, с которыми можно продолжить исполнение.
+//
+// Обработчик инструкции вызывает unsupported для вызовов fmt, math, sort и
+// арифметики unsafe.Pointer вместо того, чтобы моделировать их; при ошибке
+// состояние завершается с ней.
+func (interpreter *Interpreter) unsupported(instruction ssa.Instruction, operands ...symbolic.SymbolicExpression) ([]symbolic.SymbolicExpression, error) {
+	if interpreter.Analyser.UnsupportedPolicy != ConcretizeUnsupported {
+		interpreter.Analyser.recordUnsupported(instruction)
+		return nil, &UnsupportedError{Instruction: instruction}
+	}
+	return interpreter.concretize(instruction, operands...)
+}
+
+// concretize заменяет символьные операнды их значениями в модели текущего
+// пути и фиксирует эти значения в условии пути. Состояние помечается как
+// Incomplete: оно покрывает лишь часть входов исходного пути.
+func (interpreter *Interpreter) concretize(instruction ssa.Instruction, operands ...symbolic.SymbolicExpression) ([]symbolic.SymbolicExpression, error) {
+	model, err := interpreter.currentModel()
+	if err != nil {
+		return nil, err
+	}
+
+	concrete := make([]symbolic.SymbolicExpression, len(operands))
+	for i, operand := range operands {
+		if isConcrete(operand) {
+			concrete[i] = operand
+			continue
+		}
+
+		value, err := symbolic.Evaluate(operand, model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to concretize %s: %w", operand, err)
+		}
+		concrete[i] = value
+
+		interpreter.addConstraint(symbolic.NewBinaryOperation(operand, value, symbolic.EQ))
+		interpreter.Concretizations = append(interpreter.Concretizations, Concretization{
+			Instruction: instruction,
+			Expression:  operand,
+			Value:       value,
+		})
+	}
+
+	interpreter.Incomplete = true
+	return concrete, nil
+}
+
+// opaqueResult создаёт свежую символьную переменную для результата
+// неподдерживаемого вызова, значение которого интерпретатор вычислить не может.
+// Обработчик вызова использует её после успешного unsupported.
+func (interpreter *Interpreter) opaqueResult(value ssa.Value) (symbolic.SymbolicExpression, error) {
+	exprType, ok := expressionType(value.Type())
+	if !ok {
+		return nil, fmt.Errorf("unsupported result type %s of %s", value.Type(), value)
+	}
+	interpreter.Incomplete = true
+	return interpreter.freshValue("opaque_"+value.Name(), exprType), nil
+}
+
+// currentModel возвращает значения входов текущего пути: конкретные входы
+// в конколическом режиме, иначе последнюю модель, если она всё ещё
+// удовлетворяет условию пути, или новую модель от солвера
+func (interpreter *Interpreter) currentModel() (solver.Model, error) {
+	if interpreter.IsConcolic() {
+		return interpreter.ConcreteInputs, nil
+	}
+	if interpreter.PathCondition == nil {
+		return interpreter.Model, nil
+	}
+	if interpreter.Model != nil && satisfies(interpreter.Model, interpreter.PathCondition) {
+		return interpreter.Model, nil
+	}
+
+	result, model, err := interpreter.Analyser.checkSat(interpreter.PathCondition)
+	if err != nil {
+		return nil, err
+	}
+	if result != solver.SAT {
+		return nil, fmt.Errorf("no model for path condition: %s", result)
+	}
+	interpreter.Model = model
+	return model, nil
+}
+
+// satisfies проверяет, что модель выполняет условие. Ограничения, добавленные
+// после последнего вызова солвера, могут сделать сохранённую модель устаревшей.
+func satisfies(model solver.Model, condition symbolic.SymbolicExpression) bool {
+	value, err := symbolic.Evaluate(condition, model)
+	if err != nil {
+		return false
+	}
+	holds, ok := value.(*symbolic.BoolConstant)
+	return ok && holds.Value
+}
+
+// isConcrete проверяет, является ли выражение константой
+func isConcrete(expr symbolic.SymbolicExpression) bool {
+	switch expr.(type) {
+	case *symbolic.IntConstant, *symbolic.BoolConstant:
+		return true
+	default:
+		return false
+	}
+}
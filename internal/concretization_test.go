@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"errors"
+	"go/constant"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestUnsupportedFails(t *testing.T) {
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4))}
+	_, err := interpreter.unsupported(&ssa.Jump{}, intVar("x"))
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("unsupported() error = %v, want UnsupportedError", err)
+	}
+	if interpreter.Incomplete {
+		t.Error("failed state marked incomplete")
+	}
+}
+
+func TestCurrentModel(t *testing.T) {
+	x := intVar("x")
+	tests := []struct {
+		name      string
+		condition symbolic.SymbolicExpression
+		model     solver.Model
+		want      string
+		checks    int
+	}{
+		{"no path condition", nil, solver.Model{"x": intConst(3)}, "3", 0},
+		{"cached model still holds", compare(x, symbolic.GT, intConst(2)), solver.Model{"x": intConst(3)}, "3", 0},
+		{"stale model is replaced", compare(x, symbolic.LT, intConst(0)), solver.Model{"x": intConst(3)}, "-1", 1},
+		{"no model yet", compare(x, symbolic.EQ, intConst(2)), nil, "2", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := newFakeSolver(4)
+			interpreter := Interpreter{
+				Analyser:      newTestAnalyser(backend),
+				PathCondition: test.condition,
+				Model:         test.model,
+			}
+			model, err := interpreter.currentModel()
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := symbolic.Evaluate(x, model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value.String() != test.want {
+				t.Errorf("x = %s, want %s", value, test.want)
+			}
+			if backend.checks != test.checks {
+				t.Errorf("solver queried %d times, want %d", backend.checks, test.checks)
+			}
+		})
+	}
+}
+
+func TestCurrentModelInfeasible(t *testing.T) {
+	x := intVar("x")
+	interpreter := Interpreter{
+		Analyser:      newTestAnalyser(newFakeSolver(4)),
+		PathCondition: logical(symbolic.AND, compare(x, symbolic.GT, intConst(0)), compare(x, symbolic.LT, intConst(0))),
+	}
+	if model, err := interpreter.currentModel(); err == nil {
+		t.Errorf("currentModel() = %v for an infeasible path", model)
+	}
+}
+
+func TestConcretize(t *testing.T) {
+	requireExpressions(t)
+	x, y := intVar("x"), intVar("y")
+	interpreter := Interpreter{
+		Analyser:      newTestAnalyser(newFakeSolver(4), WithUnsupportedPolicy(ConcretizeUnsupported)),
+		PathCondition: compare(x, symbolic.GT, intConst(2)),
+		Model:         solver.Model{"x": intConst(0)},
+	}
+
+	concrete, err := interpreter.unsupported(&ssa.Jump{}, x, intConst(7), y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !interpreter.Incomplete {
+		t.Error("concretized state is not marked incomplete")
+	}
+	if len(interpreter.Concretizations) != 2 {
+		t.Fatalf("recorded %d concretizations, want 2", len(interpreter.Concretizations))
+	}
+	for i, operand := range concrete {
+		if !isConcrete(operand) {
+			t.Errorf("operand %d = %s is not concrete", i, operand)
+		}
+	}
+	if concrete[1].String() != "7" {
+		t.Errorf("constant operand changed to %s", concrete[1])
+	}
+	// Значение берётся из модели текущего пути, а не из устаревшей
+	if !satisfies(solver.Model{"x": concrete[0], "y": concrete[2]}, interpreter.PathCondition) {
+		t.Errorf("concretized values %v violate the path condition %s", concrete, interpreter.PathCondition)
+	}
+}
+
+func TestOpaqueResult(t *testing.T) {
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4))}
+	first, err := interpreter.opaqueResult(ssa.NewConst(constant.MakeInt64(1), types.Typ[types.Int]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := interpreter.opaqueResult(ssa.NewConst(constant.MakeBool(true), types.Typ[types.Bool]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !interpreter.Incomplete {
+		t.Error("state with an opaque result is not marked incomplete")
+	}
+	if first.Type() != symbolic.IntType || second.Type() != symbolic.BoolType {
+		t.Errorf("opaque types = %s, %s", first.Type(), second.Type())
+	}
+	if first.(*symbolic.SymbolicVariable).Name == second.(*symbolic.SymbolicVariable).Name {
+		t.Error("opaque results share a variable")
+	}
+	if _, err := interpreter.opaqueResult(ssa.NewConst(constant.MakeString("s"), types.Typ[types.String])); err == nil {
+		t.Error("expected an error for a string result")
+	}
+}
@@ -0,0 +1,534 @@
+package internal
+
+import (
+	"fmt"
+	"go/types"
+	"maps"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// Подключение к интерпретатору. Обработчики инструкций вызывают: *ssa.Go —
+// spawn, *ssa.MakeChan — makeChan, *ssa.Send — send, *ssa.UnOp с token.ARROW —
+// receive, *ssa.Select — selectCases, встроенный close — closeChannel,
+// *ssa.Panic — panicGoroutine. После операций над каналами и *ssa.Go, а также
+// при блокировке горутины обработчик возвращает schedule(), а при возврате из
+// последней функции горутины — exitGoroutine(). Любое ветвление состояния
+// копируется через fork.
+
+// defaultContextSwitchBound ограничивает число вытеснений на пути по умолчанию
+const defaultContextSwitchBound = 2
+
+// nilChannel — номер, которым представляется нулевое значение канала
+const nilChannel = -1
+
+// WithContextSwitchBound ограничивает число вытеснений горутин на одном пути
+func WithContextSwitchBound(bound int) Option {
+	return func(analyser *Analyser) {
+		analyser.ContextSwitchBound = bound
+	}
+}
+
+// GoroutineStatus — состояние горутины
+type GoroutineStatus int
+
+const (
+	Runnable GoroutineStatus = iota
+	Blocked
+	Finished
+)
+
+// Goroutine — отдельная горутина программы. Стек текущей горутины хранится
+// в Interpreter.CallStack и сохраняется сюда при переключении.
+type Goroutine struct {
+	ID        int
+	CallStack []CallStackFrame
+	Status    GoroutineStatus
+	// Waiting — операции, на которых заблокирована горутина
+	Waiting []ChannelOp
+	// Completed — результат операции, завершённой другой горутиной,
+	// пока эта была заблокирована
+	Completed *SelectOutcome
+	// Clock — векторные часы горутины для отношения happens-before
+	Clock     VectorClock
+	blockedAt int
+}
+
+// Channel — модель канала. Значение канала в символьных выражениях —
+// целочисленная константа с его номером.
+type Channel struct {
+	ID       int
+	Capacity int
+	Buffer   []symbolic.SymbolicExpression
+	Closed   bool
+	// Zero — нулевое значение элемента, получаемое из закрытого канала
+	Zero symbolic.SymbolicExpression
+	// clocks — часы отправителей сообщений в буфере, closeClock — часы закрытия
+	clocks     []VectorClock
+	closeClock VectorClock
+}
+
+// ChannelOp — операция отправки или получения, в том числе случай select
+type ChannelOp struct {
+	Channel symbolic.SymbolicExpression
+	Send    bool
+	Value   symbolic.SymbolicExpression
+}
+
+// SelectOutcome — результат операции над каналами: номер выполненного
+// случая (-1 для default), полученное значение и признак ok
+type SelectOutcome struct {
+	Case  int
+	Value symbolic.SymbolicExpression
+	OK    bool
+}
+
+// Scheduler хранит горутины и каналы состояния
+type Scheduler struct {
+	Goroutines []*Goroutine
+	Channels   []*Channel
+	Current    int
+	// Switches — число вытеснений на пути
+	Switches int
+	// Trace — номера горутин в порядке переключений
+	Trace []int
+	// Mutexes — модели sync.Mutex по строковому представлению указателя
+	Mutexes map[string]Mutex
+	clock   int
+	shadow  map[string]*shadowCell
+}
+
+// clone создаёт независимую копию планировщика для нового состояния
+func (scheduler *Scheduler) clone() *Scheduler {
+	cloned := *scheduler
+	cloned.Goroutines = make([]*Goroutine, len(scheduler.Goroutines))
+	for i, goroutine := range scheduler.Goroutines {
+		copied := *goroutine
+		copied.CallStack = cloneCallStack(goroutine.CallStack)
+		copied.Waiting = slices.Clone(goroutine.Waiting)
+		cloned.Goroutines[i] = &copied
+	}
+	cloned.Channels = make([]*Channel, len(scheduler.Channels))
+	for i, channel := range scheduler.Channels {
+		copied := *channel
+		copied.Buffer = slices.Clone(channel.Buffer)
+		copied.clocks = slices.Clone(channel.clocks)
+		cloned.Channels[i] = &copied
+	}
+	cloned.Trace = slices.Clone(scheduler.Trace)
+	cloned.Mutexes = maps.Clone(scheduler.Mutexes)
+	cloned.shadow = make(map[string]*shadowCell, len(scheduler.shadow))
+	for location, cell := range scheduler.shadow {
+		cloned.shadow[location] = cell.clone()
+	}
+	return &cloned
+}
+
+// fork создаёт независимую копию состояния: стек вызовов, горутины, каналы
+// и память Heap (через Memory.Clone). При записи дерева исполнения копия
+// становится дочерним узлом состояния.
+func (interpreter *Interpreter) fork() Interpreter {
+	forked := *interpreter
+	forked.CallStack = cloneCallStack(interpreter.CallStack)
+	forked.Branches = slices.Clip(interpreter.Branches)
+	forked.Assumptions = slices.Clip(interpreter.Assumptions)
+	forked.Concretizations = slices.Clip(interpreter.Concretizations)
+	forked.Findings = slices.Clip(interpreter.Findings)
+	forked.Executed = slices.Clip(interpreter.Executed)
+	forked.Blocks = slices.Clip(interpreter.Blocks)
+	if interpreter.Heap != nil {
+		forked.Heap = interpreter.Heap.Clone()
+	}
+	if interpreter.Scheduler != nil {
+		forked.Scheduler = interpreter.Scheduler.clone()
+	}
+	interpreter.branchNode(&forked)
+	return forked
+}
+
+func cloneCallStack(callStack []CallStackFrame) []CallStackFrame {
+	cloned := make([]CallStackFrame, len(callStack))
+	for i, frame := range callStack {
+		frame.LocalMemory = maps.Clone(frame.LocalMemory)
+		cloned[i] = frame
+	}
+	return cloned
+}
+
+// scheduler возвращает планировщик состояния, создавая его с главной
+// горутиной при первой операции над горутинами или каналами
+func (interpreter *Interpreter) scheduler() *Scheduler {
+	if interpreter.Scheduler == nil {
+		interpreter.Scheduler = &Scheduler{
+			Goroutines: []*Goroutine{{ID: 0, Status: Runnable, Clock: VectorClock{1}}},
+		}
+	}
+	return interpreter.Scheduler
+}
+
+// currentGoroutine возвращает исполняемую горутину
+func (interpreter *Interpreter) currentGoroutine() *Goroutine {
+	scheduler := interpreter.scheduler()
+	return scheduler.Goroutines[scheduler.Current]
+}
+
+// spawn создаёт горутину для инструкции go. args — значения свободных
+// переменных замыкания и параметров функции в порядке FreeVars, Params.
+func (interpreter *Interpreter) spawn(function *ssa.Function, args []symbolic.SymbolicExpression) {
+	scheduler := interpreter.scheduler()
+	locals := make(map[string]symbolic.SymbolicExpression, len(args))
+	names := make([]string, 0, len(function.FreeVars)+len(function.Params))
+	for _, freeVar := range function.FreeVars {
+		names = append(names, freeVar.Name())
+	}
+	for _, param := range function.Params {
+		names = append(names, param.Name())
+	}
+	for i, arg := range args {
+		if i < len(names) {
+			locals[names[i]] = arg
+		}
+	}
+
+	// Инструкция go предшествует первой инструкции новой горутины
+	id := len(scheduler.Goroutines)
+	scheduler.Goroutines = append(scheduler.Goroutines, &Goroutine{
+		ID:        id,
+		CallStack: []CallStackFrame{{Function: function, LocalMemory: locals}},
+		Status:    Runnable,
+		Clock:     interpreter.release().tick(id),
+	})
+}
+
+// makeChan создаёт канал с элементами типа elem и ёмкостью capacity
+func (interpreter *Interpreter) makeChan(elem types.Type, capacity int) symbolic.SymbolicExpression {
+	scheduler := interpreter.scheduler()
+	zero, _ := zeroValue(elem)
+	scheduler.Channels = append(scheduler.Channels, &Channel{
+		ID:       len(scheduler.Channels),
+		Capacity: capacity,
+		Zero:     zero,
+	})
+	return symbolic.NewIntConstant(int64(len(scheduler.Channels) - 1))
+}
+
+// channel возвращает канал по его значению (nil для нулевого канала)
+func (interpreter *Interpreter) channel(value symbolic.SymbolicExpression) (*Channel, error) {
+	id, ok := value.(*symbolic.IntConstant)
+	if !ok {
+		return nil, fmt.Errorf("channel %s is not concrete", value)
+	}
+	if id.Value == nilChannel {
+		return nil, nil
+	}
+	scheduler := interpreter.scheduler()
+	if id.Value < 0 || id.Value >= int64(len(scheduler.Channels)) {
+		return nil, fmt.Errorf("unknown channel %s", value)
+	}
+	return scheduler.Channels[id.Value], nil
+}
+
+// send выполняет ch <- value. Возвращает false, если горутина заблокировалась:
+// тогда нужно вызвать schedule, а инструкция повторяется после пробуждения.
+func (interpreter *Interpreter) send(ch, value symbolic.SymbolicExpression) (bool, error) {
+	_, done, err := interpreter.channelOp(ChannelOp{Channel: ch, Send: true, Value: value})
+	return done, err
+}
+
+// receive выполняет <-ch. Возвращает false, если горутина заблокировалась.
+func (interpreter *Interpreter) receive(ch symbolic.SymbolicExpression) (SelectOutcome, bool, error) {
+	return interpreter.channelOp(ChannelOp{Channel: ch})
+}
+
+func (interpreter *Interpreter) channelOp(op ChannelOp) (SelectOutcome, bool, error) {
+	if outcome := interpreter.completed(); outcome != nil {
+		return *outcome, true, nil
+	}
+	ops := []ChannelOp{op}
+	ready, err := interpreter.readyCases(ops)
+	if err != nil {
+		return SelectOutcome{}, false, err
+	}
+	if len(ready) == 0 {
+		interpreter.block(ops)
+		return SelectOutcome{}, false, nil
+	}
+	outcome, err := interpreter.commit(ops, ready[0])
+	return outcome, err == nil, err
+}
+
+// selectCases выполняет select и возвращает по состоянию на каждый готовый
+// случай вместе с его результатом. Если ни один случай не готов, select без
+// default блокирует горутину и возвращает пустой набор состояний.
+func (interpreter *Interpreter) selectCases(ops []ChannelOp, blocking bool) ([]Interpreter, []SelectOutcome, error) {
+	if outcome := interpreter.completed(); outcome != nil {
+		return []Interpreter{*interpreter}, []SelectOutcome{*outcome}, nil
+	}
+	ready, err := interpreter.readyCases(ops)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ready) == 0 {
+		if !blocking {
+			return []Interpreter{*interpreter}, []SelectOutcome{{Case: -1}}, nil
+		}
+		interpreter.block(ops)
+		return nil, nil, nil
+	}
+
+	states := make([]Interpreter, 0, len(ready))
+	outcomes := make([]SelectOutcome, 0, len(ready))
+	for _, index := range ready {
+		state := interpreter.fork()
+		outcome, err := state.commit(ops, index)
+		if err != nil {
+			return nil, nil, err
+		}
+		states = append(states, state)
+		outcomes = append(outcomes, outcome)
+	}
+	return states, outcomes, nil
+}
+
+// closeChannel выполняет close(ch) и пробуждает горутины, ожидающие канал
+func (interpreter *Interpreter) closeChannel(ch symbolic.SymbolicExpression) error {
+	channel, err := interpreter.channel(ch)
+	if err != nil {
+		return err
+	}
+	if channel == nil {
+		interpreter.abort(CloseOfClosedChannel, "close of nil channel")
+		return nil
+	}
+	if channel.Closed {
+		interpreter.abort(CloseOfClosedChannel, "close of closed channel")
+		return nil
+	}
+	channel.Closed = true
+	channel.closeClock = interpreter.release()
+
+	// Ожидающие горутины повторят операцию и увидят закрытый канал
+	for _, goroutine := range interpreter.Scheduler.Goroutines {
+		if goroutine.Status == Blocked && interpreter.waitsOn(goroutine, channel) {
+			goroutine.Status = Runnable
+			goroutine.Waiting = nil
+		}
+	}
+	return nil
+}
+
+// panicGoroutine завершает программу паникой в текущей горутине. Через
+// него сообщают и о паниках времени исполнения: делении на ноль
+// (DivideByZeroMessage) и разыменовании nil (NilDereferenceMessage);
+// по первому сообщению symanalysis предлагает проверку делителя.
+func (interpreter *Interpreter) panicGoroutine(message string) {
+	interpreter.abort(GoroutinePanic, message)
+}
+
+// exitGoroutine завершает текущую горутину после возврата из её последней
+// функции. Завершение главной горутины завершает программу, и функция
+// возвращает nil; иначе возвращаются состояния для продолжения исполнения.
+func (interpreter *Interpreter) exitGoroutine() []Interpreter {
+	scheduler := interpreter.Scheduler
+	if scheduler == nil || scheduler.Current == 0 {
+		return nil
+	}
+	interpreter.currentGoroutine().Status = Finished
+	return interpreter.schedule()
+}
+
+// schedule возвращает состояния-наследники для каждой горутины, которая может
+// продолжить исполнение. Продолжение текущей горутины бесплатно, переход
+// к другой при готовой текущей — вытеснение; число вытеснений на пути
+// ограничено Analyser.ContextSwitchBound. Если готовых горутин нет,
+// состояние завершается с ошибкой Deadlock.
+func (interpreter *Interpreter) schedule() []Interpreter {
+	scheduler := interpreter.Scheduler
+	if scheduler == nil || interpreter.Terminated {
+		return []Interpreter{*interpreter}
+	}
+	current := scheduler.Goroutines[scheduler.Current]
+	current.CallStack = interpreter.CallStack
+	preemption := current.Status == Runnable
+
+	var successors []Interpreter
+	if preemption {
+		successors = append(successors, *interpreter)
+	}
+	for _, goroutine := range scheduler.Goroutines {
+		if goroutine.ID == current.ID || goroutine.Status != Runnable {
+			continue
+		}
+		if preemption && scheduler.Switches >= interpreter.Analyser.ContextSwitchBound {
+			break
+		}
+		successor := interpreter.fork()
+		successor.switchTo(goroutine.ID, preemption)
+		successors = append(successors, successor)
+	}
+
+	if len(successors) == 0 {
+		interpreter.abort(Deadlock, "all goroutines are asleep - deadlock!")
+		return []Interpreter{*interpreter}
+	}
+	return successors
+}
+
+// switchTo делает горутину id текущей
+func (interpreter *Interpreter) switchTo(id int, preemption bool) {
+	scheduler := interpreter.Scheduler
+	scheduler.Current = id
+	scheduler.Trace = append(scheduler.Trace, id)
+	if preemption {
+		scheduler.Switches++
+	}
+	interpreter.CallStack = scheduler.Goroutines[id].CallStack
+}
+
+// completed забирает результат операции, завершённой другой горутиной
+func (interpreter *Interpreter) completed() *SelectOutcome {
+	if interpreter.Scheduler == nil {
+		return nil
+	}
+	goroutine := interpreter.currentGoroutine()
+	outcome := goroutine.Completed
+	goroutine.Completed = nil
+	return outcome
+}
+
+// readyCases возвращает номера операций, выполнимых без блокировки
+func (interpreter *Interpreter) readyCases(ops []ChannelOp) ([]int, error) {
+	var ready []int
+	for i, op := range ops {
+		channel, err := interpreter.channel(op.Channel)
+		if err != nil {
+			return nil, err
+		}
+		if channel == nil {
+			continue
+		}
+		switch {
+		case channel.Closed:
+			ready = append(ready, i)
+		case op.Send && len(channel.Buffer) < channel.Capacity,
+			!op.Send && len(channel.Buffer) > 0:
+			ready = append(ready, i)
+		default:
+			// Без места в буфере операция выполнима, если её ждёт другая сторона
+			if waiting, _ := interpreter.waiter(channel, !op.Send); waiting != nil {
+				ready = append(ready, i)
+			}
+		}
+	}
+	return ready, nil
+}
+
+// commit выполняет готовую операцию ops[index]
+func (interpreter *Interpreter) commit(ops []ChannelOp, index int) (SelectOutcome, error) {
+	op := ops[index]
+	channel, err := interpreter.channel(op.Channel)
+	if err != nil {
+		return SelectOutcome{}, err
+	}
+	outcome := SelectOutcome{Case: index}
+
+	if op.Send {
+		if channel.Closed {
+			interpreter.abort(SendOnClosedChannel, "send on closed channel")
+			return outcome, nil
+		}
+		clock := interpreter.release()
+		if receiver, waiting := interpreter.waiter(channel, false); receiver != nil {
+			interpreter.wake(receiver, waiting, op.Value)
+			receiver.Clock = receiver.Clock.join(clock)
+		} else {
+			channel.Buffer = append(channel.Buffer, op.Value)
+			channel.clocks = append(channel.clocks, clock)
+		}
+		outcome.OK = true
+		return outcome, nil
+	}
+
+	sender, waiting := interpreter.waiter(channel, true)
+	switch {
+	case len(channel.Buffer) > 0:
+		outcome.Value, outcome.OK = channel.Buffer[0], true
+		interpreter.acquire(channel.clocks[0])
+		channel.Buffer, channel.clocks = channel.Buffer[1:], channel.clocks[1:]
+		// Освободившееся место занимает первая ожидающая отправка
+		if sender != nil {
+			channel.Buffer = append(channel.Buffer, interpreter.wake(sender, waiting, nil))
+			channel.clocks = append(channel.clocks, sender.Clock)
+			sender.Clock = sender.Clock.tick(sender.ID)
+		}
+	case sender != nil:
+		outcome.Value, outcome.OK = interpreter.wake(sender, waiting, nil), true
+		interpreter.acquire(sender.Clock)
+		sender.Clock = sender.Clock.tick(sender.ID)
+	default:
+		interpreter.acquire(channel.closeClock)
+		if channel.Zero == nil {
+			return outcome, fmt.Errorf("unsupported element type of channel %d", channel.ID)
+		}
+		outcome.Value = channel.Zero
+	}
+	return outcome, nil
+}
+
+// block блокирует текущую горутину на операциях ops
+func (interpreter *Interpreter) block(ops []ChannelOp) {
+	scheduler := interpreter.scheduler()
+	goroutine := interpreter.currentGoroutine()
+	goroutine.Status = Blocked
+	goroutine.Waiting = ops
+	goroutine.blockedAt = scheduler.clock
+	scheduler.clock++
+}
+
+// waiter возвращает горутину, дольше всех ожидающую отправки (send = true)
+// или получения на канале, и номер её операции
+func (interpreter *Interpreter) waiter(channel *Channel, send bool) (*Goroutine, int) {
+	var first *Goroutine
+	var firstIndex int
+	for _, goroutine := range interpreter.Scheduler.Goroutines {
+		if goroutine.Status != Blocked || (first != nil && goroutine.blockedAt >= first.blockedAt) {
+			continue
+		}
+		if index, ok := interpreter.waitingOp(goroutine, channel, send); ok {
+			first, firstIndex = goroutine, index
+		}
+	}
+	return first, firstIndex
+}
+
+// wake завершает операцию index заблокированной горутины: передаёт ей
+// значение value при получении. Возвращает отправленное ею значение.
+func (interpreter *Interpreter) wake(goroutine *Goroutine, index int, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	sent := goroutine.Waiting[index].Value
+	goroutine.Completed = &SelectOutcome{Case: index, Value: value, OK: true}
+	goroutine.Status = Runnable
+	goroutine.Waiting = nil
+	return sent
+}
+
+// waitingOp находит операцию горутины над каналом
+func (interpreter *Interpreter) waitingOp(goroutine *Goroutine, channel *Channel, send bool) (int, bool) {
+	for i, op := range goroutine.Waiting {
+		if op.Send != send {
+			continue
+		}
+		if waited, err := interpreter.channel(op.Channel); err == nil && waited == channel {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// waitsOn проверяет, ожидает ли горутина какую-либо операцию над каналом
+func (interpreter *Interpreter) waitsOn(goroutine *Goroutine, channel *Channel) bool {
+	_, sends := interpreter.waitingOp(goroutine, channel, true)
+	_, receives := interpreter.waitingOp(goroutine, channel, false)
+	return sends || receives
+}
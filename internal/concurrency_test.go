@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"go/types"
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// stubMemory — memory.Memory со значениями полей в отображении
+type stubMemory struct {
+	fields map[int]symbolic.SymbolicExpression
+}
+
+func (mem *stubMemory) Allocate(tpe symbolic.ExpressionType) *symbolic.Ref { return &symbolic.Ref{} }
+
+func (mem *stubMemory) AssignField(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) {
+	mem.fields[fieldIdx] = value
+}
+
+func (mem *stubMemory) GetFieldValue(ref *symbolic.Ref, fieldIdx int) symbolic.SymbolicExpression {
+	return mem.fields[fieldIdx]
+}
+
+func (mem *stubMemory) AssignToArray(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression) {
+	mem.fields[index] = value
+}
+
+func (mem *stubMemory) GetFromArray(ref *symbolic.Ref, index int) symbolic.SymbolicExpression {
+	return mem.fields[index]
+}
+
+func (mem *stubMemory) Clone() memory.Memory {
+	cloned := &stubMemory{fields: make(map[int]symbolic.SymbolicExpression, len(mem.fields))}
+	for index, value := range mem.fields {
+		cloned.fields[index] = value
+	}
+	return cloned
+}
+
+const workerSource = `package main
+
+func worker(ch chan int) { ch <- 1 }
+`
+
+func TestForkIsIndependent(t *testing.T) {
+	heap := &stubMemory{fields: map[int]symbolic.SymbolicExpression{0: intConst(1)}}
+	original := Interpreter{
+		Analyser:  newTestAnalyser(newFakeSolver(4)),
+		Heap:      heap,
+		CallStack: []CallStackFrame{{LocalMemory: map[string]symbolic.SymbolicExpression{"x": intConst(1)}}},
+		Branches:  make([]symbolic.SymbolicExpression, 1, 4),
+	}
+	channel := original.makeChan(types.Typ[types.Int], 2)
+
+	forked := original.fork()
+	forked.Heap.AssignField(nil, 0, intConst(2))
+	forked.CallStack[0].LocalMemory["x"] = intConst(2)
+	forked.Branches = append(forked.Branches, boolVar("b"))
+	if _, err := forked.send(channel, intConst(3)); err != nil {
+		t.Fatal(err)
+	}
+	original.Branches = append(original.Branches, boolVar("c"))
+
+	if heap.fields[0].String() != "1" {
+		t.Errorf("write to the forked heap is visible in the original: %s", heap.fields[0])
+	}
+	if original.CallStack[0].LocalMemory["x"].String() != "1" {
+		t.Error("forked local variables alias the original")
+	}
+	if forked.Branches[1].String() != "b" {
+		t.Errorf("original branch overwrote the forked one: %v", forked.Branches)
+	}
+	if len(original.Scheduler.Channels[0].Buffer) != 0 {
+		t.Error("send in the forked state filled the original channel")
+	}
+}
+
+func TestBufferedChannel(t *testing.T) {
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4))}
+	channel := interpreter.makeChan(types.Typ[types.Int], 1)
+
+	if done, err := interpreter.send(channel, intConst(7)); !done || err != nil {
+		t.Fatalf("send to an empty buffer = %t, %v", done, err)
+	}
+	if done, err := interpreter.send(channel, intConst(8)); done || err != nil {
+		t.Fatalf("send to a full buffer = %t, %v, want blocked", done, err)
+	}
+	if goroutine := interpreter.currentGoroutine(); goroutine.Status != Blocked {
+		t.Errorf("sender status = %d, want Blocked", goroutine.Status)
+	}
+	successors := interpreter.schedule()
+	if len(successors) != 1 || !successors[0].Terminated || successors[0].Findings[0].Kind != Deadlock {
+		t.Errorf("schedule with no runnable goroutines = %+v, want a deadlock", successors)
+	}
+}
+
+func TestUnbufferedHandoff(t *testing.T) {
+	worker := buildFunction(t, workerSource, "worker")
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4))}
+	channel := interpreter.makeChan(types.Typ[types.Int], 0)
+	interpreter.spawn(worker, []symbolic.SymbolicExpression{channel})
+
+	// Главная горутина блокируется на получении и уступает работнику
+	if _, done, err := interpreter.receive(channel); done || err != nil {
+		t.Fatalf("receive without a sender = %t, %v, want blocked", done, err)
+	}
+	successors := interpreter.schedule()
+	if len(successors) != 1 || successors[0].Scheduler.Current != 1 {
+		t.Fatalf("schedule after blocking = %d states, want the worker", len(successors))
+	}
+	state := successors[0]
+	if state.CallStack[0].LocalMemory["ch"] != channel {
+		t.Errorf("worker parameter = %v, want %s", state.CallStack[0].LocalMemory["ch"], channel)
+	}
+
+	if done, err := state.send(channel, intConst(1)); !done || err != nil {
+		t.Fatalf("send to a waiting receiver = %t, %v", done, err)
+	}
+	main := state.Scheduler.Goroutines[0]
+	if main.Status != Runnable || main.Completed == nil || main.Completed.Value.String() != "1" {
+		t.Fatalf("receiver was not woken with the value: %+v", main)
+	}
+	if main.Clock.get(1) == 0 {
+		t.Error("receiver clock is not ordered after the send")
+	}
+
+	exited := state.exitGoroutine()
+	if len(exited) != 1 || exited[0].Scheduler.Current != 0 {
+		t.Fatalf("exit of the worker = %d states, want the main goroutine", len(exited))
+	}
+	outcome, done, err := exited[0].receive(channel)
+	if !done || err != nil || outcome.Value.String() != "1" {
+		t.Errorf("retried receive = %+v, %t, %v", outcome, done, err)
+	}
+}
+
+func TestContextSwitchBound(t *testing.T) {
+	worker := buildFunction(t, workerSource, "worker")
+	for _, bound := range []int{0, 1} {
+		interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4), WithContextSwitchBound(bound))}
+		channel := interpreter.makeChan(types.Typ[types.Int], 0)
+		interpreter.spawn(worker, []symbolic.SymbolicExpression{channel})
+
+		successors := interpreter.schedule()
+		if len(successors) != bound+1 {
+			t.Errorf("bound %d: schedule = %d states, want %d", bound, len(successors), bound+1)
+		}
+		if bound > 0 && successors[1].Scheduler.Switches != 1 {
+			t.Errorf("preemption was not counted: %d", successors[1].Scheduler.Switches)
+		}
+	}
+}
+
+func TestCloseChannel(t *testing.T) {
+	tests := []struct {
+		name    string
+		closed  bool
+		nilChan bool
+	}{
+		{"close of closed channel", true, false},
+		{"close of nil channel", false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4))}
+			channel := interpreter.makeChan(types.Typ[types.Int], 0)
+			if test.nilChan {
+				channel = intConst(nilChannel)
+			}
+			if test.closed {
+				if err := interpreter.closeChannel(channel); err != nil || interpreter.Terminated {
+					t.Fatalf("first close = %v, terminated %t", err, interpreter.Terminated)
+				}
+			}
+			if err := interpreter.closeChannel(channel); err != nil {
+				t.Fatal(err)
+			}
+			if !interpreter.Terminated || interpreter.Findings[0].Kind != CloseOfClosedChannel {
+				t.Errorf("findings = %+v, want CloseOfClosedChannel", interpreter.Findings)
+			}
+		})
+	}
+
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4))}
+	channel := interpreter.makeChan(types.Typ[types.Int], 0)
+	if err := interpreter.closeChannel(channel); err != nil {
+		t.Fatal(err)
+	}
+	outcome, done, err := interpreter.receive(channel)
+	if !done || err != nil || outcome.OK || outcome.Value.String() != "0" {
+		t.Errorf("receive from closed channel = %+v, %t, %v", outcome, done, err)
+	}
+	if _, err := interpreter.send(channel, intConst(1)); err != nil || interpreter.Findings[0].Kind != SendOnClosedChannel {
+		t.Errorf("send on closed channel: %v, findings %+v", err, interpreter.Findings)
+	}
+}
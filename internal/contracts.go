@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/ssa"
+	ssabuilder "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// contract возвращает контракт функции из аннотаций //sym:requires и
+// //sym:ensures (nil, если их нет). Контракты разбираются один раз.
+func (analyser *Analyser) contract(function *ssa.Function) (*ssabuilder.Contract, error) {
+	if contract, ok := analyser.contracts[function]; ok {
+		return contract, nil
+	}
+	var contract *ssabuilder.Contract
+	var err error
+	if analyser.Builder != nil {
+		contract, err = analyser.Builder.Contract(function)
+	} else {
+		contract, err = ssabuilder.ParseContract(function, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if analyser.contracts == nil {
+		analyser.contracts = make(map[*ssa.Function]*ssabuilder.Contract)
+	}
+	analyser.contracts[function] = contract
+	return contract, nil
+}
+
+// assumeRequires добавляет предусловия анализируемой функции к условию
+// начального состояния: пути, нарушающие их, не исследуются. Analyse
+// вызывает её для начального состояния со значениями параметров. В
+// конколическом режиме предусловия проверяются как допущения
+// (см. concolicAssume); false означает, что конкретные входы нарушают их
+// и запуск отбрасывается.
+func (interpreter *Interpreter) assumeRequires(function *ssa.Function, args []symbolic.SymbolicExpression) (bool, error) {
+	contract, err := interpreter.Analyser.contract(function)
+	if err != nil {
+		return false, err
+	}
+	if contract == nil {
+		return true, nil
+	}
+	bindings := parameterBindings(function, args)
+	for _, clause := range contract.Requires {
+		condition := symbolic.Substitute(clause.Expr, bindings)
+		if !interpreter.IsConcolic() {
+			interpreter.addConstraint(condition)
+			continue
+		}
+		if holds, err := interpreter.concolicAssume(condition); err != nil || !holds {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkRequires проверяет предусловия вызываемой функции с аргументами args.
+// Обработчик вызова обращается к ней перед входом в тело функции.
+func (interpreter *Interpreter) checkRequires(function *ssa.Function, args []symbolic.SymbolicExpression) ([]Interpreter, error) {
+	contract, err := interpreter.Analyser.contract(function)
+	if err != nil || contract == nil {
+		return []Interpreter{*interpreter}, err
+	}
+	return interpreter.checkClauses(contract.Requires, parameterBindings(function, args),
+		PreconditionViolation, "precondition of "+function.Name())
+}
+
+// checkEnsures проверяет постусловия функции при возврате значений results.
+// args — значения параметров функции в текущем вызове. Обработчик
+// *ssa.Return обращается к ней перед выходом из функции.
+func (interpreter *Interpreter) checkEnsures(function *ssa.Function, args, results []symbolic.SymbolicExpression) ([]Interpreter, error) {
+	contract, err := interpreter.Analyser.contract(function)
+	if err != nil || contract == nil {
+		return []Interpreter{*interpreter}, err
+	}
+	bindings := parameterBindings(function, args)
+	for i, variable := range contract.Results {
+		if variable != nil && i < len(results) {
+			bindings[variable.Name] = results[i]
+		}
+	}
+	return interpreter.checkClauses(contract.Ensures, bindings,
+		PostconditionViolation, "postcondition of "+function.Name())
+}
+
+// checkClauses проверяет условия контракта и возвращает продолжающееся
+// состояние (если оно выполнимо) вместе с состояниями-нарушениями
+func (interpreter *Interpreter) checkClauses(clauses []ssabuilder.Clause, bindings map[string]symbolic.SymbolicExpression, kind FindingKind, what string) ([]Interpreter, error) {
+	var violations []Interpreter
+	for _, clause := range clauses {
+		message := fmt.Sprintf("%s %q violated (%s)", what, clause.Text, clause.Pos)
+		continuing, failing, err := interpreter.assert(symbolic.Substitute(clause.Expr, bindings), kind, message)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, failing...)
+		if continuing == nil {
+			// Условие на пути всегда ложно: продолжать нечего
+			return violations, nil
+		}
+		*interpreter = *continuing
+	}
+	return append([]Interpreter{*interpreter}, violations...), nil
+}
+
+// parameterBindings сопоставляет переменным параметров контракта значения аргументов
+func parameterBindings(function *ssa.Function, args []symbolic.SymbolicExpression) map[string]symbolic.SymbolicExpression {
+	bindings := make(map[string]symbolic.SymbolicExpression, len(args))
+	for i, param := range function.Params {
+		if i < len(args) {
+			bindings[param.Name()] = args[i]
+		}
+	}
+	return bindings
+}
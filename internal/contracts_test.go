@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+const contractSource = `package main
+
+//sym:requires n >= 0
+//sym:ensures result >= 1
+func factorial(n int) int {
+	if n == 0 {
+		return 1
+	}
+	return n * factorial(n-1)
+}
+
+func plain(n int) int { return n }
+
+//sym:requires n >
+func broken(n int) int { return n }
+`
+
+func TestAssumeRequires(t *testing.T) {
+	requireExpressions(t)
+	function := buildFunction(t, contractSource, "factorial")
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4))}
+	if ok, err := interpreter.assumeRequires(function, []symbolic.SymbolicExpression{intVar("n")}); !ok || err != nil {
+		t.Fatalf("assumeRequires = %t, %v", ok, err)
+	}
+	if interpreter.PathCondition == nil {
+		t.Fatal("precondition was not assumed")
+	}
+	if satisfies(map[string]symbolic.SymbolicExpression{"n": intConst(-1)}, interpreter.PathCondition) {
+		t.Errorf("path condition %s admits n = -1", interpreter.PathCondition)
+	}
+
+	if _, err := interpreter.assumeRequires(buildFunction(t, contractSource, "broken"), nil); err == nil {
+		t.Error("expected an error for a malformed contract")
+	}
+}
+
+func TestContractsConcolic(t *testing.T) {
+	requireExpressions(t)
+	function := buildFunction(t, contractSource, "factorial")
+	n := intVar("n")
+	analyser := newTestAnalyser(newFakeSolver(4))
+	// Запуск проверяет предусловие и постусловие для результата n + 1,
+	// который нарушает ensures только при n < 0, исключённом requires
+	run := func(inputs solver.Model) []Interpreter {
+		interpreter := Interpreter{Analyser: analyser, ConcreteInputs: inputs}
+		ok, err := interpreter.assumeRequires(function, []symbolic.SymbolicExpression{n})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return nil
+		}
+		states, err := interpreter.checkEnsures(function, []symbolic.SymbolicExpression{n},
+			[]symbolic.SymbolicExpression{compare(n, symbolic.ADD, intConst(1))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return states
+	}
+
+	results, err := analyser.concolicSearch([]solver.Model{{"n": intConst(-1)}}, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("concolicSearch ran %d paths, want 1", len(results))
+	}
+	if results[0].Terminated || len(results[0].Findings) != 0 {
+		t.Errorf("input violating the precondition was reported: %+v", results[0].Findings)
+	}
+	if value := results[0].ConcreteInputs["n"].(*symbolic.IntConstant).Value; value < 0 {
+		t.Errorf("n = %d violates the precondition", value)
+	}
+}
+
+func TestCheckContracts(t *testing.T) {
+	requireExpressions(t)
+	function := buildFunction(t, contractSource, "factorial")
+	x := intVar("x")
+	tests := []struct {
+		name       string
+		path       symbolic.SymbolicExpression
+		check      func(*Interpreter) ([]Interpreter, error)
+		violation  FindingKind
+		violations int
+		continuing bool
+	}{
+		{"argument may be negative", nil, func(i *Interpreter) ([]Interpreter, error) {
+			return i.checkRequires(function, []symbolic.SymbolicExpression{x})
+		}, PreconditionViolation, 1, true},
+		{"argument is non-negative", compare(x, symbolic.GE, intConst(2)), func(i *Interpreter) ([]Interpreter, error) {
+			return i.checkRequires(function, []symbolic.SymbolicExpression{x})
+		}, PreconditionViolation, 0, true},
+		{"argument is always negative", compare(x, symbolic.LT, intConst(0)), func(i *Interpreter) ([]Interpreter, error) {
+			return i.checkRequires(function, []symbolic.SymbolicExpression{x})
+		}, PreconditionViolation, 1, false},
+		{"result may be zero", nil, func(i *Interpreter) ([]Interpreter, error) {
+			return i.checkEnsures(function, []symbolic.SymbolicExpression{intConst(3)}, []symbolic.SymbolicExpression{x})
+		}, PostconditionViolation, 1, true},
+		{"result is positive", nil, func(i *Interpreter) ([]Interpreter, error) {
+			return i.checkEnsures(function, []symbolic.SymbolicExpression{intConst(3)}, []symbolic.SymbolicExpression{intConst(6)})
+		}, PostconditionViolation, 0, true},
+		{"no contract", nil, func(i *Interpreter) ([]Interpreter, error) {
+			return i.checkRequires(buildFunction(t, contractSource, "plain"), []symbolic.SymbolicExpression{x})
+		}, PreconditionViolation, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4)), PathCondition: test.path}
+			states, err := test.check(&interpreter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			violations := 0
+			continuing := false
+			for _, state := range states {
+				if !state.Terminated {
+					continuing = true
+					continue
+				}
+				violations++
+				if finding := state.Findings[0]; finding.Kind != test.violation || !strings.Contains(finding.Message, "of factorial") {
+					t.Errorf("finding = %+v", finding)
+				}
+			}
+			if violations != test.violations || continuing != test.continuing {
+				t.Errorf("%d violations, continuing %t; want %d, %t", violations, continuing, test.violations, test.continuing)
+			}
+		})
+	}
+}
+
+func TestContractIsParsedOnce(t *testing.T) {
+	requireExpressions(t)
+	function := buildFunction(t, contractSource, "factorial")
+	analyser := newTestAnalyser(newFakeSolver(4))
+	first, err := analyser.contract(function)
+	if err != nil || first == nil {
+		t.Fatalf("contract = %v, %v", first, err)
+	}
+	if second, _ := analyser.contract(function); second != first {
+		t.Error("contract was parsed again")
+	}
+}
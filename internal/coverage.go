@@ -0,0 +1,391 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// BranchOutcome — результат исследования ветви условного перехода
+type BranchOutcome int
+
+const (
+	// BranchCovered — ветвь пройдена хотя бы одним завершённым путём (тестом)
+	BranchCovered BranchOutcome = iota
+	// BranchInfeasible — все пути, доходившие до ветвления, несовместны
+	// с условием ветви (солвер вернул UNSAT)
+	BranchInfeasible
+	// BranchSolverUnknown — выполнимость ветви не установлена (UNKNOWN)
+	BranchSolverUnknown
+	// BranchBudgetExhausted — анализ остановлен по бюджету до исследования ветви
+	BranchBudgetExhausted
+	// BranchUnsupported — пути к ветви оборвались на неподдерживаемой инструкции
+	BranchUnsupported
+	// BranchNotReached — ветвь не исследована по другой причине
+	BranchNotReached
+)
+
+// String возвращает описание результата
+func (outcome BranchOutcome) String() string {
+	switch outcome {
+	case BranchCovered:
+		return "covered"
+	case BranchInfeasible:
+		return "infeasible (proven UNSAT)"
+	case BranchSolverUnknown:
+		return "solver returned UNKNOWN"
+	case BranchBudgetExhausted:
+		return "budget exhausted"
+	case BranchUnsupported:
+		return "unsupported instruction"
+	case BranchNotReached:
+		return "not reached"
+	default:
+		return "unknown"
+	}
+}
+
+// edge — переход между базовыми блоками одной функции
+type edge struct {
+	from, to *ssa.BasicBlock
+}
+
+// edgeVerdicts — результаты проверки выполнимости состояний, перешедших по ребру
+type edgeVerdicts struct {
+	sat, unsat, unknown int
+	// infeasible — невыполнимые условия путей, перешедших по ребру
+	infeasible []symbolic.SymbolicExpression
+}
+
+// coverageLog — сведения о достигнутых блоках, собираемые во время анализа
+type coverageLog struct {
+	reached     map[*ssa.BasicBlock]bool
+	verdicts    map[edge]*edgeVerdicts
+	unsupported map[*ssa.BasicBlock]bool
+}
+
+// coverage возвращает журнал покрытия анализатора, создавая его при первом обращении
+func (analyser *Analyser) coverage() *coverageLog {
+	if analyser.coverageLog == nil {
+		analyser.coverageLog = &coverageLog{
+			reached:     make(map[*ssa.BasicBlock]bool),
+			verdicts:    make(map[edge]*edgeVerdicts),
+			unsupported: make(map[*ssa.BasicBlock]bool),
+		}
+	}
+	return analyser.coverageLog
+}
+
+// enterBlock отмечает переход состояния в блок. interpretDynamically
+// вызывает его при каждом переходе, иначе покрытие ветвей не собирается;
+// в ветвях *ssa.If — до resolveState, чтобы результат проверки относился
+// к ребру.
+func (interpreter *Interpreter) enterBlock(block *ssa.BasicBlock) {
+	interpreter.Blocks = append(interpreter.Blocks, block)
+	if interpreter.Analyser != nil {
+		interpreter.Analyser.coverage().reached[block] = true
+	}
+	interpreter.nodeBlock()
+}
+
+// lastEdge возвращает ребро, по которому состояние перешло в текущий блок
+func (interpreter *Interpreter) lastEdge() (edge, bool) {
+	if len(interpreter.Blocks) < 2 {
+		return edge{}, false
+	}
+	from, to := interpreter.Blocks[len(interpreter.Blocks)-2], interpreter.Blocks[len(interpreter.Blocks)-1]
+	for _, successor := range from.Succs {
+		if successor == to {
+			return edge{from, to}, true
+		}
+	}
+	return edge{}, false
+}
+
+// recordVerdict запоминает результат проверки выполнимости состояния для
+// ребра, по которому оно перешло в текущий блок
+func (analyser *Analyser) recordVerdict(interpreter Interpreter, result solver.Result) {
+	transition, ok := interpreter.lastEdge()
+	if !ok {
+		return
+	}
+	verdicts := analyser.coverage().verdicts[transition]
+	if verdicts == nil {
+		verdicts = &edgeVerdicts{}
+		analyser.coverage().verdicts[transition] = verdicts
+	}
+	switch result {
+	case solver.SAT:
+		verdicts.sat++
+	case solver.UNSAT:
+		verdicts.unsat++
+		verdicts.infeasible = append(verdicts.infeasible, interpreter.PathCondition)
+	default:
+		verdicts.unknown++
+	}
+}
+
+// recordUnsupported запоминает блок, в котором путь оборвался на
+// неподдерживаемой инструкции
+func (analyser *Analyser) recordUnsupported(instruction ssa.Instruction) {
+	if block := instruction.Block(); block != nil {
+		analyser.coverage().unsupported[block] = true
+	}
+}
+
+// BranchReport — ветвь условного перехода и результат её исследования
+type BranchReport struct {
+	Location  Location      `json:"location"`
+	Condition string        `json:"condition"`
+	Direction bool          `json:"direction"`
+	Outcome   BranchOutcome `json:"outcome"`
+}
+
+// MarshalText представляет результат строкой в JSON
+func (outcome BranchOutcome) MarshalText() ([]byte, error) {
+	return []byte(outcome.String()), nil
+}
+
+// String возвращает ветвь в виде file:line: if cond (true): outcome
+func (branch BranchReport) String() string {
+	return fmt.Sprintf("%s: if %s (%t): %s", branch.Location, branch.Condition, branch.Direction, branch.Outcome)
+}
+
+// PathCoverage — блоки и строки, которые проходит тест одного пути
+type PathCoverage struct {
+	Blocks []int `json:"blocks"`
+	Lines  []int `json:"lines"`
+}
+
+// CoverageReport — покрытие блоков и ветвей функции тестами её путей
+type CoverageReport struct {
+	Function        string         `json:"function"`
+	Blocks          int            `json:"blocks"`
+	CoveredBlocks   int            `json:"coveredBlocks"`
+	Branches        int            `json:"branches"`
+	CoveredBranches int            `json:"coveredBranches"`
+	Paths           []PathCoverage `json:"paths"`
+	// Unreached — непокрытые ветви, до ветвления которых анализ дошёл
+	Unreached []BranchReport `json:"unreached,omitempty"`
+	// Dead — ветви, невыполнимые на всех путях, которые до них доходят
+	Dead []DeadBranch `json:"dead,omitempty"`
+}
+
+// Coverage сопоставляет конечные состояния анализа функции (каждому
+// соответствует сгенерированный тест) блокам SSA и строкам исходного
+// кода и классифицирует непокрытые ветви по причине
+func (analyser *Analyser) Coverage(function *ssa.Function, finals []Interpreter) CoverageReport {
+	report := CoverageReport{Function: function.Name(), Blocks: len(function.Blocks)}
+	coveredBlocks := make(map[*ssa.BasicBlock]bool)
+	coveredEdges := make(map[edge]bool)
+	for _, final := range finals {
+		var path PathCoverage
+		lines := make(map[int]bool)
+		for i, block := range final.Blocks {
+			if i > 0 {
+				coveredEdges[edge{final.Blocks[i-1], block}] = true
+			}
+			if block.Parent() != function {
+				continue
+			}
+			coveredBlocks[block] = true
+			path.Blocks = append(path.Blocks, block.Index)
+			for _, instruction := range block.Instrs {
+				if location, ok := instructionLocation(instruction); ok {
+					lines[location.Line] = true
+				}
+			}
+		}
+		for line := range lines {
+			path.Lines = append(path.Lines, line)
+		}
+		sort.Ints(path.Lines)
+		report.Paths = append(report.Paths, path)
+	}
+	report.CoveredBlocks = len(coveredBlocks)
+
+	log := analyser.coverage()
+	for _, block := range function.Blocks {
+		branch, ok := block.Instrs[len(block.Instrs)-1].(*ssa.If)
+		if !ok {
+			continue
+		}
+		for i, successor := range block.Succs {
+			report.Branches++
+			transition := edge{block, successor}
+			if coveredEdges[transition] {
+				report.CoveredBranches++
+				continue
+			}
+			verdicts := log.verdicts[transition]
+			if !log.reached[block] && verdicts == nil {
+				continue
+			}
+			location := branchLocation(branch)
+			report.Unreached = append(report.Unreached, BranchReport{
+				Location:  location,
+				Condition: conditionText(branch),
+				Direction: i == 0,
+				Outcome:   analyser.branchOutcome(block, verdicts),
+			})
+		}
+	}
+	report.Dead = analyser.DeadBranches(function)
+	return report
+}
+
+// branchOutcome определяет, почему ветвь из блока не покрыта
+func (analyser *Analyser) branchOutcome(block *ssa.BasicBlock, verdicts *edgeVerdicts) BranchOutcome {
+	log := analyser.coverage()
+	switch {
+	case verdicts != nil && verdicts.sat == 0 && verdicts.unknown == 0 && analyser.exhaustive():
+		return BranchInfeasible
+	case verdicts != nil && verdicts.sat == 0 && verdicts.unknown > 0:
+		return BranchSolverUnknown
+	case log.unsupported[block]:
+		// Путь оборвался в самом блоке, не дойдя до ветвления
+		return BranchUnsupported
+	case analyser.BudgetExhausted:
+		return BranchBudgetExhausted
+	case len(log.unsupported) > 0:
+		// Часть путей, которые могли дойти до ветви, оборвалась раньше
+		return BranchUnsupported
+	default:
+		return BranchNotReached
+	}
+}
+
+// exhaustive проверяет, что анализ исследовал все пути в пределах своих
+// ограничений: бюджет не исчерпан, ни один путь не оборвался на
+// неподдерживаемой инструкции и не отброшен из-за UNKNOWN
+func (analyser *Analyser) exhaustive() bool {
+	log := analyser.coverage()
+	if analyser.BudgetExhausted || len(log.unsupported) > 0 {
+		return false
+	}
+	for _, verdicts := range log.verdicts {
+		if verdicts.unknown > 0 && analyser.UnknownPolicy == DropUnknown {
+			return false
+		}
+	}
+	return true
+}
+
+// branchLocation возвращает позицию ветвления. У *ssa.If её нет, поэтому
+// берётся позиция вычисления условия, а для условий без него (параметр,
+// константа) — последней инструкции блока с позицией
+func branchLocation(branch *ssa.If) Location {
+	if instruction, ok := branch.Cond.(ssa.Instruction); ok {
+		if location, ok := instructionLocation(instruction); ok {
+			return location
+		}
+	}
+	instructions := branch.Block().Instrs
+	for i := len(instructions) - 1; i >= 0; i-- {
+		if location, ok := instructionLocation(instructions[i]); ok {
+			return location
+		}
+	}
+	return Location{}
+}
+
+// conditionText возвращает условие ветвления в виде исходного кода, если
+// SSA сохранил выражение, иначе в виде SSA
+func conditionText(branch *ssa.If) string {
+	if binary, ok := branch.Cond.(*ssa.BinOp); ok {
+		return fmt.Sprintf("%s %s %s", operandText(binary.X), binary.Op, operandText(binary.Y))
+	}
+	return operandText(branch.Cond)
+}
+
+func operandText(value ssa.Value) string {
+	switch value := value.(type) {
+	case *ssa.Parameter:
+		return value.Name()
+	case *ssa.Const:
+		if value.Value != nil {
+			return value.Value.ExactString()
+		}
+	}
+	return value.Name()
+}
+
+// AnalyseCoverage исследует функции верхнего уровня файла и возвращает
+// покрытие, которого достигают тесты, сгенерированные по их путям
+func AnalyseCoverage(sourceFile string, options ...Option) ([]CoverageReport, error) {
+	source, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), sourceFile, source, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []CoverageReport
+	for _, decl := range file.Decls {
+		function, ok := decl.(*ast.FuncDecl)
+		if !ok || function.Recv != nil || function.Type.TypeParams != nil || function.Body == nil || function.Name.Name == "main" {
+			continue
+		}
+		analyser := NewAnalyser(options...)
+		finals := analyser.Analyse(string(source), function.Name.Name)
+		fn := analyser.Package.Func(function.Name.Name)
+		if fn == nil {
+			return reports, fmt.Errorf("function %s not found in SSA", function.Name.Name)
+		}
+		reports = append(reports, analyser.Coverage(fn, finals))
+	}
+	return reports, nil
+}
+
+// WriteCoverage выводит покрытие блоков и ветвей и причины непокрытых ветвей
+func WriteCoverage(w io.Writer, reports []CoverageReport) {
+	for _, report := range reports {
+		fmt.Fprintf(w, "%s: blocks %d/%d, branches %d/%d\n", report.Function,
+			report.CoveredBlocks, report.Blocks, report.CoveredBranches, report.Branches)
+		for i, path := range report.Paths {
+			fmt.Fprintf(w, "  test %d: blocks %s, lines %s\n", i+1, joinInts(path.Blocks), joinInts(path.Lines))
+		}
+		for _, branch := range report.Unreached {
+			fmt.Fprintf(w, "  unreached %s\n", branch)
+		}
+		for _, branch := range report.Dead {
+			fmt.Fprintf(w, "  dead %s\n", branch)
+			for _, proof := range branch.Proof {
+				fmt.Fprintf(w, "    UNSAT: %s\n", proof)
+			}
+		}
+	}
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// BlockCounts возвращает число проходов каждого блока функции (по номеру
+// блока) на всех конечных путях — для наложения покрытия на граф потока управления
+func BlockCounts(function *ssa.Function, finals []Interpreter) map[int]int {
+	counts := make(map[int]int, len(function.Blocks))
+	for _, final := range finals {
+		for _, block := range final.Blocks {
+			if block.Parent() == function {
+				counts[block.Index]++
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+const coverageSource = `package main
+
+func classify(x int, flag bool) int {
+	if x > 10 {
+		return 1
+	}
+	if flag {
+		return 2
+	}
+	return 3
+}
+`
+
+// walk проводит состояние по блокам с номерами indices, отмечая переходы
+func walk(analyser *Analyser, function *ssa.Function, indices ...int) Interpreter {
+	interpreter := Interpreter{Analyser: analyser}
+	for _, index := range indices {
+		interpreter.enterBlock(function.Blocks[index])
+	}
+	return interpreter
+}
+
+// branchBlocks возвращает блок ветвления по x и блок ветвления по flag
+func branchBlocks(t *testing.T, function *ssa.Function) (*ssa.BasicBlock, *ssa.BasicBlock) {
+	t.Helper()
+	var branches []*ssa.BasicBlock
+	for _, block := range function.Blocks {
+		if _, ok := block.Instrs[len(block.Instrs)-1].(*ssa.If); ok {
+			branches = append(branches, block)
+		}
+	}
+	if len(branches) != 2 {
+		t.Fatalf("%d branch blocks, want 2", len(branches))
+	}
+	return branches[0], branches[1]
+}
+
+func TestCoverage(t *testing.T) {
+	function := buildFunction(t, coverageSource, "classify")
+	first, second := branchBlocks(t, function)
+	tests := []struct {
+		name    string
+		verdict solver.Result
+		prepare func(analyser *Analyser)
+		outcome BranchOutcome
+	}{
+		{"proven infeasible", solver.UNSAT, nil, BranchInfeasible},
+		{"unknown", solver.UNKNOWN, nil, BranchSolverUnknown},
+		{"budget", solver.UNSAT, func(analyser *Analyser) { analyser.BudgetExhausted = true }, BranchBudgetExhausted},
+		{"unsupported in another block", solver.UNSAT, func(analyser *Analyser) {
+			analyser.coverage().unsupported[function.Blocks[len(function.Blocks)-1]] = true
+		}, BranchUnsupported},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyser := newTestAnalyser(newFakeSolver(2))
+			// Путь x > 10 покрывает истинную ветвь первого ветвления
+			covered := walk(analyser, function, first.Index, first.Succs[0].Index)
+			// Ложная ветвь первого ветвления проверена солвером с вердиктом verdict
+			rejected := walk(analyser, function, first.Index, first.Succs[1].Index)
+			rejected.PathCondition = symbolic.NewBoolConstant(false)
+			analyser.recordVerdict(rejected, test.verdict)
+			if test.prepare != nil {
+				test.prepare(analyser)
+			}
+
+			report := analyser.Coverage(function, []Interpreter{covered})
+			if report.Blocks != len(function.Blocks) || report.CoveredBlocks != 2 {
+				t.Errorf("blocks %d/%d, want 2/%d", report.CoveredBlocks, report.Blocks, len(function.Blocks))
+			}
+			if report.Branches != 4 || report.CoveredBranches != 1 {
+				t.Errorf("branches %d/%d, want 1/4", report.CoveredBranches, report.Branches)
+			}
+			// Второе ветвление достигнуто по ложной ветви, но не исследовано
+			if len(report.Unreached) != 3 {
+				t.Fatalf("unreached = %v, want the false branch of x > 10 and both branches of flag", report.Unreached)
+			}
+			branch := report.Unreached[0]
+			if branch.Direction || branch.Condition != "x > 10" || branch.Location.Line != 4 || branch.Location.Column != 7 {
+				t.Errorf("unreached branch = %s", branch)
+			}
+			if branch.Outcome != test.outcome {
+				t.Errorf("outcome = %s, want %s", branch.Outcome, test.outcome)
+			}
+			if flag := report.Unreached[1]; flag.Condition != "flag" || flag.Location.Line != 7 {
+				t.Errorf("unreached branch = %s", flag)
+			}
+			if len(report.Paths) != 1 || !slices.Equal(report.Paths[0].Lines, []int{4, 5}) {
+				t.Errorf("path coverage = %+v", report.Paths)
+			}
+		})
+	}
+
+	t.Run("reached but not explored", func(t *testing.T) {
+		analyser := newTestAnalyser(newFakeSolver(2))
+		walk(analyser, function, first.Index, second.Index)
+		report := analyser.Coverage(function, nil)
+		outcomes := 0
+		for _, branch := range report.Unreached {
+			if branch.Condition == "flag" && branch.Outcome == BranchNotReached {
+				outcomes++
+			}
+		}
+		if outcomes != 2 {
+			t.Errorf("unreached = %v, want both branches of flag not reached", report.Unreached)
+		}
+	})
+}
+
+func TestLastEdge(t *testing.T) {
+	function := buildFunction(t, coverageSource, "classify")
+	first, _ := branchBlocks(t, function)
+	if _, ok := (&Interpreter{Blocks: []*ssa.BasicBlock{first}}).lastEdge(); ok {
+		t.Error("a single block has no edge")
+	}
+	transition, ok := (&Interpreter{Blocks: []*ssa.BasicBlock{first, first.Succs[1]}}).lastEdge()
+	if !ok || transition != (edge{first, first.Succs[1]}) {
+		t.Errorf("lastEdge = %v, %t", transition, ok)
+	}
+	// Возврат из вызова: блоки не связаны ребром
+	if _, ok := (&Interpreter{Blocks: []*ssa.BasicBlock{first.Succs[0], first}}).lastEdge(); ok {
+		t.Error("blocks without an edge between them were recorded")
+	}
+}
+
+func TestBlockCounts(t *testing.T) {
+	function := buildFunction(t, coverageSource, "classify")
+	first, second := branchBlocks(t, function)
+	other := buildFunction(t, coverageSource, "classify")
+	finals := []Interpreter{
+		{Blocks: []*ssa.BasicBlock{first, first.Succs[0]}},
+		{Blocks: []*ssa.BasicBlock{first, second, second.Succs[0]}},
+		// Блоки другой функции не учитываются
+		{Blocks: []*ssa.BasicBlock{other.Blocks[0]}},
+	}
+	counts := BlockCounts(function, finals)
+	want := map[int]int{first.Index: 2, first.Succs[0].Index: 1, second.Index: 1, second.Succs[0].Index: 1}
+	if len(counts) != len(want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+	for index, count := range want {
+		if counts[index] != count {
+			t.Errorf("block %d: %d passes, want %d", index, counts[index], count)
+		}
+	}
+}
+
+func TestWriteCoverage(t *testing.T) {
+	var output bytes.Buffer
+	WriteCoverage(&output, []CoverageReport{{
+		Function: "classify", Blocks: 5, CoveredBlocks: 3, Branches: 4, CoveredBranches: 2,
+		Paths: []PathCoverage{{Blocks: []int{0, 1}, Lines: []int{3, 4, 5}}},
+		Unreached: []BranchReport{{
+			Location: Location{File: "a.go", Line: 7}, Condition: "flag", Direction: true, Outcome: BranchSolverUnknown,
+		}},
+		Dead: []DeadBranch{{Location: Location{File: "a.go", Line: 4}, Condition: "x > 10", Proof: []string{"x > 10 && x < 0"}}},
+	}})
+	for _, want := range []string{
+		"classify: blocks 3/5, branches 2/4\n",
+		"  test 1: blocks 0 1, lines 3 4 5\n",
+		"  unreached a.go:7: if flag (true): solver returned UNKNOWN\n",
+		"    UNSAT: x > 10 && x < 0\n",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("output misses %q:\n%s", want, output.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// DeadBranch — ветвь, невыполнимая на всех исследованных путях, которые
+// доходят до ветвления
+type DeadBranch struct {
+	Location  Location `json:"location"`
+	Condition string   `json:"condition"`
+	Direction bool     `json:"direction"`
+	// Proof — невыполнимые условия путей, перешедших по ветви: конъюнкция
+	// условия пути до ветвления и условия ветви. Их невыполнимость
+	// доказывает, что ветвь мертва.
+	Proof []string `json:"proof"`
+	// Proven означает, что анализ исследовал все пути в пределах своих
+	// ограничений. Иначе ветвь невыполнима лишь на исследованных путях,
+	// а до остальных анализ не дошёл (бюджет, неподдерживаемые инструкции,
+	// UNKNOWN).
+	Proven bool `json:"proven"`
+}
+
+// String возвращает ветвь и вывод о ней
+func (branch DeadBranch) String() string {
+	verdict := "provably dead under analysed bounds"
+	if !branch.Proven {
+		verdict = "infeasible on explored paths only, analysis incomplete"
+	}
+	return fmt.Sprintf("%s: if %s (%t): %s", branch.Location, branch.Condition, branch.Direction, verdict)
+}
+
+// DeadBranches возвращает ветви условных переходов функции, для которых
+// солвер доказал невыполнимость на каждом пути, доходившем до ветвления.
+// Ветви, до которых не дошёл ни один путь, не считаются мёртвыми.
+func (analyser *Analyser) DeadBranches(function *ssa.Function) []DeadBranch {
+	log := analyser.coverage()
+	proven := analyser.exhaustive()
+
+	var dead []DeadBranch
+	for _, block := range function.Blocks {
+		branch, ok := block.Instrs[len(block.Instrs)-1].(*ssa.If)
+		if !ok {
+			continue
+		}
+		for i, successor := range block.Succs {
+			verdicts := log.verdicts[edge{block, successor}]
+			if verdicts == nil || verdicts.sat > 0 || verdicts.unknown > 0 || verdicts.unsat == 0 {
+				continue
+			}
+			location := branchLocation(branch)
+			deadBranch := DeadBranch{
+				Location:  location,
+				Condition: conditionText(branch),
+				Direction: i == 0,
+				Proven:    proven,
+			}
+			for _, condition := range verdicts.infeasible {
+				deadBranch.Proof = append(deadBranch.Proof, condition.String())
+			}
+			dead = append(dead, deadBranch)
+		}
+	}
+	return dead
+}
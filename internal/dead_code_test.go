@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestDeadBranches(t *testing.T) {
+	function := buildFunction(t, coverageSource, "classify")
+	first, second := branchBlocks(t, function)
+	// verdict — проверка состояния, перешедшего из блока branch в его
+	// преемника successor, с условием пути condition
+	type verdict struct {
+		successor int
+		result    solver.Result
+		condition string
+	}
+	tests := []struct {
+		name     string
+		verdicts []verdict
+		prepare  func(analyser *Analyser)
+		dead     bool
+		proven   bool
+		proof    []string
+	}{
+		{"unsat on every path", []verdict{{1, solver.UNSAT, "a"}, {1, solver.UNSAT, "b"}}, nil, true, true, []string{"a", "b"}},
+		{"sat on one path", []verdict{{1, solver.UNSAT, "a"}, {1, solver.SAT, "b"}}, nil, false, false, nil},
+		{"unknown on one path", []verdict{{1, solver.UNSAT, "a"}, {1, solver.UNKNOWN, "b"}}, nil, false, false, nil},
+		{"other successor", []verdict{{0, solver.SAT, "a"}}, nil, false, false, nil},
+		{"budget exhausted", []verdict{{1, solver.UNSAT, "a"}},
+			func(analyser *Analyser) { analyser.BudgetExhausted = true }, true, false, []string{"a"}},
+		{"path cut by an unsupported instruction", []verdict{{1, solver.UNSAT, "a"}},
+			func(analyser *Analyser) { analyser.coverage().unsupported[second] = true }, true, false, []string{"a"}},
+		{"unknown dropped elsewhere", []verdict{{1, solver.UNSAT, "a"}},
+			func(analyser *Analyser) {
+				state := walk(analyser, function, first.Index, second.Index, second.Succs[0].Index)
+				analyser.recordVerdict(state, solver.UNKNOWN)
+			}, true, false, []string{"a"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyser := newTestAnalyser(newFakeSolver(2))
+			for _, v := range test.verdicts {
+				state := walk(analyser, function, first.Index, first.Succs[v.successor].Index)
+				state.PathCondition = symbolic.NewSymbolicVariable(v.condition, symbolic.BoolType)
+				analyser.recordVerdict(state, v.result)
+			}
+			if test.prepare != nil {
+				test.prepare(analyser)
+			}
+
+			dead := analyser.DeadBranches(function)
+			if !test.dead {
+				if len(dead) != 0 {
+					t.Errorf("dead = %v, want none", dead)
+				}
+				return
+			}
+			if len(dead) != 1 {
+				t.Fatalf("dead = %v, want the false branch of x > 10", dead)
+			}
+			branch := dead[0]
+			if branch.Condition != "x > 10" || branch.Direction || branch.Location.Line != 4 {
+				t.Errorf("dead branch = %s", branch)
+			}
+			if branch.Proven != test.proven {
+				t.Errorf("Proven = %t, want %t", branch.Proven, test.proven)
+			}
+			if !slices.Equal(branch.Proof, test.proof) {
+				t.Errorf("proof = %v, want %v", branch.Proof, test.proof)
+			}
+		})
+	}
+}
+
+func TestDeadBranchesKeepUnknown(t *testing.T) {
+	function := buildFunction(t, coverageSource, "classify")
+	first, second := branchBlocks(t, function)
+	analyser := newTestAnalyser(newFakeSolver(2), WithUnknownPolicy(KeepUnknown))
+	state := walk(analyser, function, first.Index, first.Succs[1].Index)
+	state.PathCondition = symbolic.NewBoolConstant(false)
+	analyser.recordVerdict(state, solver.UNSAT)
+	// Состояние с UNKNOWN на другом ребре не отброшено, анализ остаётся полным
+	analyser.recordVerdict(walk(analyser, function, first.Index, second.Index, second.Succs[0].Index), solver.UNKNOWN)
+
+	dead := analyser.DeadBranches(function)
+	if len(dead) != 1 || !dead[0].Proven {
+		t.Errorf("dead = %v, want one proven dead branch", dead)
+	}
+}
+
+func TestDeadBranchString(t *testing.T) {
+	branch := DeadBranch{Location: Location{File: "a.go", Line: 4, Column: 7}, Condition: "x > 10", Proven: true}
+	if got, want := branch.String(), "a.go:4:7: if x > 10 (false): provably dead under analysed bounds"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	branch.Proven = false
+	if got, want := branch.String(), "a.go:4:7: if x > 10 (false): infeasible on explored paths only, analysis incomplete"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"golang.org/x/tools/go/ssa"
 	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/solver"
 	"symbolic-execution-course/internal/symbolic"
 )
 
@@ -11,17 +12,67 @@ type Interpreter struct {
 	Analyser      *Analyser
 	PathCondition symbolic.SymbolicExpression
 	Heap          memory.Memory
+
+	// Model — последнее найденное солвером присваивание входных переменных
+	Model solver.Model
+	// Incomplete означает, что выполнимость пути не доказана или путь
+	// получен конкретизацией, то есть результат является недоаппроксимацией
+	Incomplete bool
+
+	// ConcreteInputs — конкретные значения входов в конколическом режиме
+	ConcreteInputs solver.Model
+	// Branches — условия пройденных ветвлений в порядке исполнения
+	Branches []symbolic.SymbolicExpression
+	// Assumptions — индексы в Branches допущений spec.Assume и предусловий,
+	// которые expandExecution не инвертирует
+	Assumptions []int
+	// Concretizations — замены символьных значений конкретными на этом пути
+	Concretizations []Concretization
+
+	// Scheduler — горутины и каналы (nil, пока программа однопоточна)
+	Scheduler *Scheduler
+	// Findings — ошибки, найденные на пути
+	Findings []Finding
+	// Terminated означает аварийное завершение программы (паника, взаимоблокировка);
+	// такое состояние не исполняется дальше и сразу попадает в Results
+	Terminated bool
+	// Executed — исполненные инструкции с позициями в исходном коде
+	// (по одной на строку подряд), из них строится путь в отчётах об ошибках
+	Executed []ssa.Instruction
+	// Blocks — пройденные базовые блоки (включая блоки вызываемых функций)
+	Blocks []*ssa.BasicBlock
+	// Node — номер узла состояния в дереве исполнения (0 — дерево не записывается)
+	Node int
+	// instruction — инструкция, отмеченная последним step; по ней модели
+	// вызовов и отчёты об ошибках получают исполняемую инструкцию
+	instruction ssa.Instruction
+	// joinPoint — блок с несколькими предшественниками, в начале которого
+	// (после φ-узлов) стоит состояние и может быть объединено с другими
+	joinPoint *ssa.BasicBlock
 }
 
 type CallStackFrame struct {
 	Function    *ssa.Function
 	LocalMemory map[string]symbolic.SymbolicExpression
 	ReturnValue symbolic.SymbolicExpression
+	// ReturnValues — значения результатов функции с несколькими результатами
+	ReturnValues []symbolic.SymbolicExpression
+}
+
+// addConstraint добавляет ограничение к условию пути
+func (interpreter *Interpreter) addConstraint(constraint symbolic.SymbolicExpression) {
+	if interpreter.PathCondition == nil {
+		interpreter.PathCondition = constraint
+		return
+	}
+	interpreter.PathCondition = symbolic.NewLogicalOperation(
+		[]symbolic.SymbolicExpression{interpreter.PathCondition, constraint}, symbolic.AND)
 }
 
 func (interpreter *Interpreter) interpretDynamically(element ssa.Instruction) []Interpreter {
 	switch element.(type) {
 	// TODO implement me
+	// Хуки возможностей анализатора описаны в комментариях их файлов.
 	}
 	panic("implement me")
 }
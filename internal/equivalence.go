@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"fmt"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// Outcome — результат исполнения функции на входах расхождения
+type Outcome struct {
+	// Results — возвращённые значения (nil при панике)
+	Results  []symbolic.SymbolicExpression
+	Panicked bool
+	// Kind и Message описывают аварийное завершение
+	Kind    FindingKind
+	Message string
+}
+
+// String возвращает строковое представление результата
+func (outcome Outcome) String() string {
+	if outcome.Panicked {
+		return "panic: " + outcome.Message
+	}
+	if len(outcome.Results) == 0 {
+		return "<no result>"
+	}
+	values := make([]string, len(outcome.Results))
+	for i, result := range outcome.Results {
+		values[i] = result.String()
+	}
+	return strings.Join(values, ", ")
+}
+
+// Divergence — входные данные, на которых две версии функции ведут себя по-разному
+type Divergence struct {
+	Inputs solver.Model
+	A, B   Outcome
+	// Test — тестовая функция Go, воспроизводящая расхождение
+	Test string
+}
+
+// AnalyseEquivalence проверяет эквивалентность двух функций с одинаковой
+// сигнатурой: обе исполняются на общих символьных входах (параметры
+// сопоставляются по позиции), и для каждой пары путей солвер ищет входы,
+// на которых различаются результаты или версии завершаются аварийно
+// по-разному (паникует лишь одна из них или с другим сообщением).
+func AnalyseEquivalence(source string, functionA string, functionB string, options ...Option) ([]Divergence, error) {
+	analyserA := NewAnalyser(options...)
+	analyserA.opaquePrefix = "a."
+	resultsA := analyserA.Analyse(source, functionA)
+	analyserB := NewAnalyser(options...)
+	analyserB.opaquePrefix = "b."
+	resultsB := analyserB.Analyse(source, functionB)
+
+	fnA, fnB := analyserA.Package.Func(functionA), analyserB.Package.Func(functionB)
+	if fnA == nil || fnB == nil {
+		return nil, fmt.Errorf("functions %s and %s not found", functionA, functionB)
+	}
+	return analyserA.compareFunctions(fnA, fnB, resultsA, resultsB)
+}
+
+// compareFunctions ищет расхождения между конечными состояниями двух функций
+func (analyser *Analyser) compareFunctions(fnA, fnB *ssa.Function, resultsA, resultsB []Interpreter) ([]Divergence, error) {
+	if !types.Identical(fnA.Signature, fnB.Signature) {
+		return nil, fmt.Errorf("signatures of %s and %s differ: %s vs %s", fnA.Name(), fnB.Name(), fnA.Signature, fnB.Signature)
+	}
+	renaming, err := parameterRenaming(fnB, fnA)
+	if err != nil {
+		return nil, err
+	}
+
+	var divergences []Divergence
+	seen := make(map[string]bool)
+	for _, a := range resultsA {
+		for _, b := range resultsB {
+			divergence, found, err := analyser.diverge(a, b, renaming)
+			if err != nil {
+				return divergences, err
+			}
+			if !found || seen[modelKey(divergence.Inputs)] {
+				continue
+			}
+			seen[modelKey(divergence.Inputs)] = true
+			divergence.Test, err = equivalenceTest(fnA, fnB, divergence.Inputs, len(divergences)+1)
+			if err != nil {
+				return divergences, err
+			}
+			divergences = append(divergences, divergence)
+		}
+	}
+	return divergences, nil
+}
+
+// diverge ищет входы, на которых путь a первой функции и путь b второй
+// выполнимы одновременно и дают разные результаты
+func (analyser *Analyser) diverge(a, b Interpreter, renaming map[string]symbolic.SymbolicExpression) (Divergence, bool, error) {
+	panicA, panicB := panicked(a), panicked(b)
+	if panicA && panicB {
+		kindA, messageA := termination(a)
+		kindB, messageB := termination(b)
+		if kindA == kindB && messageA == messageB {
+			return Divergence{}, false, nil
+		}
+	}
+
+	var constraints []symbolic.SymbolicExpression
+	if a.PathCondition != nil {
+		constraints = append(constraints, a.PathCondition)
+	}
+	if b.PathCondition != nil {
+		constraints = append(constraints, symbolic.Substitute(b.PathCondition, renaming))
+	}
+	resultsA, resultsB := returnValues(a), returnValues(b)
+	for i, result := range resultsB {
+		resultsB[i] = symbolic.Substitute(result, renaming)
+	}
+	if !panicA && !panicB {
+		if len(resultsA) == 0 || len(resultsA) != len(resultsB) {
+			return Divergence{}, false, nil
+		}
+		// Расхождение хотя бы в одном из результатов
+		differences := make([]symbolic.SymbolicExpression, len(resultsA))
+		for i := range resultsA {
+			differences[i] = symbolic.NewBinaryOperation(resultsA[i], resultsB[i], symbolic.NE)
+		}
+		if len(differences) == 1 {
+			constraints = append(constraints, differences[0])
+		} else {
+			constraints = append(constraints, symbolic.NewLogicalOperation(differences, symbolic.OR))
+		}
+	}
+	if len(constraints) == 0 {
+		constraints = append(constraints, symbolic.NewBoolConstant(true))
+	}
+
+	result, model, err := analyser.checkSat(symbolic.NewLogicalOperation(constraints, symbolic.AND))
+	if err != nil || result != solver.SAT {
+		return Divergence{}, false, err
+	}
+	outcomeA, err := outcome(a, resultsA, model)
+	if err != nil {
+		return Divergence{}, false, err
+	}
+	outcomeB, err := outcome(b, resultsB, model)
+	if err != nil {
+		return Divergence{}, false, err
+	}
+	return Divergence{Inputs: model, A: outcomeA, B: outcomeB}, true, nil
+}
+
+// parameterRenaming сопоставляет параметрам from переменные одноимённых
+// по позиции параметров to
+func parameterRenaming(from, to *ssa.Function) (map[string]symbolic.SymbolicExpression, error) {
+	renaming := make(map[string]symbolic.SymbolicExpression, len(from.Params))
+	for i, param := range from.Params {
+		exprType, ok := expressionType(param.Type())
+		if !ok {
+			return nil, fmt.Errorf("unsupported parameter type %s", param.Type())
+		}
+		renaming[param.Name()] = symbolic.NewSymbolicVariable(to.Params[i].Name(), exprType)
+	}
+	return renaming, nil
+}
+
+// returnValues возвращает копию результатов функции в конечном состоянии
+func returnValues(final Interpreter) []symbolic.SymbolicExpression {
+	if len(final.CallStack) == 0 {
+		return nil
+	}
+	frame := final.CallStack[0]
+	if frame.ReturnValues != nil {
+		return slices.Clone(frame.ReturnValues)
+	}
+	if frame.ReturnValue == nil {
+		return nil
+	}
+	return []symbolic.SymbolicExpression{frame.ReturnValue}
+}
+
+// panicked проверяет, завершилось ли состояние паникой
+func panicked(final Interpreter) bool {
+	return final.Terminated
+}
+
+// termination возвращает вид и сообщение ошибки, завершившей состояние
+func termination(final Interpreter) (FindingKind, string) {
+	if len(final.Findings) == 0 {
+		return GoroutinePanic, "terminated"
+	}
+	last := final.Findings[len(final.Findings)-1]
+	return last.Kind, last.Message
+}
+
+// outcome вычисляет результат пути на конкретных входах
+func outcome(final Interpreter, results []symbolic.SymbolicExpression, model solver.Model) (Outcome, error) {
+	if panicked(final) {
+		kind, message := termination(final)
+		return Outcome{Panicked: true, Kind: kind, Message: message}, nil
+	}
+	values := make([]symbolic.SymbolicExpression, len(results))
+	for i, result := range results {
+		value, err := symbolic.Evaluate(result, model)
+		if err != nil {
+			return Outcome{}, err
+		}
+		values[i] = value
+	}
+	return Outcome{Results: values}, nil
+}
+
+// equivalenceTest строит тестовую функцию, вызывающую обе версии на
+// входах расхождения и сравнивающую все их результаты. Паника любой из
+// версий также проваливает тест. Аргументы форматируются testArguments.
+func equivalenceTest(fnA, fnB *ssa.Function, inputs solver.Model, index int) (string, error) {
+	args, err := testArguments(fnA, inputs)
+	if err != nil {
+		return "", err
+	}
+	call := strings.Join(args, ", ")
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "func TestEquivalence_%s_%s_%d(t *testing.T) {\n", fnA.Name(), fnB.Name(), index)
+	count := fnA.Signature.Results().Len()
+	if count == 0 {
+		fmt.Fprintf(&builder, "\t%s(%s)\n\t%s(%s)\n", fnA.Name(), call, fnB.Name(), call)
+		builder.WriteString("}\n")
+		return builder.String(), nil
+	}
+
+	resultsA, resultsB := make([]string, count), make([]string, count)
+	differences := make([]string, count)
+	for i := range count {
+		resultsA[i], resultsB[i] = fmt.Sprintf("a%d", i), fmt.Sprintf("b%d", i)
+		differences[i] = resultsA[i] + " != " + resultsB[i]
+	}
+	listA, listB := strings.Join(resultsA, ", "), strings.Join(resultsB, ", ")
+	verbs := strings.TrimSuffix(strings.Repeat("%v, ", count), ", ")
+	fmt.Fprintf(&builder, "\t%s := %s(%s)\n", listA, fnA.Name(), call)
+	fmt.Fprintf(&builder, "\t%s := %s(%s)\n", listB, fnB.Name(), call)
+	fmt.Fprintf(&builder, "\tif %s {\n", strings.Join(differences, " || "))
+	fmt.Fprintf(&builder, "\t\tt.Errorf(\"%s(%s) = %s; %s(%s) = %s\", %s, %s)\n",
+		fnA.Name(), call, verbs, fnB.Name(), call, verbs, listA, listB)
+	builder.WriteString("\t}\n}\n")
+	return builder.String(), nil
+}
+
+// EquivalenceTestFile собирает тесты расхождений в файл пакета packageName
+func EquivalenceTestFile(packageName string, divergences []Divergence) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "package %s\n\nimport \"testing\"\n", packageName)
+	for _, divergence := range divergences {
+		builder.WriteString("\n" + divergence.Test)
+	}
+	return builder.String()
+}
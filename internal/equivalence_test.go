@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+const equivalenceSource = `package main
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func absByComparison(y int) int {
+	if y >= 0 {
+		return y
+	}
+	return 0 - y
+}
+
+func divmod(a, b int) (int, int) { return a / b, a % b }
+
+func divmod2(c, d int) (int, int) { return c / d, c % d }
+
+func touch(x int) {}
+
+func narrow(b int8, u uint16) int8 { return b }
+
+func narrow2(c int8, v uint16) int8 { return c }
+
+func other(x bool) int { return 0 }
+`
+
+// returning строит конечное состояние пути с условием path и результатами results
+func returning(path symbolic.SymbolicExpression, results ...symbolic.SymbolicExpression) Interpreter {
+	frame := CallStackFrame{}
+	if len(results) == 1 {
+		frame.ReturnValue = results[0]
+	} else {
+		frame.ReturnValues = results
+	}
+	return Interpreter{PathCondition: path, CallStack: []CallStackFrame{frame}}
+}
+
+// panicking строит состояние, завершённое ошибкой kind
+func panicking(path symbolic.SymbolicExpression, kind FindingKind, message string) Interpreter {
+	return Interpreter{
+		PathCondition: path,
+		CallStack:     []CallStackFrame{{}},
+		Terminated:    true,
+		Findings:      []Finding{{Kind: kind, Message: message}},
+	}
+}
+
+func TestCompareFunctions(t *testing.T) {
+	requireExpressions(t)
+	abs := buildFunction(t, equivalenceSource, "abs")
+	alternative := buildFunction(t, equivalenceSource, "absByComparison")
+	x, y := intVar("x"), intVar("y")
+	negative := func(v *symbolic.SymbolicVariable) symbolic.SymbolicExpression {
+		return compare(v, symbolic.LT, intConst(0))
+	}
+	nonNegative := func(v *symbolic.SymbolicVariable) symbolic.SymbolicExpression {
+		return compare(v, symbolic.GE, intConst(0))
+	}
+	negate := func(v *symbolic.SymbolicVariable) symbolic.SymbolicExpression {
+		return compare(intConst(0), symbolic.SUB, v)
+	}
+	tests := []struct {
+		name     string
+		a, b     []Interpreter
+		diverges bool
+		check    func(t *testing.T, divergence Divergence)
+	}{
+		{"equivalent",
+			[]Interpreter{returning(negative(x), negate(x)), returning(nonNegative(x), x)},
+			[]Interpreter{returning(nonNegative(y), y), returning(negative(y), negate(y))},
+			false, nil},
+		{"different result",
+			[]Interpreter{returning(negative(x), negate(x)), returning(nonNegative(x), x)},
+			[]Interpreter{returning(nil, y)},
+			true, func(t *testing.T, divergence Divergence) {
+				if value := divergence.Inputs["x"].(*symbolic.IntConstant).Value; value >= 0 {
+					t.Errorf("divergence at x = %d, want a negative input", value)
+				}
+			}},
+		{"only one version panics",
+			[]Interpreter{returning(nil, x)},
+			[]Interpreter{panicking(negative(y), GoroutinePanic, "boom")},
+			true, func(t *testing.T, divergence Divergence) {
+				if divergence.A.Panicked || !divergence.B.Panicked || divergence.B.Message != "boom" {
+					t.Errorf("outcomes = %s, %s", divergence.A, divergence.B)
+				}
+			}},
+		{"same panic",
+			[]Interpreter{panicking(nil, GoroutinePanic, "boom")},
+			[]Interpreter{panicking(nil, GoroutinePanic, "boom")},
+			false, nil},
+		{"different panic message",
+			[]Interpreter{panicking(nil, GoroutinePanic, "boom")},
+			[]Interpreter{panicking(nil, GoroutinePanic, "bang")},
+			true, nil},
+		{"different termination kind",
+			[]Interpreter{panicking(nil, GoroutinePanic, "boom")},
+			[]Interpreter{panicking(nil, AssertionViolation, "boom")},
+			true, func(t *testing.T, divergence Divergence) {
+				if divergence.A.Kind != GoroutinePanic || divergence.B.Kind != AssertionViolation {
+					t.Errorf("kinds = %s, %s", divergence.A.Kind, divergence.B.Kind)
+				}
+			}},
+		{"disjoint paths",
+			[]Interpreter{returning(negative(x), intConst(1))},
+			[]Interpreter{returning(nonNegative(y), intConst(2))},
+			false, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyser := newTestAnalyser(newFakeSolver(3))
+			divergences, err := analyser.compareFunctions(abs, alternative, test.a, test.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (len(divergences) > 0) != test.diverges {
+				t.Fatalf("found %d divergences, want diverges = %t", len(divergences), test.diverges)
+			}
+			if len(divergences) > 0 && test.check != nil {
+				test.check(t, divergences[0])
+			}
+		})
+	}
+}
+
+func TestCompareFunctionsMultipleResults(t *testing.T) {
+	requireExpressions(t)
+	divmod := buildFunction(t, equivalenceSource, "divmod")
+	divmod2 := buildFunction(t, equivalenceSource, "divmod2")
+	a, c := intVar("a"), intVar("c")
+
+	// Первые результаты совпадают, второй различается
+	analyser := newTestAnalyser(newFakeSolver(2))
+	divergences, err := analyser.compareFunctions(divmod, divmod2,
+		[]Interpreter{returning(nil, a, intConst(0))},
+		[]Interpreter{returning(nil, c, intConst(1))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(divergences) != 1 {
+		t.Fatalf("found %d divergences, want the second result to differ", len(divergences))
+	}
+	if got := divergences[0].A.String(); !strings.HasSuffix(got, ", 0") {
+		t.Errorf("outcome A = %s, want both results", got)
+	}
+
+	divergences, err = analyser.compareFunctions(divmod, divmod2,
+		[]Interpreter{returning(nil, a, intConst(0))},
+		[]Interpreter{returning(nil, c, intConst(0))})
+	if err != nil || len(divergences) != 0 {
+		t.Errorf("equal results: %d divergences, %v", len(divergences), err)
+	}
+}
+
+func TestCompareFunctionsRejectsSignatures(t *testing.T) {
+	abs := buildFunction(t, equivalenceSource, "abs")
+	other := buildFunction(t, equivalenceSource, "other")
+	analyser := newTestAnalyser(newFakeSolver(2))
+	if _, err := analyser.compareFunctions(abs, other, nil, nil); err == nil || !strings.Contains(err.Error(), "signatures") {
+		t.Errorf("compareFunctions error = %v, want a signature mismatch", err)
+	}
+}
+
+func TestEquivalenceTest(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		inputs solver.Model
+		want   []string
+	}{
+		{"abs", "absByComparison", solver.Model{"x": intConst(-3)}, []string{
+			"func TestEquivalence_abs_absByComparison_1(t *testing.T) {",
+			"\ta0 := abs(-3)",
+			"\tb0 := absByComparison(-3)",
+			"\tif a0 != b0 {",
+			"\t\tt.Errorf(\"abs(-3) = %v; absByComparison(-3) = %v\", a0, b0)",
+		}},
+		{"divmod", "divmod2", solver.Model{"a": intConst(7)}, []string{
+			"\ta0, a1 := divmod(7, 0)",
+			"\tb0, b1 := divmod2(7, 0)",
+			"\tif a0 != b0 || a1 != b1 {",
+			"\t\tt.Errorf(\"divmod(7, 0) = %v, %v; divmod2(7, 0) = %v, %v\", a0, a1, b0, b1)",
+		}},
+		{"touch", "touch", solver.Model{}, []string{
+			"\ttouch(0)\n\ttouch(0)\n}",
+		}},
+		// Модель солвера не ограничена разрядностью параметров
+		{"narrow", "narrow2", solver.Model{"b": intConst(200), "u": intConst(-1)}, []string{
+			"\ta0 := narrow(-56, 65535)",
+			"\tb0 := narrow2(-56, 65535)",
+		}},
+	}
+	for _, test := range tests {
+		fnA, fnB := buildFunction(t, equivalenceSource, test.a), buildFunction(t, equivalenceSource, test.b)
+		got, err := equivalenceTest(fnA, fnB, test.inputs, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range test.want {
+			if !strings.Contains(got, line) {
+				t.Errorf("test for %s misses %q:\n%s", test.a, line, got)
+			}
+		}
+	}
+}
+
+func TestFreshValuesArePrefixed(t *testing.T) {
+	first := Interpreter{Analyser: newTestAnalyser(newFakeSolver(2))}
+	first.Analyser.opaquePrefix = "a."
+	second := Interpreter{Analyser: newTestAnalyser(newFakeSolver(2))}
+	second.Analyser.opaquePrefix = "b."
+	if a, b := first.freshValue("opaque", symbolic.IntType), second.freshValue("opaque", symbolic.IntType); a.Name == b.Name {
+		t.Errorf("analysers share the fresh variable %s", a.Name)
+	}
+}
+
+func TestOutcomeString(t *testing.T) {
+	tests := []struct {
+		outcome Outcome
+		want    string
+	}{
+		{Outcome{Results: []symbolic.SymbolicExpression{intConst(1), symbolic.NewBoolConstant(true)}}, "1, true"},
+		{Outcome{Panicked: true, Message: "boom"}, "panic: boom"},
+		{Outcome{}, "<no result>"},
+	}
+	for _, test := range tests {
+		if got := test.outcome.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
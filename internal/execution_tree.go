@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+)
+
+// WithExecutionTree включает запись дерева исполнения: каждое состояние,
+// созданное через fork, становится дочерним узлом состояния-родителя.
+// Поэтому interpretDynamically создаёт обе ветви *ssa.If через fork, иначе
+// они не получат собственных узлов.
+func WithExecutionTree() Option {
+	return func(analyser *Analyser) {
+		analyser.Tree = &ExecutionTree{}
+	}
+}
+
+// TreeNode — состояние в дереве исполнения от его создания до следующего
+// ветвления или завершения
+type TreeNode struct {
+	ID int `json:"id"`
+	// Parent — номер родительского узла (0 у корня)
+	Parent   int    `json:"parent"`
+	Function string `json:"function,omitempty"`
+	// Block — номер первого блока SSA, в который перешло состояние (-1, если неизвестен)
+	Block int `json:"block"`
+	// Condition — условие ветви, по которой состояние отделилось от родителя
+	Condition string `json:"condition,omitempty"`
+	// Verdict — результат проверки выполнимости состояния солвером
+	Verdict string `json:"verdict,omitempty"`
+	// Result — итог пути для конечных состояний
+	Result     string `json:"result,omitempty"`
+	Terminal   bool   `json:"terminal,omitempty"`
+	Incomplete bool   `json:"incomplete,omitempty"`
+	// MergedInto — номер узла состояния, с которым объединено это (0 — не объединено)
+	MergedInto int `json:"merged_into,omitempty"`
+}
+
+// ExecutionTree — дерево ветвлений состояний при анализе функции
+type ExecutionTree struct {
+	Nodes []*TreeNode `json:"nodes"`
+}
+
+// add добавляет узел и возвращает его номер
+func (tree *ExecutionTree) add(parent int) int {
+	node := &TreeNode{ID: len(tree.Nodes) + 1, Parent: parent, Block: -1}
+	tree.Nodes = append(tree.Nodes, node)
+	return node.ID
+}
+
+// node возвращает узел по номеру (nil для 0)
+func (tree *ExecutionTree) node(id int) *TreeNode {
+	if id <= 0 || id > len(tree.Nodes) {
+		return nil
+	}
+	return tree.Nodes[id-1]
+}
+
+// branchNode выделяет копии состояния собственный узел дерева
+func (interpreter *Interpreter) branchNode(forked *Interpreter) {
+	if interpreter.Analyser == nil || interpreter.Analyser.Tree == nil {
+		return
+	}
+	tree := interpreter.Analyser.Tree
+	if interpreter.Node == 0 {
+		interpreter.Node = tree.add(0)
+		interpreter.nodeBlock()
+	}
+	forked.Node = tree.add(interpreter.Node)
+}
+
+// nodeBlock запоминает в узле состояния блок, в котором оно находится
+func (interpreter *Interpreter) nodeBlock() {
+	if interpreter.Analyser == nil || interpreter.Analyser.Tree == nil || len(interpreter.Blocks) == 0 {
+		return
+	}
+	node := interpreter.Analyser.Tree.node(interpreter.Node)
+	if node == nil || node.Block >= 0 {
+		return
+	}
+	block := interpreter.Blocks[len(interpreter.Blocks)-1]
+	node.Function = block.Parent().Name()
+	node.Block = block.Index
+}
+
+// recordNodeVerdict запоминает в узле состояния результат проверки его
+// выполнимости и условие ветви, по которой оно пришло
+func (analyser *Analyser) recordNodeVerdict(interpreter Interpreter, result solver.Result) {
+	if analyser.Tree == nil {
+		return
+	}
+	node := analyser.Tree.node(interpreter.Node)
+	if node == nil {
+		return
+	}
+	node.Verdict = result.String()
+	if transition, ok := interpreter.lastEdge(); ok {
+		if branch, ok := transition.from.Instrs[len(transition.from.Instrs)-1].(*ssa.If); ok {
+			node.Condition = fmt.Sprintf("%s (%t)", conditionText(branch), transition.from.Succs[0] == transition.to)
+		}
+	}
+}
+
+// ExecutionTree возвращает записанное дерево исполнения, отмечая в нём
+// конечные состояния из analyser.Results (nil, если запись не включена)
+func (analyser *Analyser) ExecutionTree() *ExecutionTree {
+	tree := analyser.Tree
+	if tree == nil {
+		return nil
+	}
+	for _, final := range analyser.Results {
+		node := tree.node(final.Node)
+		if node == nil {
+			if len(tree.Nodes) > 0 {
+				continue
+			}
+			// Путь без ветвлений: дерево из одного узла
+			final.Node = tree.add(0)
+			final.nodeBlock()
+			node = tree.node(final.Node)
+		}
+		node.Terminal = true
+		node.Incomplete = final.Incomplete
+		node.Result = terminalResult(final)
+	}
+	return tree
+}
+
+// terminalResult описывает итог конечного состояния
+func terminalResult(final Interpreter) string {
+	if final.Terminated {
+		if len(final.Findings) > 0 {
+			finding := final.Findings[len(final.Findings)-1]
+			return fmt.Sprintf("%s: %s", finding.Kind, finding.Message)
+		}
+		return "terminated"
+	}
+	if results := returnValues(final); results != nil {
+		return "return " + Outcome{Results: results}.String()
+	}
+	return "return"
+}
+
+// DOT возвращает дерево в формате Graphviz. Невыполнимые состояния
+// выделяются красным, с неизвестной выполнимостью — оранжевым, конечные —
+// двойной рамкой, слияния состояний — пунктирными рёбрами.
+func (tree *ExecutionTree) DOT(name string) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "digraph %s {\n", strconv.Quote(name))
+	builder.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	for _, node := range tree.Nodes {
+		var label []string
+		if node.Block >= 0 {
+			label = append(label, fmt.Sprintf("%s: block %d", node.Function, node.Block))
+		} else {
+			label = append(label, fmt.Sprintf("state %d", node.ID))
+		}
+		if node.Verdict != "" {
+			label = append(label, node.Verdict)
+		}
+		if node.Terminal {
+			result := node.Result
+			if node.Incomplete {
+				result += " (incomplete)"
+			}
+			label = append(label, result)
+		}
+
+		attributes := []string{"label=" + strconv.Quote(strings.Join(label, "\n"))}
+		switch {
+		case node.Verdict == solver.UNSAT.String():
+			attributes = append(attributes, "color=red", "style=dashed")
+		case node.Verdict == solver.UNKNOWN.String():
+			attributes = append(attributes, "color=orange")
+		}
+		if node.Terminal {
+			attributes = append(attributes, "peripheries=2")
+		}
+		fmt.Fprintf(&builder, "\tn%d [%s];\n", node.ID, strings.Join(attributes, ", "))
+		if node.Parent > 0 {
+			fmt.Fprintf(&builder, "\tn%d -> n%d [label=%s];\n", node.Parent, node.ID, strconv.Quote(node.Condition))
+		}
+		if node.MergedInto > 0 {
+			fmt.Fprintf(&builder, "\tn%d -> n%d [style=dotted, label=\"merged\"];\n", node.ID, node.MergedInto)
+		}
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+)
+
+func TestExecutionTreeRecordsForks(t *testing.T) {
+	function := buildFunction(t, coverageSource, "classify")
+	first, _ := branchBlocks(t, function)
+	analyser := newTestAnalyser(newFakeSolver(2), WithExecutionTree())
+
+	root := walk(analyser, function, first.Index)
+	then, otherwise := root.fork(), root.fork()
+	then.enterBlock(first.Succs[0])
+	otherwise.enterBlock(first.Succs[1])
+	analyser.recordNodeVerdict(then, solver.SAT)
+	analyser.recordNodeVerdict(otherwise, solver.UNSAT)
+
+	tree := analyser.Tree
+	if len(tree.Nodes) != 3 {
+		t.Fatalf("%d nodes, want the root and two branches", len(tree.Nodes))
+	}
+	rootNode := tree.node(root.Node)
+	if rootNode.Parent != 0 || rootNode.Function != "classify" || rootNode.Block != first.Index {
+		t.Errorf("root = %+v", rootNode)
+	}
+	tests := []struct {
+		state     Interpreter
+		block     int
+		condition string
+		verdict   string
+	}{
+		{then, first.Succs[0].Index, "x > 10 (true)", solver.SAT.String()},
+		{otherwise, first.Succs[1].Index, "x > 10 (false)", solver.UNSAT.String()},
+	}
+	for _, test := range tests {
+		node := tree.node(test.state.Node)
+		if node == nil || node.Parent != root.Node || node.Block != test.block {
+			t.Fatalf("branch node = %+v", node)
+		}
+		if node.Condition != test.condition || node.Verdict != test.verdict {
+			t.Errorf("node %d: condition %q, verdict %q; want %q, %q", node.ID, node.Condition, node.Verdict, test.condition, test.verdict)
+		}
+	}
+
+	analyser.Results = []Interpreter{then}
+	analyser.ExecutionTree()
+	if node := tree.node(then.Node); !node.Terminal || node.Result != "return" {
+		t.Errorf("terminal node = %+v", node)
+	}
+	if node := tree.node(otherwise.Node); node.Terminal {
+		t.Errorf("infeasible node %d is marked terminal", node.ID)
+	}
+}
+
+func TestExecutionTreeWithoutBranches(t *testing.T) {
+	function := buildFunction(t, coverageSource, "classify")
+	analyser := newTestAnalyser(newFakeSolver(2), WithExecutionTree())
+	final := walk(analyser, function, 0)
+	final.Terminated = true
+	final.Incomplete = true
+	final.Findings = []Finding{{Kind: GoroutinePanic, Message: "boom"}}
+	analyser.Results = []Interpreter{final}
+
+	tree := analyser.ExecutionTree()
+	if len(tree.Nodes) != 1 {
+		t.Fatalf("%d nodes, want a single root", len(tree.Nodes))
+	}
+	node := tree.Nodes[0]
+	if !node.Terminal || !node.Incomplete || node.Block != 0 || node.Result != GoroutinePanic.String()+": boom" {
+		t.Errorf("node = %+v", node)
+	}
+
+	if tree := newTestAnalyser(newFakeSolver(2)).ExecutionTree(); tree != nil {
+		t.Errorf("tree without WithExecutionTree = %v", tree)
+	}
+}
+
+func TestExecutionTreeDOT(t *testing.T) {
+	tree := &ExecutionTree{Nodes: []*TreeNode{
+		{ID: 1, Function: "f", Block: 0},
+		{ID: 2, Parent: 1, Block: 1, Function: "f", Condition: "x > 0 (true)", Verdict: solver.SAT.String(), Terminal: true, Result: "return 1"},
+		{ID: 3, Parent: 1, Block: -1, Condition: "x > 0 (false)", Verdict: solver.UNSAT.String()},
+		{ID: 4, Parent: 1, Block: 2, Function: "f", Verdict: solver.UNKNOWN.String(), Terminal: true, Incomplete: true, Result: "return", MergedInto: 2},
+	}}
+	dot := tree.DOT("pkg.f")
+	for _, want := range []string{
+		"digraph \"pkg.f\" {\n",
+		"\tn1 [label=\"f: block 0\"];\n",
+		"\tn2 [label=\"f: block 1\\nsat\\nreturn 1\", peripheries=2];\n",
+		"\tn1 -> n2 [label=\"x > 0 (true)\"];\n",
+		"\tn3 [label=\"state 3\\nunsat\", color=red, style=dashed];\n",
+		"\tn4 [label=\"f: block 2\\nunknown\\nreturn (incomplete)\", color=orange, peripheries=2];\n",
+		"\tn4 -> n2 [style=dotted, label=\"merged\"];\n",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT misses %q:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, "-> n1 ") {
+		t.Errorf("root has an incoming edge:\n%s", dot)
+	}
+}
+
+func TestExecutionTreeJSON(t *testing.T) {
+	tree := &ExecutionTree{Nodes: []*TreeNode{
+		{ID: 1, Block: 0, Function: "f"},
+		{ID: 2, Parent: 1, Block: 1, Function: "f", Condition: "flag (false)", Verdict: "sat", Terminal: true, Result: "return 3", MergedInto: 1},
+	}}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"nodes":[`, `"merged_into":1`, `"condition":"flag (false)"`, `"terminal":true`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON misses %s: %s", want, data)
+		}
+	}
+	if strings.Contains(string(data), `"incomplete"`) {
+		t.Errorf("false flags must be omitted: %s", data)
+	}
+
+	var decoded ExecutionTree
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Nodes) != 2 || *decoded.Nodes[1] != *tree.Nodes[1] {
+		t.Errorf("decoded = %+v", decoded.Nodes)
+	}
+}
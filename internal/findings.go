@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// FindingKind — вид ошибки, найденной на пути исполнения
+type FindingKind int
+
+const (
+	// Deadlock — все горутины заблокированы
+	Deadlock FindingKind = iota
+	// SendOnClosedChannel — отправка в закрытый канал
+	SendOnClosedChannel
+	// CloseOfClosedChannel — повторное закрытие канала или закрытие nil канала
+	CloseOfClosedChannel
+	// GoroutinePanic — паника, не перехваченная в горутине
+	GoroutinePanic
+	// DataRace — конфликтующие обращения к памяти, не упорядоченные синхронизацией
+	DataRace
+	// AssertionViolation — нарушение spec.Assert
+	AssertionViolation
+	// PreconditionViolation — вызов нарушает //sym:requires вызываемой функции
+	PreconditionViolation
+	// PostconditionViolation — результат нарушает //sym:ensures
+	PostconditionViolation
+)
+
+// String возвращает строковое представление вида ошибки
+func (kind FindingKind) String() string {
+	switch kind {
+	case Deadlock:
+		return "deadlock"
+	case SendOnClosedChannel:
+		return "send-on-closed-channel"
+	case CloseOfClosedChannel:
+		return "close-of-closed-channel"
+	case GoroutinePanic:
+		return "goroutine-panic"
+	case DataRace:
+		return "data-race"
+	case AssertionViolation:
+		return "assertion-violation"
+	case PreconditionViolation:
+		return "precondition-violation"
+	case PostconditionViolation:
+		return "postcondition-violation"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText представляет вид ошибки строкой в JSON
+func (kind FindingKind) MarshalText() ([]byte, error) {
+	return []byte(kind.String()), nil
+}
+
+// UnmarshalText восстанавливает вид ошибки из строки
+func (kind *FindingKind) UnmarshalText(text []byte) error {
+	for candidate := Deadlock; candidate.String() != "unknown"; candidate++ {
+		if candidate.String() == string(text) {
+			*kind = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown finding kind %q", text)
+}
+
+// Сообщения паник времени исполнения, как их выводит Go
+const (
+	DivideByZeroMessage   = "runtime error: integer divide by zero"
+	NilDereferenceMessage = "runtime error: invalid memory address or nil pointer dereference"
+)
+
+// Finding — ошибка, найденная на пути исполнения. Входные данные,
+// воспроизводящие её, берутся из модели условия пути состояния.
+type Finding struct {
+	Kind FindingKind `json:"kind"`
+	// Goroutine — номер горутины, в которой произошла ошибка
+	Goroutine int    `json:"goroutine"`
+	Message   string `json:"message"`
+	// Schedule — номера горутин в порядке переключений до ошибки
+	Schedule []int `json:"schedule,omitempty"`
+	// Location — позиция инструкции, на которой найдена ошибка
+	Location *Location `json:"location,omitempty"`
+	// Path — позиции исполненных инструкций от входа в функцию до ошибки
+	Path []Location `json:"path,omitempty"`
+}
+
+// Location — позиция в исходном коде
+type Location struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+}
+
+// String возвращает позицию в виде file:line:column
+func (location Location) String() string {
+	if location.Column == 0 {
+		return fmt.Sprintf("%s:%d", location.File, location.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", location.File, location.Line, location.Column)
+}
+
+// instructionLocation возвращает позицию инструкции (ok = false, если её нет)
+func instructionLocation(instruction ssa.Instruction) (Location, bool) {
+	if instruction == nil || !instruction.Pos().IsValid() || instruction.Parent() == nil {
+		return Location{}, false
+	}
+	position := instruction.Parent().Prog.Fset.Position(instruction.Pos())
+	return Location{File: position.Filename, Line: position.Line, Column: position.Column}, true
+}
+
+// step отмечает исполнение инструкции для восстановления пути в отчётах.
+// Из подряд идущих инструкций одной строки в путь попадает первая.
+// interpretDynamically вызывает его для каждой исполняемой инструкции,
+// иначе ошибки не получат позицию и путь исполнения, а модели вызовов —
+// инструкцию вызова.
+func (interpreter *Interpreter) step(instruction ssa.Instruction) {
+	interpreter.joinPoint = nil
+	interpreter.instruction = instruction
+	current, ok := instructionLocation(instruction)
+	if !ok {
+		return
+	}
+	if last := len(interpreter.Executed) - 1; last >= 0 {
+		previous, _ := instructionLocation(interpreter.Executed[last])
+		if previous.File == current.File && previous.Line == current.Line {
+			return
+		}
+	}
+	interpreter.Executed = append(interpreter.Executed, instruction)
+}
+
+// report добавляет ошибку к состоянию
+func (interpreter *Interpreter) report(kind FindingKind, message string) {
+	finding := Finding{Kind: kind, Message: message}
+	if scheduler := interpreter.Scheduler; scheduler != nil {
+		finding.Goroutine = scheduler.Current
+		finding.Schedule = append([]int{}, scheduler.Trace...)
+	}
+	for _, instruction := range interpreter.Executed {
+		location, _ := instructionLocation(instruction)
+		finding.Path = append(finding.Path, location)
+	}
+	// В пути из инструкций строки остаётся первая, а позиция ошибки —
+	// позиция самой исполняемой инструкции (например, оператора деления)
+	if location, ok := instructionLocation(interpreter.instruction); ok {
+		finding.Location = &location
+	} else if len(finding.Path) > 0 {
+		finding.Location = &finding.Path[len(finding.Path)-1]
+	}
+	interpreter.Findings = append(interpreter.Findings, finding)
+}
+
+// abort сообщает об ошибке, завершающей программу, и делает состояние конечным
+func (interpreter *Interpreter) abort(kind FindingKind, message string) {
+	interpreter.report(kind, message)
+	interpreter.Terminated = true
+}
@@ -0,0 +1,342 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// fuzzCorpusHeader — первая строка файла корпуса go test -fuzz
+const fuzzCorpusHeader = "go test fuzz v1"
+
+// ErrNotFuzzable — параметры функции не поддерживаются testing.F или анализатором
+var ErrNotFuzzable = errors.New("function parameters are not supported by fuzzing")
+
+// WithFuzzHarness включает генерацию функций FuzzXxx и начального корпуса
+// testdata/fuzz/FuzzXxx из моделей путей в GenerateTestFile: в тестовый
+// файл добавляется FuzzHarness каждой функции вместе с импортами testing и
+// пакетов её параметров, а входы её путей записываются через
+// WriteFuzzCorpus в каталог исходного файла
+func WithFuzzHarness() Option {
+	return func(analyser *Analyser) {
+		analyser.FuzzHarness = true
+	}
+}
+
+// WithFuzzCorpus добавляет записи корпуса из каталога пакета dir
+// (testdata/fuzz/FuzzXxx) к начальным входам AnalyseConcolic
+func WithFuzzCorpus(dir string) Option {
+	return func(analyser *Analyser) {
+		analyser.CorpusDir = dir
+	}
+}
+
+// FuzzName возвращает имя fuzz-теста функции
+func FuzzName(function string) string {
+	runes := []rune(function)
+	runes[0] = unicode.ToUpper(runes[0])
+	return "Fuzz" + string(runes)
+}
+
+// FuzzHarness строит функцию FuzzXxx, вызывающую function на входах
+// фаззера, и пути пакетов, которые нужно импортировать для приведения
+// входов к именованным типам параметров (кроме testing). ok = false, если
+// среди параметров есть типы, которые не поддерживает testing.F или
+// символьный анализатор.
+func FuzzHarness(function *ssa.Function) (harness string, imports []string, ok bool) {
+	if function.Signature.Recv() != nil || function.TypeParams().Len() > 0 {
+		return "", nil, false
+	}
+	// Типы других пакетов записываются через имя импортированного пакета
+	qualifier := func(pkg *types.Package) string {
+		if pkg == function.Pkg.Pkg {
+			return ""
+		}
+		if !slices.Contains(imports, pkg.Path()) {
+			imports = append(imports, pkg.Path())
+		}
+		return pkg.Name()
+	}
+	params := make([]string, len(function.Params))
+	args := make([]string, len(function.Params))
+	for i, param := range function.Params {
+		basic, ok := fuzzType(param.Type())
+		if !ok {
+			return "", nil, false
+		}
+		params[i] = fmt.Sprintf("%s %s", param.Name(), basic.Name())
+		args[i] = param.Name()
+		if !types.Identical(param.Type(), basic) {
+			args[i] = fmt.Sprintf("%s(%s)", types.TypeString(param.Type(), qualifier), param.Name())
+		}
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "func %s(f *testing.F) {\n", FuzzName(function.Name()))
+	fmt.Fprintf(&builder, "\tf.Fuzz(func(t *testing.T, %s) {\n", strings.Join(params, ", "))
+	fmt.Fprintf(&builder, "\t\t%s(%s)\n", function.Name(), strings.Join(args, ", "))
+	builder.WriteString("\t})\n}\n")
+	return builder.String(), imports, true
+}
+
+// fuzzType возвращает базовый тип параметра, если его поддерживают и
+// testing.F, и анализатор (целые и булевы значения)
+func fuzzType(tpe types.Type) (*types.Basic, bool) {
+	basic, ok := tpe.Underlying().(*types.Basic)
+	if !ok || basic.Info()&(types.IsInteger|types.IsBoolean) == 0 || basic.Kind() == types.Uintptr {
+		return nil, false
+	}
+	return basic, true
+}
+
+// WriteFuzzCorpus записывает входы путей как начальный корпус
+// dir/testdata/fuzz/FuzzXxx. Отсутствующие в модели параметры получают
+// нулевые значения. Возвращает число записанных файлов.
+func WriteFuzzCorpus(dir string, function *ssa.Function, inputs []solver.Model) (int, error) {
+	return writeCorpus(filepath.Join(dir, "testdata", "fuzz", FuzzName(function.Name())), function, inputs)
+}
+
+// writeCorpus записывает входы в каталог корпуса (testdata/fuzz/FuzzXxx
+// или каталог кэша фаззера)
+func writeCorpus(corpus string, function *ssa.Function, inputs []solver.Model) (int, error) {
+	written := 0
+	for _, model := range inputs {
+		entry, ok := fuzzEntry(function, model)
+		if !ok {
+			return written, fmt.Errorf("%s: %w", function.Name(), ErrNotFuzzable)
+		}
+		if err := os.MkdirAll(corpus, 0o755); err != nil {
+			return written, err
+		}
+		// Имя файла строится так же, как у go test -fuzz
+		name := fmt.Sprintf("%x", sha256.Sum256([]byte(entry)))[:16]
+		if err := os.WriteFile(filepath.Join(corpus, name), []byte(entry), 0o644); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// fuzzEntry кодирует входы в формате корпуса go test fuzz v1
+func fuzzEntry(function *ssa.Function, model solver.Model) (string, bool) {
+	lines := []string{fuzzCorpusHeader}
+	for _, param := range function.Params {
+		basic, ok := fuzzType(param.Type())
+		if !ok {
+			return "", false
+		}
+		value, ok := model[param.Name()]
+		if !ok {
+			value, _ = zeroValue(basic)
+		}
+		switch value := value.(type) {
+		case *symbolic.BoolConstant:
+			lines = append(lines, fmt.Sprintf("bool(%t)", value.Value))
+		case *symbolic.IntConstant:
+			lines = append(lines, fuzzInteger(basic, value.Value))
+		default:
+			return "", false
+		}
+	}
+	return strings.Join(lines, "\n") + "\n", true
+}
+
+// fuzzInteger записывает целое значение в формате корпуса, приводя его к
+// разрядности параметра так же, как преобразование типов в Go: модель
+// солвера не ограничена диапазоном типа, а go test -fuzz отвергает записи
+// со значениями вне него
+func fuzzInteger(basic *types.Basic, value int64) string {
+	switch basic.Kind() {
+	case types.Int8:
+		return fmt.Sprintf("%s(%d)", basic.Name(), int8(value))
+	case types.Int16:
+		return fmt.Sprintf("%s(%d)", basic.Name(), int16(value))
+	case types.Int32:
+		return fmt.Sprintf("%s(%d)", basic.Name(), int32(value))
+	case types.Uint8:
+		// go test -fuzz записывает байты символьными литералами
+		return fmt.Sprintf("byte(%q)", rune(uint8(value)))
+	case types.Uint16:
+		return fmt.Sprintf("%s(%d)", basic.Name(), uint16(value))
+	case types.Uint32:
+		return fmt.Sprintf("%s(%d)", basic.Name(), uint32(value))
+	case types.Uint, types.Uint64:
+		return fmt.Sprintf("%s(%d)", basic.Name(), uint64(value))
+	}
+	return fmt.Sprintf("%s(%d)", basic.Name(), value)
+}
+
+// ReadFuzzCorpus читает корпус dir/testdata/fuzz/FuzzXxx функции и
+// сопоставляет значения записей параметрам params по позиции. Значения
+// неподдерживаемых типов (строки, []byte, числа с плавающей точкой) в
+// модель не попадают; отсутствие корпуса не является ошибкой. Вместе с
+// ошибками повреждённых записей возвращаются все прочитанные.
+func ReadFuzzCorpus(dir string, functionName string, params []string) ([]solver.Model, error) {
+	return readCorpus(filepath.Join(dir, "testdata", "fuzz", FuzzName(functionName)), params)
+}
+
+// readCorpus читает записи каталога корпуса (testdata/fuzz/FuzzXxx или
+// каталог кэша фаззера с тем же форматом)
+func readCorpus(corpus string, params []string) ([]solver.Model, error) {
+	entries, err := os.ReadDir(corpus)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var models []solver.Model
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(corpus, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		model, err := parseFuzzEntry(string(data), params)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		models = append(models, model)
+	}
+	return models, errors.Join(errs...)
+}
+
+// parseFuzzEntry разбирает запись корпуса формата go test fuzz v1
+func parseFuzzEntry(data string, params []string) (solver.Model, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != fuzzCorpusHeader {
+		return nil, fmt.Errorf("missing %q header", fuzzCorpusHeader)
+	}
+	values := lines[1:]
+	if len(values) != len(params) {
+		return nil, fmt.Errorf("entry has %d values, function has %d parameters", len(values), len(params))
+	}
+
+	model := make(solver.Model, len(params))
+	for i, line := range values {
+		value, ok, err := parseFuzzValue(strings.TrimSpace(line))
+		if err != nil {
+			return nil, err
+		}
+		if ok && params[i] != "" {
+			model[params[i]] = value
+		}
+	}
+	return model, nil
+}
+
+// parseFuzzValue разбирает значение вида type(literal). ok = false для
+// типов, которые анализатор не представляет.
+func parseFuzzValue(line string) (symbolic.SymbolicExpression, bool, error) {
+	expr, err := parser.ParseExpr(line)
+	if err != nil {
+		return nil, false, fmt.Errorf("malformed value %q: %w", line, err)
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil, false, fmt.Errorf("malformed value %q", line)
+	}
+	typeName, ok := call.Fun.(*ast.Ident)
+	if !ok {
+		// []byte(...)
+		return nil, false, nil
+	}
+	basic, ok := types.Universe.Lookup(typeName.Name).(*types.TypeName)
+	if !ok {
+		return nil, false, fmt.Errorf("unknown type in %q", line)
+	}
+	if _, ok := fuzzType(basic.Type()); !ok {
+		return nil, false, nil
+	}
+
+	if ident, ok := call.Args[0].(*ast.Ident); ok && (ident.Name == "true" || ident.Name == "false") {
+		return symbolic.NewBoolConstant(ident.Name == "true"), true, nil
+	}
+	negative := false
+	literal := call.Args[0]
+	if unary, ok := literal.(*ast.UnaryExpr); ok && unary.Op == token.SUB {
+		negative, literal = true, unary.X
+	}
+	basicLiteral, ok := literal.(*ast.BasicLit)
+	if !ok {
+		return nil, false, fmt.Errorf("malformed value %q", line)
+	}
+
+	var value int64
+	switch basicLiteral.Kind {
+	case token.INT:
+		unsigned, err := strconv.ParseUint(basicLiteral.Value, 0, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("malformed value %q: %w", line, err)
+		}
+		value = int64(unsigned)
+	case token.CHAR:
+		char, _, _, err := strconv.UnquoteChar(strings.Trim(basicLiteral.Value, "'"), '\'')
+		if err != nil {
+			return nil, false, fmt.Errorf("malformed value %q: %w", line, err)
+		}
+		value = int64(char)
+	default:
+		return nil, false, fmt.Errorf("malformed value %q", line)
+	}
+	if negative {
+		value = -value
+	}
+	return symbolic.NewIntConstant(value), true, nil
+}
+
+// corpusSeeds читает корпус анализируемой функции из CorpusDir. Имена
+// параметров берутся из исходного текста, так как SSA ещё не построен.
+func (analyser *Analyser) corpusSeeds(source string, functionName string) ([]solver.Model, error) {
+	if analyser.CorpusDir == "" {
+		return nil, nil
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range file.Decls {
+		function, ok := decl.(*ast.FuncDecl)
+		if !ok || function.Recv != nil || function.Name.Name != functionName {
+			continue
+		}
+		var params []string
+		for _, field := range function.Type.Params.List {
+			if len(field.Names) == 0 {
+				params = append(params, "")
+			}
+			for _, name := range field.Names {
+				if name.Name == "_" {
+					params = append(params, "")
+				} else {
+					params = append(params, name.Name)
+				}
+			}
+		}
+		return ReadFuzzCorpus(analyser.CorpusDir, functionName, params)
+	}
+	return nil, fmt.Errorf("function %s not found", functionName)
+}
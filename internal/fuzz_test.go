@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+const fuzzSource = `package main
+
+import "time"
+
+type Level int8
+
+func widths(a int8, b int16, c int32, d uint8, e uint16, f uint32, g uint64, h int, flag bool) {}
+
+func named(level Level, timeout time.Duration, r rune) {}
+
+func text(s string) {}
+`
+
+func TestFuzzHarness(t *testing.T) {
+	tests := []struct {
+		function string
+		ok       bool
+		want     []string
+		imports  []string
+	}{
+		{"named", true, []string{
+			"func FuzzNamed(f *testing.F) {",
+			"\tf.Fuzz(func(t *testing.T, level int8, timeout int64, r rune) {",
+			"\t\tnamed(Level(level), time.Duration(timeout), r)",
+		}, []string{"time"}},
+		{"widths", true, []string{"a int8, b int16, c int32, d uint8, e uint16, f uint32, g uint64, h int, flag bool"}, nil},
+		{"text", false, nil, nil},
+	}
+	for _, test := range tests {
+		harness, imports, ok := FuzzHarness(buildFunction(t, fuzzSource, test.function))
+		if ok != test.ok {
+			t.Fatalf("%s: ok = %t, want %t", test.function, ok, test.ok)
+		}
+		for _, line := range test.want {
+			if !strings.Contains(harness, line) {
+				t.Errorf("%s: harness misses %q:\n%s", test.function, line, harness)
+			}
+		}
+		if !slices.Equal(imports, test.imports) {
+			t.Errorf("%s: imports = %v, want %v", test.function, imports, test.imports)
+		}
+	}
+}
+
+func TestFuzzEntryWrapsToParameterWidth(t *testing.T) {
+	function := buildFunction(t, fuzzSource, "widths")
+	model := solver.Model{
+		"a":    intConst(300),
+		"b":    intConst(-40000),
+		"c":    intConst(1 << 33),
+		"d":    intConst(-1),
+		"e":    intConst(70000),
+		"f":    intConst(-1),
+		"g":    intConst(-1),
+		"h":    intConst(math.MinInt64),
+		"flag": symbolic.NewBoolConstant(true),
+	}
+	entry, ok := fuzzEntry(function, model)
+	if !ok {
+		t.Fatal("entry was not encoded")
+	}
+	want := strings.Join([]string{
+		fuzzCorpusHeader,
+		"int8(44)",
+		"int16(25536)",
+		"int32(0)",
+		`byte('ÿ')`,
+		"uint16(4464)",
+		"uint32(4294967295)",
+		"uint64(18446744073709551615)",
+		"int(-9223372036854775808)",
+		"bool(true)",
+	}, "\n") + "\n"
+	if entry != want {
+		t.Errorf("entry =\n%s\nwant\n%s", entry, want)
+	}
+}
+
+func TestFuzzCorpusRoundTrip(t *testing.T) {
+	function := buildFunction(t, fuzzSource, "widths")
+	params := make([]string, len(function.Params))
+	for i, param := range function.Params {
+		params[i] = param.Name()
+	}
+	inputs := []solver.Model{
+		{"a": intConst(-128), "b": intConst(32767), "c": intConst(-5), "d": intConst(200), "e": intConst(1),
+			"f": intConst(7), "g": intConst(1 << 40), "h": intConst(-3), "flag": symbolic.NewBoolConstant(true)},
+		// Отсутствующие параметры получают нулевые значения
+		{"a": intConst(1)},
+	}
+	dir := t.TempDir()
+	written, err := WriteFuzzCorpus(dir, function, inputs)
+	if err != nil || written != len(inputs) {
+		t.Fatalf("WriteFuzzCorpus = %d, %v", written, err)
+	}
+	models, err := ReadFuzzCorpus(dir, "widths", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != len(inputs) {
+		t.Fatalf("read %d entries, want %d", len(models), len(inputs))
+	}
+	for _, input := range inputs {
+		expected := completeInputs(function, input)
+		if !slices.ContainsFunc(models, func(model solver.Model) bool { return modelKey(model) == modelKey(expected) }) {
+			t.Errorf("corpus misses %s", modelKey(expected))
+		}
+	}
+}
+
+func TestReadFuzzCorpusErrors(t *testing.T) {
+	dir := t.TempDir()
+	corpus := filepath.Join(dir, "testdata", "fuzz", "FuzzF")
+	if err := os.MkdirAll(corpus, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	entries := map[string]string{
+		"valid":     fuzzCorpusHeader + "\nint(1)\n[]byte(\"x\")\n",
+		"header":    "int(1)\nint(2)\n",
+		"arity":     fuzzCorpusHeader + "\nint(1)\n",
+		"malformed": fuzzCorpusHeader + "\nint(\nint(2)\n",
+		"unknown":   fuzzCorpusHeader + "\nfoo(1)\nint(2)\n",
+	}
+	for name, data := range entries {
+		if err := os.WriteFile(filepath.Join(corpus, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	models, err := ReadFuzzCorpus(dir, "f", []string{"x", "data"})
+	if len(models) != 1 || len(models[0]) != 1 || models[0]["x"].(*symbolic.IntConstant).Value != 1 {
+		t.Errorf("models = %v, want only x from the valid entry", models)
+	}
+	for _, name := range []string{"header", "arity", "malformed", "unknown"} {
+		if err == nil || !strings.Contains(err.Error(), filepath.Join(corpus, name)) {
+			t.Errorf("error %v does not report the %s entry", err, name)
+		}
+	}
+
+	if models, err := ReadFuzzCorpus(t.TempDir(), "f", nil); models != nil || err != nil {
+		t.Errorf("missing corpus = %v, %v", models, err)
+	}
+}
+
+func TestParseFuzzValue(t *testing.T) {
+	tests := []struct {
+		line  string
+		value symbolic.SymbolicExpression
+		ok    bool
+	}{
+		{"int(-3)", intConst(-3), true},
+		{"uint64(18446744073709551615)", intConst(-1), true},
+		{"byte('a')", intConst('a'), true},
+		{`byte('\x00')`, intConst(0), true},
+		{"rune('я')", intConst('я'), true},
+		{"int8(0x10)", intConst(16), true},
+		{"bool(false)", symbolic.NewBoolConstant(false), true},
+		{`string("x")`, nil, false},
+		{"float64(1.5)", nil, false},
+		{`[]byte("x")`, nil, false},
+	}
+	for _, test := range tests {
+		value, ok, err := parseFuzzValue(test.line)
+		if err != nil || ok != test.ok {
+			t.Errorf("%s: ok = %t, err = %v", test.line, ok, err)
+			continue
+		}
+		if ok && value.String() != test.value.String() {
+			t.Errorf("%s: value = %s, want %s", test.line, value, test.value)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// requireExpressions пропускает тест, пока конструкторы и методы выражений
+// из домашнего задания (NewBinaryOperation, NewLogicalOperation, String,
+// Type) не реализованы
+func requireExpressions(t *testing.T) {
+	t.Helper()
+	defer func() {
+		if recover() != nil {
+			t.Skip("symbolic expression constructors are not implemented yet")
+		}
+	}()
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	comparison := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(0), symbolic.EQ)
+	negation := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{comparison}, symbolic.NOT)
+	_ = negation.String() + comparison.String()
+	_ = comparison.Type()
+}
+
+// Построители выражений без конструкторов из домашнего задания
+func intVar(name string) *symbolic.SymbolicVariable {
+	return symbolic.NewSymbolicVariable(name, symbolic.IntType)
+}
+
+func boolVar(name string) *symbolic.SymbolicVariable {
+	return symbolic.NewSymbolicVariable(name, symbolic.BoolType)
+}
+
+func intConst(value int64) *symbolic.IntConstant {
+	return symbolic.NewIntConstant(value)
+}
+
+func compare(left symbolic.SymbolicExpression, op symbolic.BinaryOperator, right symbolic.SymbolicExpression) *symbolic.BinaryOperation {
+	return &symbolic.BinaryOperation{Left: left, Right: right, Operator: op}
+}
+
+func logical(op symbolic.LogicalOperator, operands ...symbolic.SymbolicExpression) *symbolic.LogicalOperation {
+	return &symbolic.LogicalOperation{Operands: operands, Operator: op}
+}
+
+// fakeSolver — SolverBackend для тестов: перебирает значения переменных
+// в [-domain, domain] и вычисляет ограничения через symbolic.Evaluate
+type fakeSolver struct {
+	domain int64
+	// unknown заставляет Check отвечать UNKNOWN; unknownChecks — только
+	// первые unknownChecks проверок. Проверки после первых failAfter
+	// завершаются ошибкой.
+	unknown       bool
+	unknownChecks int
+	failAfter     int
+	scopes        [][]symbolic.SymbolicExpression
+	model         solver.Model
+	checks        int
+}
+
+func newFakeSolver(domain int64) *fakeSolver {
+	return &fakeSolver{domain: domain, scopes: make([][]symbolic.SymbolicExpression, 1)}
+}
+
+func (fs *fakeSolver) Name() string { return "fake" }
+
+func (fs *fakeSolver) Assert(constraint symbolic.SymbolicExpression) error {
+	top := len(fs.scopes) - 1
+	fs.scopes[top] = append(fs.scopes[top], constraint)
+	return nil
+}
+
+func (fs *fakeSolver) Check() (solver.Result, error) {
+	fs.checks++
+	if fs.failAfter > 0 && fs.checks > fs.failAfter {
+		return solver.UNKNOWN, errors.New("fake solver failure")
+	}
+	if fs.unknown || fs.checks <= fs.unknownChecks {
+		return solver.UNKNOWN, nil
+	}
+	var constraints []symbolic.SymbolicExpression
+	for _, scope := range fs.scopes {
+		constraints = append(constraints, scope...)
+	}
+	model, ok := fs.search(symbolic.CollectVariables(constraints...), constraints, solver.Model{})
+	if !ok {
+		return solver.UNSAT, nil
+	}
+	fs.model = model
+	return solver.SAT, nil
+}
+
+// search перебирает значения переменных начиная с variables[0]
+func (fs *fakeSolver) search(variables []*symbolic.SymbolicVariable, constraints []symbolic.SymbolicExpression, model solver.Model) (solver.Model, bool) {
+	if len(variables) == 0 {
+		for _, constraint := range constraints {
+			value, err := symbolic.Evaluate(constraint, model)
+			if holds, ok := value.(*symbolic.BoolConstant); err != nil || !ok || !holds.Value {
+				return nil, false
+			}
+		}
+		result := make(solver.Model, len(model))
+		for name, value := range model {
+			result[name] = value
+		}
+		return result, true
+	}
+
+	variable := variables[0]
+	var values []symbolic.SymbolicExpression
+	switch variable.ExprType {
+	case symbolic.BoolType:
+		values = []symbolic.SymbolicExpression{symbolic.NewBoolConstant(true), symbolic.NewBoolConstant(false)}
+	default:
+		// Сначала большие значения, чтобы модели не были минимальными сами по себе
+		for value := fs.domain; value >= -fs.domain; value-- {
+			values = append(values, symbolic.NewIntConstant(value))
+		}
+	}
+	for _, value := range values {
+		model[variable.Name] = value
+		if found, ok := fs.search(variables[1:], constraints, model); ok {
+			delete(model, variable.Name)
+			return found, true
+		}
+	}
+	delete(model, variable.Name)
+	return nil, false
+}
+
+func (fs *fakeSolver) ReasonUnknown() string { return "fake" }
+
+func (fs *fakeSolver) Push() error {
+	fs.scopes = append(fs.scopes, nil)
+	return nil
+}
+
+func (fs *fakeSolver) Pop() error {
+	fs.scopes = fs.scopes[:len(fs.scopes)-1]
+	return nil
+}
+
+func (fs *fakeSolver) Model() (solver.Model, error) { return fs.model, nil }
+
+func (fs *fakeSolver) SetTimeout(timeout time.Duration) error { return nil }
+
+func (fs *fakeSolver) SetResourceLimit(limit uint) error { return nil }
+
+func (fs *fakeSolver) Close() error { return nil }
+
+// newTestAnalyser создаёт Analyser с поддельным солвером
+func newTestAnalyser(backend *fakeSolver, options ...Option) *Analyser {
+	return NewAnalyser(append([]Option{WithSolver(backend)}, options...)...)
+}
+
+// buildFunction строит SSA функции name из исходного кода пакета main
+func buildFunction(t *testing.T, source, name string) *ssa.Function {
+	t.Helper()
+	return buildPackageFunction(t, "main", source, name)
+}
+
+// buildPackageFunction строит SSA функции name пакета с путём path
+func buildPackageFunction(t *testing.T, path, source, name string) *ssa.Function {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	pkg, err := new(types.Config{Importer: importer.Default()}).Check(path, fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// GlobalDebug сохраняет синтаксис функций, из которого читаются контракты
+	program := ssa.NewProgram(fset, ssa.SanityCheckFunctions|ssa.GlobalDebug)
+	created := make(map[*types.Package]bool)
+	var createImports func(*types.Package)
+	createImports = func(pkg *types.Package) {
+		for _, imported := range pkg.Imports() {
+			if !created[imported] {
+				created[imported] = true
+				createImports(imported)
+				program.CreatePackage(imported, nil, nil, true)
+			}
+		}
+	}
+	createImports(pkg)
+	ssaPkg := program.CreatePackage(pkg, []*ast.File{file}, info, false)
+	ssaPkg.Build()
+
+	function := ssaPkg.Func(name)
+	if function == nil {
+		t.Fatalf("function %s not found", name)
+	}
+	return function
+}
+
+// firstCall возвращает первый вызов в теле функции
+func firstCall(t *testing.T, function *ssa.Function) *ssa.Call {
+	t.Helper()
+	for _, block := range function.Blocks {
+		for _, instruction := range block.Instrs {
+			if call, ok := instruction.(*ssa.Call); ok {
+				return call
+			}
+		}
+	}
+	t.Fatalf("no call in %s", function)
+	return nil
+}
@@ -0,0 +1,388 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	ssabuilder "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// Значения HybridConfig по умолчанию
+const (
+	defaultFuzzTime     = 10 * time.Second
+	defaultHybridRounds = 5
+)
+
+// HybridConfig — параметры гибридного фаззинга
+type HybridConfig struct {
+	// FuzzTime — длительность одного запуска go test -fuzz
+	FuzzTime time.Duration
+	// Rounds — наибольшее число чередований фаззера и символьного анализа
+	Rounds int
+}
+
+// HybridReport — итоги гибридного фаззинга функции
+type HybridReport struct {
+	Rounds int `json:"rounds"`
+	// FuzzerInputs — входы, расширившие покрытие, найденные фаззером
+	FuzzerInputs int `json:"fuzzerInputs"`
+	// SolverInputs — входы, построенные символьным анализатором и добавленные в корпус
+	SolverInputs int `json:"solverInputs"`
+	// Failure — вывод go test, если фаззер нашёл падение
+	Failure string `json:"failure,omitempty"`
+	// Crasher — запись testdata/fuzz/FuzzXxx с падающим входом
+	Crasher string `json:"crasher,omitempty"`
+	// CorpusErrors — ошибки чтения записей корпуса; такие записи пропускаются
+	CorpusErrors []string `json:"corpusErrors,omitempty"`
+}
+
+// HybridFuzz чередует фаззер и символьный анализатор для функции файла
+// sourceFile. Каждый раунд go test -fuzz работает FuzzTime на обёртке
+// FuzzXxx, которая подключается к пакету через -overlay. Новые входы
+// фаззера и солвера исполняются конколически по одному разу, и для
+// ветвлений, другая ветвь которых не пройдена ни одним известным входом,
+// солвер строит входы, проходящие её (хэши, «магические» числа). Они
+// записываются в корпус фаззера в кэше Go к следующему раунду, каталог
+// пакета не изменяется. Анализ завершается после Rounds раундов, при
+// падении программы или когда ни фаззер, ни солвер не нашли новых входов.
+func (analyser *Analyser) HybridFuzz(sourceFile string, functionName string, config HybridConfig) (HybridReport, error) {
+	if config.FuzzTime <= 0 {
+		config.FuzzTime = defaultFuzzTime
+	}
+	if config.Rounds <= 0 {
+		config.Rounds = defaultHybridRounds
+	}
+	source, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return HybridReport{}, err
+	}
+	builder := analyser.Builder
+	if builder == nil {
+		builder = ssabuilder.NewBuilder()
+	}
+	function, err := builder.ParseAndBuildSSA(string(source), functionName)
+	if err != nil {
+		return HybridReport{}, err
+	}
+
+	dir := filepath.Dir(sourceFile)
+	fuzzName := FuzzName(functionName)
+	overlay, cleanup, err := fuzzOverlay(dir, function)
+	if err != nil {
+		return HybridReport{}, err
+	}
+	defer cleanup()
+	cache, err := fuzzCacheDir(dir, fuzzName)
+	if err != nil {
+		return HybridReport{}, err
+	}
+	crashers := filepath.Join(dir, "testdata", "fuzz", fuzzName)
+
+	params := make([]string, len(function.Params))
+	for i, param := range function.Params {
+		params[i] = param.Name()
+	}
+	seen := make(map[string]bool)
+	remember := func(models []solver.Model) []solver.Model {
+		var fresh []solver.Model
+		for _, model := range models {
+			model = completeInputs(function, model)
+			if key := modelKey(model); !seen[key] {
+				seen[key] = true
+				fresh = append(fresh, model)
+			}
+		}
+		return fresh
+	}
+	var report HybridReport
+	pending := remember(report.readCorpus(crashers, params))
+	var paths []Interpreter
+	for report.Rounds < config.Rounds {
+		report.Rounds++
+		before, err := corpusEntries(crashers)
+		if err != nil {
+			return report, err
+		}
+		output, fuzzErr := runFuzzer(dir, fuzzName, overlay, config.FuzzTime)
+		if fuzzErr != nil {
+			// Падающий вход go test -fuzz сохраняет в testdata/fuzz/FuzzXxx;
+			// без новой записи ошибка означает сбой сборки или запуска
+			crasher, err := newCorpusEntry(crashers, before)
+			if err != nil {
+				return report, err
+			}
+			if crasher == "" {
+				return report, fmt.Errorf("go test -fuzz: %w\n%s", fuzzErr, output)
+			}
+			report.Failure = output
+			report.Crasher = crasher
+			return report, nil
+		}
+
+		discovered := report.readCorpus(cache, params)
+		fresh := remember(discovered)
+		report.FuzzerInputs += len(fresh)
+
+		// Новые входы исполняются по одному разу; известные пути не повторяются
+		replayed := analyser.replay(string(source), functionName, append(pending, fresh...))
+		paths = append(paths, replayed...)
+		targeted, err := analyser.targetUncovered(paths, replayed)
+		if err != nil {
+			return report, err
+		}
+		pending = remember(targeted)
+		if len(fresh) == 0 && len(pending) == 0 {
+			break
+		}
+		written, err := writeCorpus(cache, function, pending)
+		report.SolverInputs += written
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// replay исполняет функцию конколически на каждом входе, не инвертируя
+// ветвления: каждый вход проходит единственный путь
+func (analyser *Analyser) replay(source string, functionName string, inputs []solver.Model) []Interpreter {
+	defer func() { analyser.ConcreteInputs = nil }()
+	var finals []Interpreter
+	for _, input := range inputs {
+		analyser.ConcreteInputs = input
+		finals = append(finals, analyser.Analyse(source, functionName)...)
+	}
+	return finals
+}
+
+// readCorpus читает записи каталога корпуса. Повреждённые записи не
+// прерывают фаззинг: они пропускаются, а ошибки попадают в CorpusErrors.
+func (report *HybridReport) readCorpus(corpus string, params []string) []solver.Model {
+	models, err := readCorpus(corpus, params)
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			report.CorpusErrors = append(report.CorpusErrors, err.Error())
+		}
+	} else if err != nil {
+		report.CorpusErrors = append(report.CorpusErrors, err.Error())
+	}
+	return models
+}
+
+// targetUncovered строит входы для рёбер ветвлений, которые не прошёл ни
+// один путь из paths. Для каждого ветвления путей fresh, другая ветвь
+// которого не покрыта, солвер инвертирует его условие, сохраняя префикс
+// пути; на каждое ребро строится не больше одного входа. Ветвления пути
+// сопоставляются условиям Branches по порядку: в конколическом режиме
+// каждый *ssa.If добавляет одно условие через concolicBranch, а допущения
+// Assumptions пропускаются.
+func (analyser *Analyser) targetUncovered(paths []Interpreter, fresh []Interpreter) ([]solver.Model, error) {
+	covered := make(map[edge]bool)
+	for _, path := range paths {
+		for i := 1; i < len(path.Blocks); i++ {
+			covered[edge{path.Blocks[i-1], path.Blocks[i]}] = true
+		}
+	}
+
+	var inputs []solver.Model
+	for _, path := range fresh {
+		branch := 0
+		for i := 0; i+1 < len(path.Blocks) && branch < len(path.Branches); i++ {
+			block := path.Blocks[i]
+			if _, ok := block.Instrs[len(block.Instrs)-1].(*ssa.If); !ok {
+				continue
+			}
+			for slices.Contains(path.Assumptions, branch) {
+				branch++
+			}
+			if branch == len(path.Branches) {
+				break
+			}
+			for _, successor := range block.Succs {
+				target := edge{block, successor}
+				if covered[target] {
+					continue
+				}
+				constraints := append(slices.Clone(path.Branches[:branch]), negate(path.Branches[branch]))
+				result, model, err := analyser.checkSat(symbolic.NewLogicalOperation(constraints, symbolic.AND))
+				if err != nil {
+					return inputs, err
+				}
+				if result != solver.SAT {
+					continue
+				}
+				// Входы, не участвующие в условиях, сохраняют значения пути
+				input := maps.Clone(path.ConcreteInputs)
+				if input == nil {
+					input = make(solver.Model, len(model))
+				}
+				maps.Copy(input, model)
+				inputs = append(inputs, input)
+				covered[target] = true
+			}
+			branch++
+		}
+	}
+	return inputs, nil
+}
+
+// completeInputs дополняет модель нулевыми значениями отсутствующих параметров,
+// чтобы одинаковые входы имели одинаковый ключ
+func completeInputs(function *ssa.Function, model solver.Model) solver.Model {
+	complete := make(solver.Model, len(function.Params))
+	for _, param := range function.Params {
+		if value, ok := model[param.Name()]; ok {
+			complete[param.Name()] = value
+		} else if value, ok := zeroValue(param.Type()); ok {
+			complete[param.Name()] = value
+		}
+	}
+	return complete
+}
+
+// fuzzOverlay подключает к пакету файл с FuzzXxx, если тестовые файлы
+// пакета её ещё не объявляют. Файл записывается во временный каталог, а
+// в пакет добавляется через возвращённый файл для go test -overlay (пустая
+// строка, если обёртка не нужна). cleanup удаляет временные файлы.
+func fuzzOverlay(dir string, function *ssa.Function) (overlay string, cleanup func(), err error) {
+	name := FuzzName(function.Name())
+	tests, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, test := range tests {
+		data, err := os.ReadFile(test)
+		if err != nil {
+			return "", nil, err
+		}
+		if bytes.Contains(data, []byte("func "+name+"(")) {
+			return "", func() {}, nil
+		}
+	}
+
+	harness, imports, ok := FuzzHarness(function)
+	if !ok {
+		return "", nil, fmt.Errorf("%s: %w", function.Name(), ErrNotFuzzable)
+	}
+	var content strings.Builder
+	fmt.Fprintf(&content, "// Code generated by symgo; DO NOT EDIT.\n\npackage %s\n\nimport (\n\t\"testing\"\n", function.Pkg.Pkg.Name())
+	for _, importPath := range imports {
+		fmt.Fprintf(&content, "\t%q\n", importPath)
+	}
+	fmt.Fprintf(&content, ")\n\n%s", harness)
+
+	temp, err := os.MkdirTemp("", "symgo-fuzz-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { _ = os.RemoveAll(temp) }
+	packageDir, err := filepath.Abs(dir)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	harnessFile := filepath.Join(temp, "harness_test.go")
+	replace := map[string]map[string]string{"Replace": {
+		filepath.Join(packageDir, strings.ToLower(function.Name())+"_symgo_fuzz_test.go"): harnessFile,
+	}}
+	data, err := json.Marshal(replace)
+	if err == nil {
+		err = os.WriteFile(harnessFile, []byte(content.String()), 0o644)
+	}
+	if err == nil {
+		overlay = filepath.Join(temp, "overlay.json")
+		err = os.WriteFile(overlay, data, 0o644)
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return overlay, cleanup, nil
+}
+
+// corpusEntries возвращает имена записей каталога корпуса (пустой набор,
+// если каталога нет)
+func corpusEntries(corpus string) (map[string]bool, error) {
+	entries, err := os.ReadDir(corpus)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names[entry.Name()] = true
+		}
+	}
+	return names, nil
+}
+
+// newCorpusEntry возвращает путь записи корпуса, которой не было среди
+// before (пустая строка, если новых записей нет)
+func newCorpusEntry(corpus string, before map[string]bool) (string, error) {
+	after, err := corpusEntries(corpus)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range slices.Sorted(maps.Keys(after)) {
+		if !before[name] {
+			return filepath.Join(corpus, name), nil
+		}
+	}
+	return "", nil
+}
+
+// fuzzCacheDir возвращает каталог, в который go test -fuzz сохраняет
+// входы, расширившие покрытие: $GOCACHE/fuzz/<пакет>/<FuzzXxx>
+func fuzzCacheDir(dir string, fuzzName string) (string, error) {
+	cache, err := goCommand(dir, "env", "GOCACHE")
+	if err != nil {
+		return "", err
+	}
+	importPath, err := goCommand(dir, "list", "-f", "{{.ImportPath}}", ".")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "fuzz", importPath, fuzzName), nil
+}
+
+// runFuzzer запускает go test -fuzz в каталоге пакета (с файлом overlay,
+// если он задан) и возвращает его вывод
+func runFuzzer(dir string, fuzzName string, overlay string, fuzzTime time.Duration) (string, error) {
+	args := []string{"test", "-run", "^$", "-fuzz", "^" + fuzzName + "$", "-fuzztime", fuzzTime.String()}
+	if overlay != "" {
+		args = append(args, "-overlay", overlay)
+	}
+	cmd := exec.Command("go", append(args, ".")...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// goCommand выполняет команду go в каталоге dir и возвращает её вывод без пробелов по краям
+func goCommand(dir string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go %s: %w\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return strings.TrimSpace(string(output)), nil
+}
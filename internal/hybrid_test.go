@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// fuzzPackage создаёт модуль с исходным текстом fuzzSource
+func fuzzPackage(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/sample\n\ngo 1.22\n",
+		"main.go": fuzzSource + "\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFuzzOverlay(t *testing.T) {
+	dir := fuzzPackage(t)
+	overlay, cleanup, err := fuzzOverlay(dir, buildFunction(t, fuzzSource, "named"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("package directory has %d files, the harness must stay outside it", len(entries))
+	}
+	data, err := os.ReadFile(overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct{ Replace map[string]string }
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Replace) != 1 {
+		t.Fatalf("overlay = %s", data)
+	}
+	for target, harnessFile := range config.Replace {
+		if !filepath.IsAbs(target) || filepath.Dir(target) != dir || !strings.HasSuffix(target, "_test.go") {
+			t.Errorf("overlay target %s is not a test file of the package", target)
+		}
+		harness, err := os.ReadFile(harnessFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"package main", `"testing"`, `"time"`, "func FuzzNamed(f *testing.F)"} {
+			if !strings.Contains(string(harness), want) {
+				t.Errorf("harness misses %q:\n%s", want, harness)
+			}
+		}
+	}
+
+	if !testing.Short() {
+		list := exec.Command("go", "test", "-overlay", overlay, "-list", "Fuzz", ".")
+		list.Dir = dir
+		list.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+		output, err := list.CombinedOutput()
+		if err != nil || !strings.Contains(string(output), "FuzzNamed") {
+			t.Errorf("go test -overlay -list: %v\n%s", err, output)
+		}
+	}
+
+	cleanup()
+	if _, err := os.Stat(overlay); !os.IsNotExist(err) {
+		t.Errorf("cleanup left %s behind", overlay)
+	}
+}
+
+func TestFuzzOverlayKeepsExistingHarness(t *testing.T) {
+	dir := fuzzPackage(t)
+	existing := "package main\n\nimport \"testing\"\n\nfunc FuzzNamed(f *testing.F) {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main_test.go"), []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	overlay, cleanup, err := fuzzOverlay(dir, buildFunction(t, fuzzSource, "named"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	if overlay != "" {
+		t.Errorf("overlay = %s, the package already declares FuzzNamed", overlay)
+	}
+
+	if _, _, err := fuzzOverlay(dir, buildFunction(t, fuzzSource, "text")); err == nil {
+		t.Error("expected ErrNotFuzzable for a string parameter")
+	}
+}
+
+func TestNewCorpusEntry(t *testing.T) {
+	corpus := filepath.Join(t.TempDir(), "FuzzF")
+	if entry, err := newCorpusEntry(corpus, nil); entry != "" || err != nil {
+		t.Fatalf("missing corpus: %q, %v", entry, err)
+	}
+	if err := os.MkdirAll(filepath.Join(corpus, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(corpus, "old"), []byte(fuzzCorpusHeader), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := corpusEntries(corpus)
+	if err != nil || len(before) != 1 {
+		t.Fatalf("entries = %v, %v", before, err)
+	}
+	if entry, _ := newCorpusEntry(corpus, before); entry != "" {
+		t.Errorf("no new entries, got %s", entry)
+	}
+	if err := os.WriteFile(filepath.Join(corpus, "crash"), []byte(fuzzCorpusHeader), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := newCorpusEntry(corpus, before); entry != filepath.Join(corpus, "crash") {
+		t.Errorf("new entry = %q, want the crash file", entry)
+	}
+}
+
+const stuckSource = `package main
+
+func stuck(x, y int) int {
+	if x > 10 {
+		return 1
+	}
+	return y
+}
+`
+
+func TestTargetUncovered(t *testing.T) {
+	requireExpressions(t)
+	function := buildFunction(t, stuckSource, "stuck")
+	entry := function.Blocks[0]
+	if _, ok := entry.Instrs[len(entry.Instrs)-1].(*ssa.If); !ok {
+		t.Fatal("entry block does not end with an if")
+	}
+	then, otherwise := entry.Succs[0], entry.Succs[1]
+	greater := compare(intVar("x"), symbolic.GT, intConst(10))
+	path := func(x int64, taken *ssa.BasicBlock, branch symbolic.SymbolicExpression) Interpreter {
+		return Interpreter{
+			Blocks:         []*ssa.BasicBlock{entry, taken},
+			Branches:       []symbolic.SymbolicExpression{branch},
+			ConcreteInputs: solver.Model{"x": intConst(x), "y": intConst(5)},
+		}
+	}
+	low := path(0, otherwise, negate(greater))
+	high := path(11, then, greater)
+
+	analyser := newTestAnalyser(newFakeSolver(20))
+	inputs, err := analyser.targetUncovered([]Interpreter{low}, []Interpreter{low})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 1 {
+		t.Fatalf("%d inputs, want one for the uncovered then branch", len(inputs))
+	}
+	if x := inputs[0]["x"].(*symbolic.IntConstant).Value; x <= 10 {
+		t.Errorf("x = %d does not take the stuck branch", x)
+	}
+	if y := inputs[0]["y"].(*symbolic.IntConstant).Value; y != 5 {
+		t.Errorf("y = %d, want the value of the replayed path", y)
+	}
+
+	// Ветвь уже пройдена другим известным входом
+	if inputs, _ := analyser.targetUncovered([]Interpreter{low, high}, []Interpreter{low}); len(inputs) != 0 {
+		t.Errorf("covered branch was targeted: %v", inputs)
+	}
+	// Допущение перед ветвлением не сопоставляется *ssa.If и остаётся в префиксе
+	assumed := path(0, otherwise, negate(greater))
+	assumed.Branches = append([]symbolic.SymbolicExpression{compare(intVar("y"), symbolic.LT, intConst(0))}, assumed.Branches...)
+	assumed.Assumptions = []int{0}
+	inputs, err = analyser.targetUncovered([]Interpreter{assumed}, []Interpreter{assumed})
+	if err != nil || len(inputs) != 1 {
+		t.Fatalf("targetUncovered with an assumption = %v, %v", inputs, err)
+	}
+	if x, y := inputs[0]["x"].(*symbolic.IntConstant).Value, inputs[0]["y"].(*symbolic.IntConstant).Value; x <= 10 || y >= 0 {
+		t.Errorf("x = %d, y = %d, want the stuck branch under the assumption", x, y)
+	}
+	// Путь оборвался на ветвлении: ребро, по которому он пошёл, неизвестно
+	truncated := Interpreter{Blocks: []*ssa.BasicBlock{entry}, Branches: []symbolic.SymbolicExpression{greater}}
+	if inputs, _ := analyser.targetUncovered([]Interpreter{truncated}, []Interpreter{truncated}); len(inputs) != 0 {
+		t.Errorf("truncated path produced inputs: %v", inputs)
+	}
+}
+
+func TestHybridReportReadCorpus(t *testing.T) {
+	corpus := t.TempDir()
+	entries := map[string]string{
+		"valid":  fuzzCorpusHeader + "\nint(1)\n",
+		"header": "int(1)\n",
+		"arity":  fuzzCorpusHeader + "\nint(1)\nint(2)\n",
+	}
+	for name, data := range entries {
+		if err := os.WriteFile(filepath.Join(corpus, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var report HybridReport
+	models := report.readCorpus(corpus, []string{"x"})
+	if len(models) != 1 || models[0]["x"].(*symbolic.IntConstant).Value != 1 {
+		t.Errorf("models = %v, want the valid entry", models)
+	}
+	if len(report.CorpusErrors) != 2 {
+		t.Errorf("corpus errors = %q, want the header and arity entries", report.CorpusErrors)
+	}
+	if models := report.readCorpus(filepath.Join(corpus, "missing"), []string{"x"}); models != nil || len(report.CorpusErrors) != 2 {
+		t.Errorf("missing corpus = %v, errors %q", models, report.CorpusErrors)
+	}
+}
+
+func TestReplayResetsConcreteInputs(t *testing.T) {
+	analyser := newTestAnalyser(newFakeSolver(2))
+	func() {
+		// Паника анализа не должна оставлять конкретные входы анализатору
+		defer func() { recover() }()
+		analyser.replay("package main", "missing", []solver.Model{{"x": intConst(1)}})
+	}()
+	if analyser.ConcreteInputs != nil {
+		t.Errorf("ConcreteInputs = %v after replay", analyser.ConcreteInputs)
+	}
+}
@@ -12,10 +12,25 @@ type Memory interface {
 	AssignToArray(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression)
 
 	GetFromArray(ref *symbolic.Ref, index int) symbolic.SymbolicExpression
+
+	// Clone возвращает независимую копию памяти для нового состояния
+	Clone() Memory
+}
+
+// Merger — память, которую можно объединить при слиянии состояний
+type Merger interface {
+	// Merge возвращает новую память, в которой значения, различающиеся в
+	// этой памяти и other, выбираются выражением guard ? своё : other.
+	// ok = false, если память объединить нельзя.
+	Merge(other Memory, guard symbolic.SymbolicExpression) (merged Memory, ok bool)
 }
 
 type SymbolicMemory struct {
 	// TODO: Реализуйте внутреннее представление символьной памяти
+
+	// OnAccess вызывается при каждом чтении и записи поля или элемента массива
+	// (используется для поиска гонок данных); может быть nil
+	OnAccess func(ref *symbolic.Ref, index int, write bool)
 }
 
 func NewSymbolicMemory() SymbolicMemory {
@@ -47,3 +62,18 @@ func (mem *SymbolicMemory) GetFromArray(ref *symbolic.Ref, index int) symbolic.S
 	//TODO implement me
 	panic("implement me")
 }
+
+func (mem *SymbolicMemory) Clone() Memory {
+	//TODO implement me
+	// Записи в копию не должны быть видны в исходной памяти, и наоборот;
+	// OnAccess копируется как есть.
+	panic("implement me")
+}
+
+func (mem *SymbolicMemory) Merge(other Memory, guard symbolic.SymbolicExpression) (Memory, bool) {
+	// TODO: Реализуйте объединение памяти для слияния состояний: выделенные
+	// объекты должны совпадать, а различающиеся поля и элементы массивов —
+	// выбираться через symbolic.NewITE(guard, ...). Пока память не
+	// объединяется, и состояния с разной памятью не сливаются.
+	return nil, false
+}
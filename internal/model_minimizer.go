@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// testInputs возвращает входные данные для теста по состоянию.
+// При включённой минимизации значения подбираются наиболее читаемыми.
+func (analyser *Analyser) testInputs(interpreter Interpreter) (solver.Model, error) {
+	if !analyser.MinimizeModels {
+		return interpreter.Model, nil
+	}
+	return analyser.minimizeModel(interpreter.PathCondition, interpreter.Model)
+}
+
+// minimizeProbeLimit ограничивает число запросов к солверу на минимизацию
+// одной модели: после него оставшиеся переменные сохраняют найденные значения
+const minimizeProbeLimit = 64
+
+// NilVariable возвращает булеву переменную «указатель name равен nil»,
+// а LengthVariable — целую переменную длины коллекции name. Интерпретатор
+// описывает ими форму входных указателей, срезов и отображений, и
+// минимизация в первую очередь делает указатели nil, а коллекции пустыми.
+func NilVariable(name string) *symbolic.SymbolicVariable {
+	return symbolic.NewSymbolicVariable("nil("+name+")", symbolic.BoolType)
+}
+
+// LengthVariable возвращает переменную длины коллекции name (см. NilVariable)
+func LengthVariable(name string) *symbolic.SymbolicVariable {
+	return symbolic.NewSymbolicVariable("len("+name+")", symbolic.IntType)
+}
+
+// minimizer хранит состояние одной минимизации
+type minimizer struct {
+	analyser *Analyser
+	probes   int
+}
+
+// minimizeModel подбирает модель условия пути, в которой, если путь это
+// допускает, указатели равны nil, коллекции пусты, целые значения
+// наименьшие по модулю, а булевы ложны.
+//
+// Переменные обрабатываются по очереди (сначала переменные формы из
+// NilVariable и LengthVariable): для каждой подбирается предпочтительное
+// значение, после чего оно фиксируется, чтобы следующие переменные
+// минимизировались с учётом уже выбранных. В go-z3 нет Optimize API,
+// поэтому используется итеративное сужение с не более чем
+// minimizeProbeLimit запросами.
+func (analyser *Analyser) minimizeModel(condition symbolic.SymbolicExpression, model solver.Model) (solver.Model, error) {
+	if err := analyser.configureSolver(); err != nil {
+		return nil, err
+	}
+	backend := analyser.Solver
+	if err := backend.Push(); err != nil {
+		return nil, err
+	}
+	defer backend.Pop()
+
+	if err := backend.Assert(condition); err != nil {
+		return nil, err
+	}
+
+	current := make(solver.Model, len(model))
+	for name, value := range model {
+		current[name] = value
+	}
+
+	search := &minimizer{analyser: analyser}
+	for _, variable := range minimizationOrder(symbolic.CollectVariables(condition)) {
+		var preferred symbolic.SymbolicExpression
+		switch value := current[variable.Name].(type) {
+		case *symbolic.BoolConstant:
+			if want := isNilVariable(variable); value.Value != want {
+				preferred = symbolic.NewBinaryOperation(variable, symbolic.NewBoolConstant(want), symbolic.EQ)
+			}
+		case *symbolic.IntConstant:
+			bound, err := search.smallestBound(variable, value.Value)
+			if err != nil {
+				return nil, err
+			}
+			preferred = absBound(variable, bound)
+		default:
+			continue
+		}
+
+		if preferred != nil && search.probes < minimizeProbeLimit {
+			found, err := search.probe(preferred, current)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				if err := backend.Assert(preferred); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// Фиксируем выбранное значение перед минимизацией следующих переменных
+		if value, ok := current[variable.Name]; ok {
+			if err := backend.Assert(symbolic.NewBinaryOperation(variable, value, symbolic.EQ)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return current, nil
+}
+
+// minimizationOrder ставит переменные формы (nil-флаги, затем длины) перед
+// остальными, сохраняя их порядок
+func minimizationOrder(variables []*symbolic.SymbolicVariable) []*symbolic.SymbolicVariable {
+	rank := func(variable *symbolic.SymbolicVariable) int {
+		switch {
+		case isNilVariable(variable):
+			return 0
+		case variable.ExprType == symbolic.IntType && strings.HasPrefix(variable.Name, "len("):
+			return 1
+		default:
+			return 2
+		}
+	}
+	ordered := slices.Clone(variables)
+	slices.SortStableFunc(ordered, func(a, b *symbolic.SymbolicVariable) int {
+		return rank(a) - rank(b)
+	})
+	return ordered
+}
+
+// isNilVariable проверяет, построена ли переменная NilVariable
+func isNilVariable(variable *symbolic.SymbolicVariable) bool {
+	return variable.ExprType == symbolic.BoolType && strings.HasPrefix(variable.Name, "nil(")
+}
+
+// smallestBound находит наименьшее B, при котором путь выполним с
+// |variable| <= B. Исходное значение задаёт верхнюю границу. Сначала
+// проверяются границы 0, 1, 3, 7, ..., затем двоичный поиск между
+// последней невыполнимой и первой выполнимой: число запросов растёт с
+// логарифмом результата, а не исходного значения. Когда лимит запросов
+// исчерпан, возвращается лучшая найденная граница.
+func (search *minimizer) smallestBound(variable *symbolic.SymbolicVariable, value int64) (int64, error) {
+	high := value
+	if high < 0 {
+		high = -high
+	}
+	if high < 0 {
+		// |math.MinInt64| не представим в int64
+		high--
+	}
+
+	low := int64(0)
+	for step := int64(0); step < high; step = 2*step + 1 {
+		if search.probes >= minimizeProbeLimit {
+			return high, nil
+		}
+		found, err := search.probe(absBound(variable, step), nil)
+		if err != nil {
+			return 0, err
+		}
+		if found {
+			high = step
+			break
+		}
+		low = step + 1
+		if step > (high-1)/2 {
+			break
+		}
+	}
+	for low < high && search.probes < minimizeProbeLimit {
+		middle := low + (high-low)/2
+		found, err := search.probe(absBound(variable, middle), nil)
+		if err != nil {
+			return 0, err
+		}
+		if found {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+	return high, nil
+}
+
+// probe проверяет выполнимость текущих ограничений вместе с constraint.
+// При успехе и непустом model обновляет его найденными значениями.
+// Результат UNKNOWN считается неудачей.
+func (search *minimizer) probe(constraint symbolic.SymbolicExpression, model solver.Model) (bool, error) {
+	search.probes++
+	backend := search.analyser.Solver
+	if err := backend.Push(); err != nil {
+		return false, err
+	}
+	defer backend.Pop()
+
+	if err := backend.Assert(constraint); err != nil {
+		return false, err
+	}
+	result, err := backend.Check()
+	if err != nil || result != solver.SAT {
+		return false, err
+	}
+	if model == nil {
+		return true, nil
+	}
+
+	found, err := backend.Model()
+	if err != nil {
+		return false, err
+	}
+	for name, value := range found {
+		model[name] = value
+	}
+	return true, nil
+}
+
+// absBound строит ограничение -bound <= variable <= bound
+func absBound(variable *symbolic.SymbolicVariable, bound int64) symbolic.SymbolicExpression {
+	return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(variable, symbolic.NewIntConstant(-bound), symbolic.GE),
+		symbolic.NewBinaryOperation(variable, symbolic.NewIntConstant(bound), symbolic.LE),
+	}, symbolic.AND)
+}
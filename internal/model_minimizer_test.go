@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestMinimizeModel(t *testing.T) {
+	requireExpressions(t)
+	x, y := intVar("x"), intVar("y")
+	flag := boolVar("flag")
+	pointer, length := NilVariable("p"), LengthVariable("s")
+	tests := []struct {
+		name      string
+		condition symbolic.SymbolicExpression
+		model     solver.Model
+		want      map[string]string
+	}{
+		{"lower bound", compare(x, symbolic.GT, intConst(10)),
+			solver.Model{"x": intConst(20)}, map[string]string{"x": "11"}},
+		{"negative", compare(x, symbolic.LT, intConst(-5)),
+			solver.Model{"x": intConst(-20)}, map[string]string{"x": "-6"}},
+		{"dependent variables", compare(compare(x, symbolic.ADD, y), symbolic.EQ, intConst(7)),
+			solver.Model{"x": intConst(20), "y": intConst(-13)}, map[string]string{"x": "0", "y": "7"}},
+		{"false boolean", logical(symbolic.OR, flag, compare(x, symbolic.EQ, intConst(0))),
+			solver.Model{"flag": symbolic.NewBoolConstant(true), "x": intConst(3)}, map[string]string{"flag": "false", "x": "0"}},
+		{"nil pointer", logical(symbolic.OR, pointer, compare(x, symbolic.GT, intConst(2))),
+			solver.Model{"nil(p)": symbolic.NewBoolConstant(false), "x": intConst(9)}, map[string]string{"nil(p)": "true", "x": "0"}},
+		{"empty collection first", compare(compare(length, symbolic.ADD, x), symbolic.GE, intConst(4)),
+			solver.Model{"len(s)": intConst(9), "x": intConst(1)}, map[string]string{"len(s)": "0", "x": "4"}},
+		{"value forced by path", compare(x, symbolic.EQ, intConst(17)),
+			solver.Model{"x": intConst(17)}, map[string]string{"x": "17"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyser := newTestAnalyser(newFakeSolver(24))
+			model, err := analyser.minimizeModel(test.condition, test.model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range test.want {
+				if got := model[name]; got == nil || got.String() != want {
+					t.Errorf("%s = %v, want %s", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMinimizeModelProbeLimit(t *testing.T) {
+	requireExpressions(t)
+	backend := newFakeSolver(0)
+	backend.unknown = true
+	analyser := newTestAnalyser(backend)
+
+	var variables []symbolic.SymbolicExpression
+	model := solver.Model{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		variables = append(variables, compare(intVar(name), symbolic.NE, intConst(0)))
+		model[name] = intConst(1 << 60)
+	}
+	got, err := analyser.minimizeModel(logical(symbolic.AND, variables...), model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.checks > minimizeProbeLimit {
+		t.Errorf("minimisation made %d solver queries, limit is %d", backend.checks, minimizeProbeLimit)
+	}
+	for name, value := range model {
+		if got[name].String() != value.String() {
+			t.Errorf("%s changed to %s although no probe succeeded", name, got[name])
+		}
+	}
+}
+
+func TestMinimizationOrder(t *testing.T) {
+	variables := []*symbolic.SymbolicVariable{
+		intVar("x"), LengthVariable("s"), boolVar("b"), NilVariable("p"), intVar("len"), LengthVariable("t"),
+	}
+	var names []string
+	for _, variable := range minimizationOrder(variables) {
+		names = append(names, variable.Name)
+	}
+	want := []string{"nil(p)", "len(s)", "len(t)", "x", "b", "len"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("minimizationOrder = %v, want %v", names, want)
+		}
+	}
+}
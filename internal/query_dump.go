@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+)
+
+// dumpQuery сохраняет запрос к солверу в файл query_NNNNNN.smt2,
+// чтобы тяжёлые запросы можно было воспроизвести вне анализатора
+func (analyser *Analyser) dumpQuery(conditions ...symbolic.SymbolicExpression) error {
+	if analyser.QueryDumpDir == "" {
+		return nil
+	}
+
+	script, err := translator.NewSMTLibTranslator().Script(conditions...)
+	if err != nil {
+		return fmt.Errorf("failed to render query: %w", err)
+	}
+
+	if err := os.MkdirAll(analyser.QueryDumpDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create query dump directory: %w", err)
+	}
+
+	analyser.queryCounter++
+	path := filepath.Join(analyser.QueryDumpDir, fmt.Sprintf("query_%06d.smt2", analyser.queryCounter))
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		return fmt.Errorf("failed to write query %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"fmt"
+	"go/types"
+	"maps"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// WithRaceDetection включает поиск гонок данных между горутинами
+func WithRaceDetection() Option {
+	return func(analyser *Analyser) {
+		analyser.DetectRaces = true
+	}
+}
+
+// VectorClock — векторные часы: i-й элемент равен числу событий
+// синхронизации горутины i, известных владельцу часов.
+// Операции не изменяют часы, а возвращают новые.
+type VectorClock []int
+
+// get возвращает компоненту часов горутины
+func (vc VectorClock) get(goroutine int) int {
+	if goroutine < len(vc) {
+		return vc[goroutine]
+	}
+	return 0
+}
+
+// tick увеличивает компоненту часов горутины
+func (vc VectorClock) tick(goroutine int) VectorClock {
+	ticked := slices.Clone(vc)
+	if goroutine >= len(ticked) {
+		ticked = append(ticked, make([]int, goroutine-len(ticked)+1)...)
+	}
+	ticked[goroutine]++
+	return ticked
+}
+
+// join возвращает покомпонентный максимум часов
+func (vc VectorClock) join(other VectorClock) VectorClock {
+	joined := slices.Clone(vc)
+	if len(other) > len(joined) {
+		joined = append(joined, make([]int, len(other)-len(joined))...)
+	}
+	for i, value := range other {
+		joined[i] = max(joined[i], value)
+	}
+	return joined
+}
+
+// access — обращение к ячейке памяти: горутина, её часы в момент
+// обращения и инструкция
+type access struct {
+	goroutine   int
+	epoch       int
+	instruction ssa.Instruction
+}
+
+// happensBefore проверяет, предшествует ли обращение событиям с часами clock
+func (a access) happensBefore(clock VectorClock) bool {
+	return a.epoch <= clock.get(a.goroutine)
+}
+
+// shadowCell хранит последнюю запись в ячейку и последние чтения каждой горутины
+type shadowCell struct {
+	write *access
+	reads map[int]access
+}
+
+func (cell *shadowCell) clone() *shadowCell {
+	return &shadowCell{write: cell.write, reads: maps.Clone(cell.reads)}
+}
+
+// release возвращает часы текущей горутины для передачи получателю
+// и начинает её новую эпоху
+func (interpreter *Interpreter) release() VectorClock {
+	goroutine := interpreter.currentGoroutine()
+	clock := goroutine.Clock
+	goroutine.Clock = clock.tick(goroutine.ID)
+	return clock
+}
+
+// acquire упорядочивает текущую горутину после событий с часами clock
+func (interpreter *Interpreter) acquire(clock VectorClock) {
+	goroutine := interpreter.currentGoroutine()
+	goroutine.Clock = goroutine.Clock.join(clock)
+}
+
+// recordAccess отмечает чтение или запись ячейки памяти location текущей
+// горутиной и сообщает о гонке с предыдущими обращениями, не упорядоченными
+// с текущим отношением happens-before. Вызывается при обращениях к
+// memory.SymbolicMemory (например, из SymbolicMemory.OnAccess); location
+// однозначно задаёт ячейку, например адрес объекта и номер поля или индекс
+// элемента.
+func (interpreter *Interpreter) recordAccess(location string, write bool, instruction ssa.Instruction) {
+	scheduler := interpreter.Scheduler
+	if !interpreter.Analyser.DetectRaces || scheduler == nil {
+		return
+	}
+	if scheduler.shadow == nil {
+		scheduler.shadow = make(map[string]*shadowCell)
+	}
+	cell, ok := scheduler.shadow[location]
+	if !ok {
+		cell = &shadowCell{reads: make(map[int]access)}
+		scheduler.shadow[location] = cell
+	}
+
+	goroutine := interpreter.currentGoroutine()
+	current := access{goroutine: goroutine.ID, epoch: goroutine.Clock.get(goroutine.ID), instruction: instruction}
+	if cell.write != nil && cell.write.goroutine != goroutine.ID && !cell.write.happensBefore(goroutine.Clock) {
+		interpreter.reportRace(location, *cell.write, current, write)
+	}
+	if !write {
+		cell.reads[goroutine.ID] = current
+		return
+	}
+	for _, read := range cell.reads {
+		if read.goroutine != goroutine.ID && !read.happensBefore(goroutine.Clock) {
+			interpreter.reportRace(location, read, current, true)
+		}
+	}
+	cell.write = &current
+	cell.reads = make(map[int]access)
+}
+
+// reportRace сообщает о гонке между обращениями previous и current
+func (interpreter *Interpreter) reportRace(location string, previous, current access, write bool) {
+	kind := "read"
+	if write {
+		kind = "write"
+	}
+	message := fmt.Sprintf("%s of %s at %s by goroutine %d races with access at %s by goroutine %d",
+		kind, location, position(current.instruction), current.goroutine,
+		position(previous.instruction), previous.goroutine)
+	// Гонка в цикле сообщается один раз
+	for _, finding := range interpreter.Findings {
+		if finding.Kind == DataRace && finding.Message == message {
+			return
+		}
+	}
+	interpreter.report(DataRace, message)
+}
+
+// position возвращает позицию инструкции в исходном коде
+func position(instruction ssa.Instruction) string {
+	if instruction == nil {
+		return "?"
+	}
+	if pos := instruction.Pos(); pos.IsValid() {
+		return instruction.Parent().Prog.Fset.Position(pos).String()
+	}
+	return instruction.String()
+}
+
+// Mutex — модель sync.Mutex: канал ёмкости 1, Lock — отправка в канал,
+// Unlock — получение из него. Released — часы последнего Unlock.
+type Mutex struct {
+	Channel  symbolic.SymbolicExpression
+	Released VectorClock
+}
+
+// mutex возвращает модель мьютекса по указателю на него
+func (interpreter *Interpreter) mutex(receiver symbolic.SymbolicExpression) Mutex {
+	scheduler := interpreter.scheduler()
+	key := receiver.String()
+	if mutex, ok := scheduler.Mutexes[key]; ok {
+		return mutex
+	}
+	mutex := Mutex{Channel: interpreter.makeChan(types.Typ[types.Bool], 1)}
+	if scheduler.Mutexes == nil {
+		scheduler.Mutexes = make(map[string]Mutex)
+	}
+	scheduler.Mutexes[key] = mutex
+	return mutex
+}
+
+// blocked проверяет, заблокировалась ли текущая горутина. Обработчик вызова
+// проверяет его после модели (например, Lock занятого мьютекса) и тогда
+// возвращает schedule().
+func (interpreter *Interpreter) blocked() bool {
+	return interpreter.Scheduler != nil && interpreter.currentGoroutine().Status == Blocked
+}
+
+// modelMutexLock: (*sync.Mutex).Lock блокирует горутину, пока мьютекс занят
+func modelMutexLock(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected receiver of Lock")
+	}
+	mutex := interpreter.mutex(args[0])
+	done, err := interpreter.send(mutex.Channel, symbolic.NewBoolConstant(true))
+	if done {
+		// Захват мьютекса упорядочен после его последнего освобождения
+		interpreter.acquire(mutex.Released)
+	}
+	return nil, err
+}
+
+// modelMutexUnlock: (*sync.Mutex).Unlock освобождает мьютекс;
+// освобождение свободного мьютекса — фатальная ошибка
+func modelMutexUnlock(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected receiver of Unlock")
+	}
+	mutex := interpreter.mutex(args[0])
+	channel, err := interpreter.channel(mutex.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if len(channel.Buffer) == 0 {
+		interpreter.panicGoroutine("fatal error: sync: unlock of unlocked mutex")
+		return nil, nil
+	}
+	if _, _, err := interpreter.receive(mutex.Channel); err != nil {
+		return nil, err
+	}
+	mutex.Released = interpreter.release()
+	interpreter.Scheduler.Mutexes[args[0].String()] = mutex
+	return nil, nil
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestVectorClock(t *testing.T) {
+	clock := VectorClock{1, 2}
+	if ticked := clock.tick(3); !slices.Equal(ticked, VectorClock{1, 2, 0, 1}) {
+		t.Errorf("tick(3) = %v", ticked)
+	}
+	if !slices.Equal(clock, VectorClock{1, 2}) {
+		t.Errorf("tick changed the receiver: %v", clock)
+	}
+	if joined := clock.join(VectorClock{3, 0, 5}); !slices.Equal(joined, VectorClock{3, 2, 5}) {
+		t.Errorf("join = %v", joined)
+	}
+	if clock.get(0) != 1 || clock.get(7) != 0 {
+		t.Errorf("get = %d, %d", clock.get(0), clock.get(7))
+	}
+	earlier := access{goroutine: 1, epoch: 2}
+	if !earlier.happensBefore(clock) || earlier.happensBefore(VectorClock{9, 1}) {
+		t.Error("happensBefore does not compare the goroutine's epoch")
+	}
+}
+
+// twoGoroutines возвращает состояние с главной горутиной и работником.
+// beforeSpawn выполняется главной горутиной до инструкции go.
+func twoGoroutines(t *testing.T, detect bool, beforeSpawn func(*Interpreter)) Interpreter {
+	t.Helper()
+	options := []Option{}
+	if detect {
+		options = append(options, WithRaceDetection())
+	}
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4), options...)}
+	interpreter.scheduler()
+	if beforeSpawn != nil {
+		beforeSpawn(&interpreter)
+	}
+	interpreter.spawn(buildFunction(t, workerSource, "worker"), nil)
+	return interpreter
+}
+
+func races(interpreter Interpreter) int {
+	count := 0
+	for _, finding := range interpreter.Findings {
+		if finding.Kind == DataRace {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRecordAccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		detect bool
+		before func(*Interpreter)
+		main   func(*Interpreter)
+		worker func(*Interpreter)
+		races  int
+	}{
+		{"concurrent writes", true, nil,
+			func(i *Interpreter) { i.recordAccess("x", true, nil) },
+			func(i *Interpreter) { i.recordAccess("x", true, nil) }, 1},
+		{"read after concurrent write", true, nil,
+			func(i *Interpreter) { i.recordAccess("x", true, nil) },
+			func(i *Interpreter) { i.recordAccess("x", false, nil) }, 1},
+		{"write after concurrent read", true, nil,
+			func(i *Interpreter) { i.recordAccess("x", false, nil) },
+			func(i *Interpreter) { i.recordAccess("x", true, nil) }, 1},
+		{"concurrent reads", true, nil,
+			func(i *Interpreter) { i.recordAccess("x", false, nil) },
+			func(i *Interpreter) { i.recordAccess("x", false, nil) }, 0},
+		{"different cells", true, nil,
+			func(i *Interpreter) { i.recordAccess("x", true, nil) },
+			func(i *Interpreter) { i.recordAccess("y", true, nil) }, 0},
+		{"write before go", true,
+			func(i *Interpreter) { i.recordAccess("x", true, nil) },
+			func(i *Interpreter) {},
+			func(i *Interpreter) { i.recordAccess("x", true, nil) }, 0},
+		{"race reported once", true, nil,
+			func(i *Interpreter) { i.recordAccess("x", true, nil) },
+			func(i *Interpreter) {
+				i.recordAccess("x", true, nil)
+				i.recordAccess("x", true, nil)
+			}, 1},
+		{"detection disabled", false, nil,
+			func(i *Interpreter) { i.recordAccess("x", true, nil) },
+			func(i *Interpreter) { i.recordAccess("x", true, nil) }, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			interpreter := twoGoroutines(t, test.detect, test.before)
+			test.main(&interpreter)
+			interpreter.switchTo(1, false)
+			test.worker(&interpreter)
+			if got := races(interpreter); got != test.races {
+				t.Errorf("reported %d races, want %d: %+v", got, test.races, interpreter.Findings)
+			}
+		})
+	}
+}
+
+func TestMutexOrdersAccesses(t *testing.T) {
+	interpreter := twoGoroutines(t, true, nil)
+	mu := []symbolic.SymbolicExpression{intVar("mu")}
+
+	for _, goroutine := range []int{0, 1} {
+		interpreter.switchTo(goroutine, false)
+		if _, err := modelMutexLock(&interpreter, nil, mu); err != nil || interpreter.blocked() {
+			t.Fatalf("goroutine %d: Lock of a free mutex = %v, blocked %t", goroutine, err, interpreter.blocked())
+		}
+		interpreter.recordAccess("x", true, nil)
+		if _, err := modelMutexUnlock(&interpreter, nil, mu); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := races(interpreter); got != 0 {
+		t.Errorf("accesses under a mutex reported %d races: %+v", got, interpreter.Findings)
+	}
+}
+
+func TestMutexLockBlocks(t *testing.T) {
+	interpreter := twoGoroutines(t, true, nil)
+	mu := []symbolic.SymbolicExpression{intVar("mu")}
+	if _, err := modelMutexLock(&interpreter, nil, mu); err != nil {
+		t.Fatal(err)
+	}
+	interpreter.switchTo(1, false)
+	if _, err := modelMutexLock(&interpreter, nil, mu); err != nil || !interpreter.blocked() {
+		t.Errorf("Lock of a held mutex = %v, blocked %t", err, interpreter.blocked())
+	}
+}
+
+func TestMutexUnlockOfUnlocked(t *testing.T) {
+	interpreter := twoGoroutines(t, true, nil)
+	if _, err := modelMutexUnlock(&interpreter, nil, []symbolic.SymbolicExpression{intVar("mu")}); err != nil {
+		t.Fatal(err)
+	}
+	if !interpreter.Terminated || interpreter.Findings[0].Kind != GoroutinePanic {
+		t.Errorf("findings = %+v, want a fatal unlock error", interpreter.Findings)
+	}
+}
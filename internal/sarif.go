@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReportedFinding — ошибка вместе с функцией и входными данными, её вызывающими
+type ReportedFinding struct {
+	Package  string            `json:"package"`
+	File     string            `json:"file"`
+	Function string            `json:"function"`
+	Inputs   map[string]string `json:"inputs"`
+	Finding
+}
+
+// Структуры формата SARIF 2.1.0 (только используемые поля)
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name  string      `json:"name"`
+		Rules []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+		CodeFlows []sarifCodeFlow `json:"codeFlows,omitempty"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+		Message          *sarifMessage         `json:"message,omitempty"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+	sarifCodeFlow struct {
+		ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+	}
+	sarifThreadFlow struct {
+		Locations []sarifThreadFlowLocation `json:"locations"`
+	}
+	sarifThreadFlowLocation struct {
+		Location sarifLocation `json:"location"`
+	}
+)
+
+// ruleDescriptions — описания правил SARIF для видов ошибок
+var ruleDescriptions = map[FindingKind]string{
+	Deadlock:               "All goroutines are blocked",
+	SendOnClosedChannel:    "Send on a closed channel",
+	CloseOfClosedChannel:   "Close of a closed channel",
+	GoroutinePanic:         "Unrecovered panic",
+	DataRace:               "Unsynchronised concurrent memory accesses",
+	AssertionViolation:     "spec.Assert condition can be violated",
+	PreconditionViolation:  "//sym:requires precondition can be violated",
+	PostconditionViolation: "//sym:ensures postcondition can be violated",
+}
+
+// WriteSARIF выводит ошибки в формате SARIF 2.1.0: позиция ошибки —
+// расположение результата, входные данные — в сообщении, исполненный
+// путь — codeFlow. Пути файлов записываются относительно baseDir.
+func WriteSARIF(w io.Writer, baseDir string, findings []ReportedFinding) error {
+	var rules []sarifRule
+	ruleIndex := make(map[FindingKind]int)
+	for kind := Deadlock; kind.String() != "unknown"; kind++ {
+		ruleIndex[kind] = len(rules)
+		rules = append(rules, sarifRule{ID: kind.String(), ShortDescription: sarifMessage{Text: ruleDescriptions[kind]}})
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		result := sarifResult{
+			RuleID:    finding.Kind.String(),
+			RuleIndex: ruleIndex[finding.Kind],
+			Level:     "error",
+			Message: sarifMessage{Text: fmt.Sprintf("%s: %s (inputs: %s)",
+				finding.Function, finding.Message, FormatInputs(finding.Inputs))},
+		}
+		if finding.Kind == DataRace {
+			result.Level = "warning"
+		}
+
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: sarifURI(baseDir, finding.File)},
+		}}
+		if finding.Location != nil {
+			location = sarifLocationOf(baseDir, *finding.Location, "")
+		}
+		result.Locations = []sarifLocation{location}
+
+		if len(finding.Path) > 0 {
+			flow := sarifThreadFlow{}
+			for i, step := range finding.Path {
+				message := ""
+				if i == len(finding.Path)-1 {
+					message = finding.Message
+				}
+				flow.Locations = append(flow.Locations, sarifThreadFlowLocation{Location: sarifLocationOf(baseDir, step, message)})
+			}
+			result.CodeFlows = []sarifCodeFlow{{ThreadFlows: []sarifThreadFlow{flow}}}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "symgo", Rules: rules}},
+			Results: results,
+		}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+func sarifLocationOf(baseDir string, location Location, message string) sarifLocation {
+	result := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: sarifURI(baseDir, location.File)},
+		Region:           &sarifRegion{StartLine: location.Line, StartColumn: location.Column},
+	}}
+	if message != "" {
+		result.Message = &sarifMessage{Text: message}
+	}
+	return result
+}
+
+// sarifURI возвращает путь файла относительно baseDir, если он внутри него
+func sarifURI(baseDir, path string) string {
+	if baseDir != "" {
+		if relative, err := filepath.Rel(baseDir, path); err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			path = relative
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// FormatInputs выводит входные данные в порядке имён
+func FormatInputs(inputs map[string]string) string {
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + inputs[name]
+	}
+	return strings.Join(parts, ", ")
+}
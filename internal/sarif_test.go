@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	base := filepath.Join("/work", "project")
+	file := filepath.Join(base, "pkg", "div.go")
+	findings := []ReportedFinding{
+		{
+			Package:  "example/pkg",
+			File:     file,
+			Function: "Div",
+			Inputs:   map[string]string{"b": "0", "a": "1"},
+			Finding: Finding{
+				Kind:     GoroutinePanic,
+				Message:  DivideByZeroMessage,
+				Location: &Location{File: file, Line: 4, Column: 11},
+				Path:     []Location{{File: file, Line: 3, Column: 2}, {File: file, Line: 4, Column: 11}},
+			},
+		},
+		{Package: "example/pkg", File: "/elsewhere/race.go", Function: "Race", Finding: Finding{Kind: DataRace, Message: "race on x"}},
+	}
+
+	var output bytes.Buffer
+	if err := WriteSARIF(&output, base, findings); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(output.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("log = %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(ruleDescriptions) {
+		t.Errorf("%d rules, want %d", len(run.Tool.Driver.Rules), len(ruleDescriptions))
+	}
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.ShortDescription.Text == "" {
+			t.Errorf("rule %s has no description", rule.ID)
+		}
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("%d results, want 2", len(run.Results))
+	}
+
+	panicked := run.Results[0]
+	if rule := run.Tool.Driver.Rules[panicked.RuleIndex]; rule.ID != panicked.RuleID || panicked.RuleID != GoroutinePanic.String() {
+		t.Errorf("rule %s at index %d, result refers to %s", rule.ID, panicked.RuleIndex, panicked.RuleID)
+	}
+	if want := "Div: " + DivideByZeroMessage + " (inputs: a=1, b=0)"; panicked.Message.Text != want {
+		t.Errorf("message = %q, want %q", panicked.Message.Text, want)
+	}
+	location := panicked.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "pkg/div.go" || location.Region == nil || location.Region.StartLine != 4 || location.Region.StartColumn != 11 {
+		t.Errorf("location = %+v, region %+v", location.ArtifactLocation, location.Region)
+	}
+	if len(panicked.CodeFlows) != 1 {
+		t.Fatalf("%d code flows, want 1", len(panicked.CodeFlows))
+	}
+	steps := panicked.CodeFlows[0].ThreadFlows[0].Locations
+	if len(steps) != 2 || steps[0].Location.Message != nil || steps[1].Location.Message == nil || steps[1].Location.Message.Text != DivideByZeroMessage {
+		t.Errorf("code flow = %+v", steps)
+	}
+
+	race := run.Results[1]
+	if race.Level != "warning" || race.CodeFlows != nil {
+		t.Errorf("race result = %+v", race)
+	}
+	if location := race.Locations[0].PhysicalLocation; location.ArtifactLocation.URI != "/elsewhere/race.go" || location.Region != nil {
+		t.Errorf("race location = %+v", location)
+	}
+}
+
+func TestWriteSARIFWithoutFindings(t *testing.T) {
+	var output bytes.Buffer
+	if err := WriteSARIF(&output, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(output.Bytes(), []byte(`"results": []`)) {
+		t.Errorf("empty run must list an empty results array:\n%s", output.String())
+	}
+}
+
+func TestSarifURI(t *testing.T) {
+	tests := []struct {
+		base, path, want string
+	}{
+		{"/a", "/a/b/c.go", "b/c.go"},
+		{"/a", "/ab/c.go", "/ab/c.go"},
+		{"/a/b", "/a/c.go", "/a/c.go"},
+		{"", "/a/c.go", "/a/c.go"},
+	}
+	for _, test := range tests {
+		if got := sarifURI(test.base, test.path); got != test.want {
+			t.Errorf("sarifURI(%q, %q) = %q, want %q", test.base, test.path, got, test.want)
+		}
+	}
+}
+
+func TestStepKeepsOneInstructionPerLine(t *testing.T) {
+	function := buildFunction(t, `package main
+
+func f(x int) int {
+	y := x + 1; z := y * 2
+	return z
+}
+`, "f")
+	interpreter := Interpreter{}
+	for _, block := range function.Blocks {
+		for _, instruction := range block.Instrs {
+			if _, debug := instruction.(*ssa.DebugRef); !debug {
+				interpreter.step(instruction)
+			}
+		}
+	}
+	interpreter.report(GoroutinePanic, "boom")
+
+	finding := interpreter.Findings[0]
+	if len(finding.Path) != 2 || finding.Path[0].Line != 4 || finding.Path[1].Line != 5 {
+		t.Fatalf("path = %v, want lines 4 and 5", finding.Path)
+	}
+	if finding.Location == nil || *finding.Location != finding.Path[1] {
+		t.Errorf("location = %v, want the last step", finding.Location)
+	}
+}
+
+func TestReportLocatesFaultingInstruction(t *testing.T) {
+	function := buildFunction(t, `package main
+
+func f(a, b, c int) int {
+	return a / (b - c)
+}
+`, "f")
+	interpreter := Interpreter{}
+	for _, instruction := range function.Blocks[0].Instrs {
+		if _, debug := instruction.(*ssa.DebugRef); debug {
+			continue
+		}
+		interpreter.step(instruction)
+		if binary, ok := instruction.(*ssa.BinOp); ok && binary.Op == token.QUO {
+			interpreter.report(GoroutinePanic, DivideByZeroMessage)
+			break
+		}
+	}
+
+	// Первая инструкция строки — вычитание, ошибка указывает на деление
+	finding := interpreter.Findings[0]
+	if len(finding.Path) != 1 || finding.Path[0].Column != 16 {
+		t.Fatalf("path = %v, want the subtraction at 4:16", finding.Path)
+	}
+	if finding.Location == nil || finding.Location.Line != 4 || finding.Location.Column != 11 {
+		t.Errorf("location = %v, want the division at 4:11", finding.Location)
+	}
+}
@@ -0,0 +1,58 @@
+// Package solver определяет интерфейс SMT солвера, не зависящий от конкретной реализации
+package solver
+
+import (
+	"time"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/pkg/z3wrapper"
+)
+
+// SolverBackend — SMT солвер, принимающий символьные выражения.
+// Реализации: Z3Backend (библиотека go-z3) и ProcessBackend (внешний
+// бинарный файл солвера, общающийся по SMT-LIB2 через stdin/stdout).
+type SolverBackend interface {
+	// Name возвращает имя солвера для отчётов и сравнения
+	Name() string
+
+	// Assert добавляет ограничение на текущий уровень стека
+	Assert(constraint symbolic.SymbolicExpression) error
+
+	// Check проверяет выполнимость всех добавленных ограничений.
+	// Ошибка означает сбой солвера или трансляции, а не результат UNKNOWN.
+	Check() (Result, error)
+
+	// ReasonUnknown возвращает причину последнего результата UNKNOWN
+	ReasonUnknown() string
+
+	// Push сохраняет текущий набор ограничений
+	Push() error
+
+	// Pop восстанавливает набор ограничений, сохранённый последним Push
+	Pop() error
+
+	// Model возвращает значения переменных после успешного Check
+	Model() (Model, error)
+
+	// SetTimeout задаёт ограничение времени на один Check (0 — без ограничения)
+	SetTimeout(timeout time.Duration) error
+
+	// SetResourceLimit задаёт детерминированный лимит ресурсов на один Check (0 — без ограничения)
+	SetResourceLimit(limit uint) error
+
+	// Close освобождает ресурсы солвера
+	Close() error
+}
+
+// Model отображает имя символьной переменной в её конкретное значение
+// (IntConstant или BoolConstant)
+type Model map[string]symbolic.SymbolicExpression
+
+// Result — результат проверки выполнимости: SAT, UNSAT или UNKNOWN
+type Result = z3wrapper.Result
+
+const (
+	UNSAT   = z3wrapper.UNSAT
+	SAT     = z3wrapper.SAT
+	UNKNOWN = z3wrapper.UNKNOWN
+)
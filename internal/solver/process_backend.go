@@ -0,0 +1,403 @@
+package solver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+)
+
+// ProcessConfig описывает запуск внешнего SMT-LIB2 солвера
+type ProcessConfig struct {
+	// Name — имя солвера в отчётах
+	Name string
+	// Command и Args — команда запуска в интерактивном режиме чтения из stdin
+	Command string
+	Args    []string
+	// Preamble — команды, отправляемые сразу после запуска (например, set-logic)
+	Preamble []string
+	// TimeoutOption — имя опции SMT-LIB2 для тайм-аута одного check-sat в миллисекундах
+	TimeoutOption string
+	// ResourceLimitOption — имя опции SMT-LIB2 для лимита ресурсов одного check-sat
+	ResourceLimitOption string
+	// BitVectorWidth — ширина битовых векторов для целых чисел (0 — сорт Int)
+	BitVectorWidth int
+}
+
+// Конфигурации для распространённых солверов, установленных локально
+var (
+	Z3Process = ProcessConfig{
+		Name:                "z3",
+		Command:             "z3",
+		Args:                []string{"-in", "-smt2"},
+		TimeoutOption:       "timeout",
+		ResourceLimitOption: "rlimit",
+	}
+	CVC5 = ProcessConfig{
+		Name:                "cvc5",
+		Command:             "cvc5",
+		Args:                []string{"--lang=smt2", "--incremental"},
+		Preamble:            []string{"(set-logic ALL)"},
+		TimeoutOption:       "tlimit-per",
+		ResourceLimitOption: "rlimit-per",
+	}
+	Bitwuzla = ProcessConfig{
+		Name:           "bitwuzla",
+		Command:        "bitwuzla",
+		Args:           []string{"--lang", "smt2"},
+		Preamble:       []string{"(set-logic QF_ABV)"},
+		TimeoutOption:  "time-limit-per",
+		BitVectorWidth: 64,
+	}
+)
+
+// ProcessBackend реализует SolverBackend, общаясь с внешним солвером
+// по протоколу SMT-LIB2 через stdin/stdout
+type ProcessBackend struct {
+	config     ProcessConfig
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	output     *sexprReader
+	stderr     lockedBuffer
+	translator *translator.SMTLibTranslator
+	types      map[string]symbolic.ExpressionType // Типы объявленных переменных
+	scopes     [][]string                         // Переменные, объявленные на каждом уровне стека
+	reason     string                             // Причина последнего результата unknown
+	waitOnce   sync.Once
+	waitErr    error
+}
+
+// NewProcessBackend запускает солвер и подготавливает его к работе
+func NewProcessBackend(config ProcessConfig) (*ProcessBackend, error) {
+	backend := &ProcessBackend{
+		config:     config,
+		translator: translator.NewSMTLibBitVectorTranslator(config.BitVectorWidth),
+		types:      make(map[string]symbolic.ExpressionType),
+		scopes:     make([][]string, 1),
+	}
+
+	backend.cmd = exec.Command(config.Command, config.Args...)
+	backend.cmd.Stderr = &backend.stderr
+	stdin, err := backend.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := backend.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", config.Name, err)
+	}
+	backend.stdin = stdin
+	backend.output = newSExprReader(stdout)
+
+	// После print-success каждая команда получает ответ, что позволяет
+	// читать вывод солвера синхронно с отправкой команд
+	if _, err := backend.command("(set-option :print-success true)"); err != nil {
+		_ = backend.Close()
+		return nil, err
+	}
+	for _, command := range append([]string{"(set-option :produce-models true)"}, config.Preamble...) {
+		if err := backend.expectSuccess(command); err != nil {
+			_ = backend.Close()
+			return nil, err
+		}
+	}
+	return backend, nil
+}
+
+// lockedBuffer собирает stderr солвера: в него пишет горутина exec,
+// а читает command при ошибке
+type lockedBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buffer.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buffer.String()
+}
+
+// Name возвращает имя солвера
+func (pb *ProcessBackend) Name() string {
+	return pb.config.Name
+}
+
+// Assert объявляет новые переменные и добавляет ограничение
+func (pb *ProcessBackend) Assert(constraint symbolic.SymbolicExpression) error {
+	term, err := pb.translator.TranslateExpression(constraint)
+	if err != nil {
+		return err
+	}
+
+	for _, variable := range symbolic.CollectVariables(constraint) {
+		if _, declared := pb.types[variable.Name]; declared {
+			continue
+		}
+		declaration, _ := pb.translator.Declaration(variable.Name)
+		if err := pb.expectSuccess(declaration); err != nil {
+			return err
+		}
+		pb.types[variable.Name] = variable.ExprType
+		top := len(pb.scopes) - 1
+		pb.scopes[top] = append(pb.scopes[top], variable.Name)
+	}
+
+	return pb.expectSuccess(fmt.Sprintf("(assert %s)", term))
+}
+
+// Check проверяет выполнимость ограничений
+func (pb *ProcessBackend) Check() (Result, error) {
+	pb.reason = ""
+	response, err := pb.command("(check-sat)")
+	if err != nil {
+		return UNKNOWN, err
+	}
+	switch response.Atom {
+	case "sat":
+		return SAT, nil
+	case "unsat":
+		return UNSAT, nil
+	case "unknown":
+		pb.reason = pb.queryReasonUnknown()
+		return UNKNOWN, nil
+	default:
+		return UNKNOWN, fmt.Errorf("%s: unexpected check-sat response %s", pb.config.Name, response)
+	}
+}
+
+// ReasonUnknown возвращает причину последнего результата UNKNOWN
+func (pb *ProcessBackend) ReasonUnknown() string {
+	return pb.reason
+}
+
+// Push сохраняет текущий набор ограничений
+func (pb *ProcessBackend) Push() error {
+	if err := pb.expectSuccess("(push 1)"); err != nil {
+		return err
+	}
+	pb.scopes = append(pb.scopes, nil)
+	return nil
+}
+
+// Pop восстанавливает предыдущий набор ограничений и забывает
+// переменные, объявленные после соответствующего Push
+func (pb *ProcessBackend) Pop() error {
+	if len(pb.scopes) == 1 {
+		return fmt.Errorf("pop without matching push")
+	}
+	if err := pb.expectSuccess("(pop 1)"); err != nil {
+		return err
+	}
+	for _, name := range pb.scopes[len(pb.scopes)-1] {
+		delete(pb.types, name)
+	}
+	pb.scopes = pb.scopes[:len(pb.scopes)-1]
+	return nil
+}
+
+// Model запрашивает значения объявленных скалярных переменных через get-value
+func (pb *ProcessBackend) Model() (Model, error) {
+	var names []string
+	for _, scope := range pb.scopes {
+		for _, name := range scope {
+			if pb.types[name] != symbolic.ArrayType {
+				names = append(names, name)
+			}
+		}
+	}
+
+	model := make(Model)
+	if len(names) == 0 {
+		return model, nil
+	}
+
+	terms := make([]string, 0, len(names))
+	for _, name := range names {
+		term, err := pb.translator.TranslateExpression(symbolic.NewSymbolicVariable(name, pb.types[name]))
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term.(string))
+	}
+	response, err := pb.command(fmt.Sprintf("(get-value (%s))", strings.Join(terms, " ")))
+	if err != nil {
+		return nil, err
+	}
+
+	// Ответ get-value перечисляет значения в порядке запроса, поэтому имена
+	// берутся из запроса, а не восстанавливаются из экранированных символов
+	if len(response.List) != len(names) {
+		return nil, fmt.Errorf("%s: get-value returned %d values for %d variables", pb.config.Name, len(response.List), len(names))
+	}
+	for i, pair := range response.List {
+		if len(pair.List) != 2 {
+			return nil, fmt.Errorf("%s: malformed get-value entry %s", pb.config.Name, pair)
+		}
+		name := names[i]
+		value, err := parseValue(pair.List[1], pb.types[name])
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", name, err)
+		}
+		model[name] = value
+	}
+	return model, nil
+}
+
+// SetTimeout задаёт ограничение времени на один check-sat
+func (pb *ProcessBackend) SetTimeout(timeout time.Duration) error {
+	if pb.config.TimeoutOption == "" {
+		return fmt.Errorf("%s does not support timeouts", pb.config.Name)
+	}
+	return pb.expectSuccess(fmt.Sprintf("(set-option :%s %d)", pb.config.TimeoutOption, timeout.Milliseconds()))
+}
+
+// SetResourceLimit задаёт лимит ресурсов на один check-sat
+func (pb *ProcessBackend) SetResourceLimit(limit uint) error {
+	if pb.config.ResourceLimitOption == "" {
+		return fmt.Errorf("%s does not support resource limits", pb.config.Name)
+	}
+	return pb.expectSuccess(fmt.Sprintf("(set-option :%s %d)", pb.config.ResourceLimitOption, limit))
+}
+
+// Close завершает процесс солвера
+func (pb *ProcessBackend) Close() error {
+	_, _ = io.WriteString(pb.stdin, "(exit)\n")
+	return pb.wait()
+}
+
+// wait закрывает stdin солвера и дожидается его завершения, после
+// которого stderr прочитан полностью
+func (pb *ProcessBackend) wait() error {
+	pb.waitOnce.Do(func() {
+		_ = pb.stdin.Close()
+		pb.waitErr = pb.cmd.Wait()
+	})
+	return pb.waitErr
+}
+
+// command отправляет команду и читает ответ солвера
+func (pb *ProcessBackend) command(text string) (SExpr, error) {
+	if _, err := io.WriteString(pb.stdin, text+"\n"); err != nil {
+		return SExpr{}, fmt.Errorf("%s: failed to send command: %w", pb.config.Name, err)
+	}
+	response, err := pb.output.Read()
+	if err != nil {
+		// Солвер перестал отвечать: его сообщение об ошибке нужно дочитать
+		_ = pb.wait()
+		return SExpr{}, fmt.Errorf("%s: failed to read response to %s: %w (stderr: %s)",
+			pb.config.Name, text, err, strings.TrimSpace(pb.stderr.String()))
+	}
+	if !response.IsAtom() && len(response.List) > 0 && response.List[0].Atom == "error" {
+		return SExpr{}, fmt.Errorf("%s: %s", pb.config.Name, response)
+	}
+	return response, nil
+}
+
+// expectSuccess отправляет команду, ответом на которую должен быть success
+func (pb *ProcessBackend) expectSuccess(text string) error {
+	response, err := pb.command(text)
+	if err != nil {
+		return err
+	}
+	if response.Atom != "success" {
+		return fmt.Errorf("%s: unexpected response %s to %s", pb.config.Name, response, text)
+	}
+	return nil
+}
+
+// queryReasonUnknown запрашивает причину ответа unknown
+func (pb *ProcessBackend) queryReasonUnknown() string {
+	response, err := pb.command("(get-info :reason-unknown)")
+	if err != nil || len(response.List) != 2 {
+		return "unknown reason"
+	}
+	return strings.Trim(response.List[1].Atom, "\"")
+}
+
+// parseValue разбирает значение из ответа get-value
+func parseValue(value SExpr, exprType symbolic.ExpressionType) (symbolic.SymbolicExpression, error) {
+	if exprType == symbolic.BoolType {
+		switch value.Atom {
+		case "true":
+			return symbolic.NewBoolConstant(true), nil
+		case "false":
+			return symbolic.NewBoolConstant(false), nil
+		default:
+			return nil, fmt.Errorf("unexpected boolean value %s", value)
+		}
+	}
+
+	number, err := parseNumeral(value)
+	if err != nil {
+		return nil, err
+	}
+	if !number.IsInt64() {
+		return nil, fmt.Errorf("value %s does not fit into int64", number)
+	}
+	return symbolic.NewIntConstant(number.Int64()), nil
+}
+
+// parseNumeral разбирает целочисленный литерал SMT-LIB2: 42, (- 42),
+// #b1010, #x2a и (_ bv42 64). Битовые векторы трактуются как знаковые.
+func parseNumeral(value SExpr) (*big.Int, error) {
+	if !value.IsAtom() {
+		switch {
+		case len(value.List) == 2 && value.List[0].Atom == "-":
+			number, err := parseNumeral(value.List[1])
+			if err != nil {
+				return nil, err
+			}
+			return number.Neg(number), nil
+		case len(value.List) == 3 && value.List[0].Atom == "_" && strings.HasPrefix(value.List[1].Atom, "bv"):
+			width, err := strconv.Atoi(value.List[2].Atom)
+			if err != nil {
+				return nil, fmt.Errorf("malformed bit-vector literal %s", value)
+			}
+			return parseBitVector(strings.TrimPrefix(value.List[1].Atom, "bv"), 10, width)
+		default:
+			return nil, fmt.Errorf("unsupported numeral %s", value)
+		}
+	}
+
+	atom := value.Atom
+	switch {
+	case strings.HasPrefix(atom, "#b"):
+		return parseBitVector(atom[2:], 2, len(atom)-2)
+	case strings.HasPrefix(atom, "#x"):
+		return parseBitVector(atom[2:], 16, 4*(len(atom)-2))
+	}
+	number, ok := new(big.Int).SetString(atom, 10)
+	if !ok {
+		return nil, fmt.Errorf("malformed numeral %s", atom)
+	}
+	return number, nil
+}
+
+// parseBitVector разбирает битовый вектор как знаковое число в дополнительном коде
+func parseBitVector(digits string, base int, width int) (*big.Int, error) {
+	number, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, fmt.Errorf("malformed bit-vector digits %s", digits)
+	}
+	if width > 0 && number.Bit(width-1) == 1 {
+		number.Sub(number, new(big.Int).Lsh(big.NewInt(1), uint(width)))
+	}
+	return number, nil
+}
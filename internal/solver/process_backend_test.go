@@ -0,0 +1,174 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestParseNumeral(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"42", "42"},
+		{"(- 42)", "-42"},
+		{"#b1010", "-6"},
+		{"#b0101", "5"},
+		{"#x2a", "42"},
+		{"#xff", "-1"},
+		{"(_ bv42 64)", "42"},
+		{"(_ bv18446744073709551615 64)", "-1"},
+		{"(- (_ bv1 8))", "-1"},
+	}
+	for _, test := range tests {
+		expr, err := newSExprReader(strings.NewReader(test.input)).Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		number, err := parseNumeral(expr)
+		if err != nil {
+			t.Errorf("parseNumeral(%s): %v", test.input, err)
+			continue
+		}
+		if number.String() != test.want {
+			t.Errorf("parseNumeral(%s) = %s, want %s", test.input, number, test.want)
+		}
+	}
+}
+
+func TestParseNumeralErrors(t *testing.T) {
+	for _, input := range []string{"x", "(+ 1 2)", "(_ bvx 8)", "(_ bv1 w)", "#b12"} {
+		expr, err := newSExprReader(strings.NewReader(input)).Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if number, err := parseNumeral(expr); err == nil {
+			t.Errorf("parseNumeral(%s) = %s, expected error", input, number)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		value    SExpr
+		exprType symbolic.ExpressionType
+		want     string
+		wantErr  bool
+	}{
+		{SExpr{Atom: "true"}, symbolic.BoolType, "true", false},
+		{SExpr{Atom: "1"}, symbolic.BoolType, "", true},
+		{SExpr{List: []SExpr{{Atom: "-"}, {Atom: "7"}}}, symbolic.IntType, "-7", false},
+		{SExpr{Atom: "99999999999999999999"}, symbolic.IntType, "", true},
+	}
+	for _, test := range tests {
+		value, err := parseValue(test.value, test.exprType)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseValue(%s) error = %v, wantErr %t", test.value, err, test.wantErr)
+			continue
+		}
+		if err == nil && value.String() != test.want {
+			t.Errorf("parseValue(%s) = %s, want %s", test.value, value, test.want)
+		}
+	}
+}
+
+// fakeSolverEnv включает режим поддельного солвера в процессе теста
+const fakeSolverEnv = "SYMGO_FAKE_SOLVER"
+
+// fakeSolver запускает тестовый бинарный файл как SMT-LIB2 солвер: он
+// отвечает success на команды, sat на check-sat и значениями на get-value
+func fakeSolver(t *testing.T, mode string) ProcessConfig {
+	t.Setenv(fakeSolverEnv, mode)
+	return ProcessConfig{Name: "fake", Command: os.Args[0], Args: []string{"-test.run=^TestFakeSolverProcess$"}}
+}
+
+// TestFakeSolverProcess — тело поддельного солвера, а не тест
+func TestFakeSolverProcess(t *testing.T) {
+	mode := os.Getenv(fakeSolverEnv)
+	if mode == "" {
+		t.Skip("helper process")
+	}
+	sorts := make(map[string]string)
+	reader := newSExprReader(bufio.NewReader(os.Stdin))
+	for {
+		command, err := reader.Read()
+		if err != nil {
+			os.Exit(0)
+		}
+		switch command.List[0].Atom {
+		case "exit":
+			os.Exit(0)
+		case "declare-const":
+			sorts[command.List[1].Atom] = command.List[2].String()
+			fmt.Println("success")
+		case "check-sat":
+			if mode == "crash" {
+				fmt.Fprintln(os.Stderr, "fatal: out of memory")
+				os.Exit(1)
+			}
+			fmt.Println("sat")
+		case "get-value":
+			var pairs []string
+			for i, term := range command.List[1].List {
+				value := fmt.Sprintf("(- %d)", i+1)
+				if sorts[term.Atom] == "Bool" {
+					value = "true"
+				}
+				pairs = append(pairs, fmt.Sprintf("(%s %s)", term, value))
+			}
+			fmt.Printf("(%s)\n", strings.Join(pairs, " "))
+		default:
+			fmt.Println("success")
+		}
+	}
+}
+
+func TestProcessBackendModel(t *testing.T) {
+	backend, err := NewProcessBackend(fakeSolver(t, "sat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	names := []string{"x", "a|b", "a b", "flag"}
+	for _, name := range names {
+		exprType := symbolic.IntType
+		if name == "flag" {
+			exprType = symbolic.BoolType
+		}
+		if err := backend.Assert(symbolic.NewSymbolicVariable(name, exprType)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result, err := backend.Check(); err != nil || result != SAT {
+		t.Fatalf("Check() = %v, %v", result, err)
+	}
+	model, err := backend.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"x": "-1", "a|b": "-2", "a b": "-3", "flag": "true"}
+	for name, value := range want {
+		if model[name] == nil || model[name].String() != value {
+			t.Errorf("model[%q] = %v, want %s", name, model[name], value)
+		}
+	}
+}
+
+func TestProcessBackendReportsStderr(t *testing.T) {
+	backend, err := NewProcessBackend(fakeSolver(t, "crash"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	_, err = backend.Check()
+	if err == nil || !strings.Contains(err.Error(), "out of memory") {
+		t.Errorf("Check() error = %v, want solver stderr in the message", err)
+	}
+}
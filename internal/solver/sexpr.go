@@ -0,0 +1,137 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SExpr — S-выражение из ответа SMT-LIB2 солвера: атом или список
+type SExpr struct {
+	Atom string
+	List []SExpr
+}
+
+// IsAtom проверяет, является ли выражение атомом
+func (se SExpr) IsAtom() bool {
+	return se.List == nil
+}
+
+// String возвращает текстовое представление выражения
+func (se SExpr) String() string {
+	if se.IsAtom() {
+		return se.Atom
+	}
+	parts := make([]string, 0, len(se.List))
+	for _, item := range se.List {
+		parts = append(parts, item.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// sexprReader читает S-выражения из потока
+type sexprReader struct {
+	reader *bufio.Reader
+}
+
+func newSExprReader(reader io.Reader) *sexprReader {
+	return &sexprReader{reader: bufio.NewReader(reader)}
+}
+
+// Read читает одно полное S-выражение
+func (sr *sexprReader) Read() (SExpr, error) {
+	token, err := sr.token()
+	if err != nil {
+		return SExpr{}, err
+	}
+	switch token {
+	case "(":
+		list := []SExpr{}
+		for {
+			item, err := sr.Read()
+			if err == errCloseParen {
+				return SExpr{List: list}, nil
+			}
+			if err != nil {
+				return SExpr{}, err
+			}
+			list = append(list, item)
+		}
+	case ")":
+		return SExpr{}, errCloseParen
+	default:
+		return SExpr{Atom: token}, nil
+	}
+}
+
+var errCloseParen = fmt.Errorf("unexpected ')'")
+
+// token читает следующую лексему, пропуская пробелы и комментарии
+func (sr *sexprReader) token() (string, error) {
+	for {
+		r, _, err := sr.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case r == ';':
+			if _, err := sr.reader.ReadString('\n'); err != nil {
+				return "", err
+			}
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+		case r == '(' || r == ')':
+			return string(r), nil
+		case r == '"':
+			return sr.delimited('"', true)
+		case r == '|':
+			return sr.delimited('|', false)
+		default:
+			var builder strings.Builder
+			builder.WriteRune(r)
+			for {
+				next, _, err := sr.reader.ReadRune()
+				if err == io.EOF {
+					return builder.String(), nil
+				}
+				if err != nil {
+					return "", err
+				}
+				if strings.ContainsRune(" \t\r\n()", next) {
+					_ = sr.reader.UnreadRune()
+					return builder.String(), nil
+				}
+				builder.WriteRune(next)
+			}
+		}
+	}
+}
+
+// delimited читает строковый литерал или экранированный символ.
+// В строковых литералах SMT-LIB2 кавычка экранируется удвоением.
+func (sr *sexprReader) delimited(delimiter rune, doubled bool) (string, error) {
+	var builder strings.Builder
+	builder.WriteRune(delimiter)
+	for {
+		r, _, err := sr.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		builder.WriteRune(r)
+		if r != delimiter {
+			continue
+		}
+		if !doubled {
+			return builder.String(), nil
+		}
+		next, _, err := sr.reader.ReadRune()
+		if err == nil && next == delimiter {
+			builder.WriteRune(next)
+			continue
+		}
+		if err == nil {
+			_ = sr.reader.UnreadRune()
+		}
+		return builder.String(), nil
+	}
+}
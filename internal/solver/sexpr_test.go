@@ -0,0 +1,59 @@
+package solver
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSExprReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"atoms", "sat unsat\nsuccess", []string{"sat", "unsat", "success"}},
+		{"nested lists", "((x 1) (y (- 2)))", []string{"((x 1) (y (- 2)))"}},
+		{"empty list", "()", []string{"()"}},
+		{"comments", "; reply\n(a ; inline\n b)", []string{"(a b)"}},
+		{"quoted symbol", "(|a b| 1)", []string{"(|a b| 1)"}},
+		{"string with doubled quote", `(error "line ""3""")`, []string{`(error "line ""3""")`}},
+		{"atom before paren", "(f(x))", []string{"(f (x))"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := newSExprReader(strings.NewReader(test.input))
+			var got []string
+			for {
+				expr, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Read: %v", err)
+				}
+				got = append(got, expr.String())
+			}
+			if strings.Join(got, "|") != strings.Join(test.want, "|") {
+				t.Errorf("Read = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSExprReaderErrors(t *testing.T) {
+	for _, input := range []string{")", "(a b", `"unterminated`, "|unterminated"} {
+		if expr, err := newSExprReader(strings.NewReader(input)).Read(); err == nil {
+			t.Errorf("Read(%q) = %s, expected error", input, expr)
+		}
+	}
+}
+
+func TestSExprIsAtom(t *testing.T) {
+	if !(SExpr{Atom: "x"}).IsAtom() {
+		t.Error("atom reported as list")
+	}
+	if (SExpr{List: []SExpr{}}).IsAtom() {
+		t.Error("empty list reported as atom")
+	}
+}
@@ -0,0 +1,147 @@
+package solver
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ebukreev/go-z3/z3"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+	"symbolic-execution-course/pkg/z3wrapper"
+)
+
+// Z3Backend реализует SolverBackend поверх библиотеки go-z3
+type Z3Backend struct {
+	translator *translator.Z3Translator
+	solver     *z3wrapper.Solver
+	scopes     [][]*symbolic.SymbolicVariable // Переменные, добавленные на каждом уровне стека
+}
+
+// NewZ3Backend создаёт солвер в контексте переданного транслятора
+func NewZ3Backend(z3Translator *translator.Z3Translator) *Z3Backend {
+	ctx := z3Translator.GetContext().(*z3.Context)
+	return &Z3Backend{
+		translator: z3Translator,
+		solver:     z3wrapper.NewSolverWithContext(ctx),
+		scopes:     make([][]*symbolic.SymbolicVariable, 1),
+	}
+}
+
+// Name возвращает имя солвера
+func (zb *Z3Backend) Name() string {
+	return "z3"
+}
+
+// Assert транслирует ограничение в Z3 и добавляет его в солвер
+func (zb *Z3Backend) Assert(constraint symbolic.SymbolicExpression) error {
+	translated, err := zb.translator.TranslateExpression(constraint)
+	if err != nil {
+		return err
+	}
+	formula, ok := translated.(z3.Bool)
+	if !ok {
+		return translator.NewTranslationError("constraint is not boolean", constraint)
+	}
+
+	top := len(zb.scopes) - 1
+	zb.scopes[top] = append(zb.scopes[top], symbolic.CollectVariables(constraint)...)
+	zb.solver.Assert(formula)
+	return nil
+}
+
+// Check проверяет выполнимость ограничений
+func (zb *Z3Backend) Check() (Result, error) {
+	return zb.solver.Check(), nil
+}
+
+// ReasonUnknown возвращает причину последнего результата UNKNOWN
+func (zb *Z3Backend) ReasonUnknown() string {
+	return zb.solver.ReasonUnknown()
+}
+
+// Push сохраняет текущий набор ограничений
+func (zb *Z3Backend) Push() error {
+	zb.solver.Push()
+	zb.scopes = append(zb.scopes, nil)
+	return nil
+}
+
+// Pop восстанавливает предыдущий набор ограничений
+func (zb *Z3Backend) Pop() error {
+	if len(zb.scopes) == 1 {
+		return fmt.Errorf("pop without matching push")
+	}
+	zb.solver.Pop()
+	zb.scopes = zb.scopes[:len(zb.scopes)-1]
+	return nil
+}
+
+// Model возвращает значения всех переменных, встреченных в ограничениях
+func (zb *Z3Backend) Model() (Model, error) {
+	z3Model := zb.solver.Model()
+	model := make(Model)
+	for _, scope := range zb.scopes {
+		for _, variable := range scope {
+			if _, done := model[variable.Name]; done {
+				continue
+			}
+			value, err := zb.evaluate(z3Model, variable)
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				model[variable.Name] = value
+			}
+		}
+	}
+	return model, nil
+}
+
+// SetTimeout задаёт ограничение времени на один Check
+func (zb *Z3Backend) SetTimeout(timeout time.Duration) error {
+	zb.solver.SetTimeout(timeout)
+	return nil
+}
+
+// SetResourceLimit задаёт лимит ресурсов Z3 на один Check
+func (zb *Z3Backend) SetResourceLimit(limit uint) error {
+	zb.solver.SetResourceLimit(limit)
+	return nil
+}
+
+// Close освобождает ресурсы солвера
+func (zb *Z3Backend) Close() error {
+	zb.solver.Close()
+	return nil
+}
+
+// evaluate получает значение переменной из модели Z3 типизированным
+// извлечением z3wrapper. Для значений без представления в Model
+// (массивы, ссылки) возвращает nil.
+func (zb *Z3Backend) evaluate(z3Model *z3.Model, variable *symbolic.SymbolicVariable) (symbolic.SymbolicExpression, error) {
+	translated, err := zb.translator.TranslateExpression(variable)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := translated.(z3.Value)
+	if !ok {
+		return nil, nil
+	}
+
+	result, err := zb.solver.GetValue(z3Model, value)
+	if err != nil {
+		return nil, fmt.Errorf("variable %s: %w", variable.Name, err)
+	}
+	switch typed := result.(type) {
+	case *big.Int:
+		if !typed.IsInt64() {
+			return nil, fmt.Errorf("variable %s: value %s does not fit into int64", variable.Name, typed)
+		}
+		return symbolic.NewIntConstant(typed.Int64()), nil
+	case bool:
+		return symbolic.NewBoolConstant(typed), nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,57 @@
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+)
+
+// assertZ3 добавляет ограничения в новый Z3Backend. Тест пропускается,
+// пока трансляция выражений в Z3 из домашнего задания не реализована.
+func assertZ3(t *testing.T, constraints ...symbolic.SymbolicExpression) *Z3Backend {
+	t.Helper()
+	defer func() {
+		if recover() != nil {
+			t.Skip("Z3 translation is not implemented yet")
+		}
+	}()
+	backend := NewZ3Backend(translator.NewZ3Translator())
+	for _, constraint := range constraints {
+		if err := backend.Assert(constraint); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return backend
+}
+
+func TestZ3BackendModel(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	p := symbolic.NewSymbolicVariable("p", symbolic.BoolType)
+	backend := assertZ3(t,
+		&symbolic.BinaryOperation{Left: x, Right: symbolic.NewIntConstant(math.MinInt64), Operator: symbolic.EQ},
+		&symbolic.LogicalOperation{Operands: []symbolic.SymbolicExpression{p}, Operator: symbolic.NOT},
+	)
+	if result, err := backend.Check(); result != SAT || err != nil {
+		t.Fatalf("Check = %s, %v", result, err)
+	}
+	model, err := backend.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model["x"].String() != "-9223372036854775808" || model["p"].String() != "false" {
+		t.Errorf("model = %v", model)
+	}
+}
+
+func TestZ3BackendModelOverflow(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	backend := assertZ3(t, &symbolic.BinaryOperation{Left: x, Right: symbolic.NewIntConstant(math.MaxInt64), Operator: symbolic.GT})
+	if result, err := backend.Check(); result != SAT || err != nil {
+		t.Fatalf("Check = %s, %v", result, err)
+	}
+	if model, err := backend.Model(); err == nil {
+		t.Errorf("Model = %v, want an error for a value outside int64", model)
+	}
+}
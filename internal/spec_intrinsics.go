@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"fmt"
+	"go/constant"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// specPackage — путь пакета со встроенными функциями спецификаций
+const specPackage = "symbolic-execution-course/pkg/spec"
+
+// callIntrinsic обрабатывает вызовы spec.Assume, spec.Assert и
+// spec.AssertMessage. Возвращает false, если вызов не является встроенным.
+// Состояние-нарушение возвращается завершённым (Terminated) вместе
+// с моделью контрпримера. Обработчик вызова обращается к callIntrinsic,
+// а затем к callModel до входа в тело вызываемой функции.
+func (interpreter *Interpreter) callIntrinsic(call *ssa.CallCommon, args []symbolic.SymbolicExpression) ([]Interpreter, bool, error) {
+	callee := call.StaticCallee()
+	if callee == nil || callee.Pkg == nil || callee.Pkg.Pkg.Path() != specPackage {
+		return nil, false, nil
+	}
+	if len(args) == 0 {
+		return nil, true, fmt.Errorf("%s expects a condition", callee.Name())
+	}
+
+	switch callee.Name() {
+	case "Assume":
+		states, err := interpreter.assume(args[0])
+		return states, true, err
+	case "Assert", "AssertMessage":
+		message := fmt.Sprintf("assertion %s failed at %s", args[0], callee.Prog.Fset.Position(call.Pos()))
+		if len(call.Args) == 2 {
+			if text, ok := call.Args[1].(*ssa.Const); ok && text.Value != nil && text.Value.Kind() == constant.String {
+				message += ": " + constant.StringVal(text.Value)
+			}
+		}
+		continuing, violations, err := interpreter.assert(args[0], AssertionViolation, message)
+		if continuing != nil {
+			violations = append([]Interpreter{*continuing}, violations...)
+		}
+		return violations, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// assume сужает путь условием; невыполнимый путь отбрасывается
+// согласно UnknownPolicy через resolveState, а в конколическом режиме —
+// запуск, на входах которого условие ложно (см. concolicAssume)
+func (interpreter *Interpreter) assume(condition symbolic.SymbolicExpression) ([]Interpreter, error) {
+	if interpreter.IsConcolic() {
+		holds, err := interpreter.concolicAssume(condition)
+		if err != nil || !holds {
+			return nil, err
+		}
+		return []Interpreter{*interpreter}, nil
+	}
+	interpreter.addConstraint(condition)
+	state, err := interpreter.Analyser.resolveState(*interpreter)
+	if err != nil || state == nil {
+		return nil, err
+	}
+	return []Interpreter{*state}, nil
+}
+
+// assert возвращает состояние, продолжающее исполнение при истинном условии,
+// и состояние-нарушение вида kind, если на текущем пути условие может быть
+// ложным. Выполнимость обоих проверяется через resolveState; continuing
+// равно nil, если условие на пути всегда ложно.
+func (interpreter *Interpreter) assert(condition symbolic.SymbolicExpression, kind FindingKind, message string) (continuing *Interpreter, violations []Interpreter, err error) {
+	if interpreter.IsConcolic() {
+		holds, err := interpreter.concolicBranch(condition)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !holds {
+			interpreter.abort(kind, message)
+		}
+		return interpreter, nil, nil
+	}
+
+	violation := interpreter.fork()
+	violation.addConstraint(negate(condition))
+	interpreter.addConstraint(condition)
+
+	continuing, err = interpreter.Analyser.resolveState(*interpreter)
+	if err != nil {
+		return nil, nil, err
+	}
+	failing, err := interpreter.Analyser.resolveState(violation)
+	if err != nil {
+		return nil, nil, err
+	}
+	if failing != nil {
+		failing.abort(kind, message)
+		violations = append(violations, *failing)
+	}
+	return continuing, violations, nil
+}
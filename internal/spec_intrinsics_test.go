@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// intrinsicsSource подменяет pkg/spec: встроенные функции распознаются
+// по пути пакета, а не по телу
+const intrinsicsSource = `package spec
+
+func Assume(condition bool)                       {}
+func Assert(condition bool)                       {}
+func AssertMessage(condition bool, message string) {}
+func Other(condition bool)                        {}
+
+func checked(x int) {
+	AssertMessage(x > 0, "x must be positive")
+}
+
+func assumed(x int) { Assume(x > 0) }
+
+func guarded(x int) {
+	Assume(x > 0)
+	Assert(x != 0)
+}
+
+func other(x int) { Other(x > 0) }
+`
+
+func intrinsicCall(t *testing.T, function string) *ssa.CallCommon {
+	t.Helper()
+	return &firstCall(t, buildPackageFunction(t, specPackage, intrinsicsSource, function)).Call
+}
+
+func TestCallIntrinsicIgnoresOtherFunctions(t *testing.T) {
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(4))}
+	if _, handled, _ := interpreter.callIntrinsic(intrinsicCall(t, "other"), []symbolic.SymbolicExpression{boolVar("b")}); handled {
+		t.Error("spec.Other was handled as an intrinsic")
+	}
+	if _, handled, err := interpreter.callIntrinsic(intrinsicCall(t, "assumed"), nil); !handled || err == nil {
+		t.Errorf("Assume without a condition = %t, %v", handled, err)
+	}
+}
+
+func TestAssume(t *testing.T) {
+	x := intVar("x")
+	tests := []struct {
+		name      string
+		condition symbolic.SymbolicExpression
+		policy    UnknownPolicy
+		unknown   bool
+		states    int
+	}{
+		{"feasible", compare(x, symbolic.GT, intConst(0)), DropUnknown, false, 1},
+		{"infeasible path is dropped", logical(symbolic.AND, compare(x, symbolic.GT, intConst(0)), compare(x, symbolic.LT, intConst(0))), DropUnknown, false, 0},
+		{"unknown is dropped", compare(x, symbolic.GT, intConst(0)), DropUnknown, true, 0},
+		{"unknown is kept", compare(x, symbolic.GT, intConst(0)), KeepUnknown, true, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := newFakeSolver(4)
+			backend.unknown = test.unknown
+			interpreter := Interpreter{Analyser: newTestAnalyser(backend, WithUnknownPolicy(test.policy))}
+			states, handled, err := interpreter.callIntrinsic(intrinsicCall(t, "assumed"), []symbolic.SymbolicExpression{test.condition})
+			if err != nil || !handled {
+				t.Fatalf("callIntrinsic = %t, %v", handled, err)
+			}
+			if len(states) != test.states {
+				t.Fatalf("Assume returned %d states, want %d", len(states), test.states)
+			}
+			if len(states) == 1 && states[0].PathCondition != test.condition {
+				t.Errorf("path condition = %v, want the assumption", states[0].PathCondition)
+			}
+			if len(states) == 1 && states[0].Incomplete != test.unknown {
+				t.Errorf("Incomplete = %t, want %t", states[0].Incomplete, test.unknown)
+			}
+		})
+	}
+}
+
+func TestAssert(t *testing.T) {
+	requireExpressions(t)
+	x := intVar("x")
+	tests := []struct {
+		name       string
+		path       symbolic.SymbolicExpression
+		unknown    bool
+		policy     UnknownPolicy
+		continuing bool
+		violation  bool
+	}{
+		{"may fail", nil, false, DropUnknown, true, true},
+		{"always holds", compare(x, symbolic.GT, intConst(3)), false, DropUnknown, true, false},
+		{"always fails", compare(x, symbolic.LT, intConst(-1)), false, DropUnknown, false, true},
+		{"unknown is dropped", nil, true, DropUnknown, false, false},
+		{"unknown is kept", nil, true, KeepUnknown, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := newFakeSolver(4)
+			backend.unknown = test.unknown
+			interpreter := Interpreter{
+				Analyser:      newTestAnalyser(backend, WithUnknownPolicy(test.policy)),
+				PathCondition: test.path,
+			}
+			states, _, err := interpreter.callIntrinsic(intrinsicCall(t, "checked"), []symbolic.SymbolicExpression{compare(x, symbolic.GT, intConst(0))})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var continuing, violations []Interpreter
+			for _, state := range states {
+				if state.Terminated {
+					violations = append(violations, state)
+				} else {
+					continuing = append(continuing, state)
+				}
+			}
+			if (len(continuing) == 1) != test.continuing || len(continuing) > 1 {
+				t.Errorf("%d continuing states, want %t", len(continuing), test.continuing)
+			}
+			if (len(violations) == 1) != test.violation || len(violations) > 1 {
+				t.Fatalf("%d violations, want %t", len(violations), test.violation)
+			}
+			if len(violations) == 0 {
+				return
+			}
+
+			finding := violations[0].Findings[0]
+			if finding.Kind != AssertionViolation || !strings.HasSuffix(finding.Message, ": x must be positive") {
+				t.Errorf("finding = %+v", finding)
+			}
+			if test.unknown {
+				if !violations[0].Incomplete {
+					t.Error("violation with an unknown verdict is not marked incomplete")
+				}
+				return
+			}
+			if value, _ := symbolic.Evaluate(x, violations[0].Model); value.(*symbolic.IntConstant).Value > 0 {
+				t.Errorf("counterexample x = %s satisfies the assertion", value)
+			}
+		})
+	}
+}
+
+func TestAssertConcolic(t *testing.T) {
+	requireExpressions(t)
+	x := intVar("x")
+	for _, value := range []int64{1, -1} {
+		backend := newFakeSolver(4)
+		interpreter := Interpreter{
+			Analyser:       newTestAnalyser(backend),
+			ConcreteInputs: solver.Model{"x": intConst(value)},
+		}
+		states, _, err := interpreter.callIntrinsic(intrinsicCall(t, "checked"), []symbolic.SymbolicExpression{compare(x, symbolic.GT, intConst(0))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(states) != 1 || states[0].Terminated != (value < 0) {
+			t.Errorf("x = %d: %d states, terminated %t", value, len(states), len(states) > 0 && states[0].Terminated)
+		}
+		if backend.checks != 0 {
+			t.Errorf("x = %d: concolic assertion queried the solver", value)
+		}
+	}
+}
+
+// intrinsicRun имитирует конколический запуск функции, которая состоит из
+// вызовов встроенных функций spec с условиями conditions по порядку
+func intrinsicRun(t *testing.T, analyser *Analyser, function string, conditions ...symbolic.SymbolicExpression) func(solver.Model) []Interpreter {
+	t.Helper()
+	var calls []*ssa.CallCommon
+	for _, instruction := range buildPackageFunction(t, specPackage, intrinsicsSource, function).Blocks[0].Instrs {
+		if call, ok := instruction.(*ssa.Call); ok {
+			calls = append(calls, &call.Call)
+		}
+	}
+	return func(inputs solver.Model) []Interpreter {
+		state := Interpreter{Analyser: analyser, ConcreteInputs: inputs}
+		for i, call := range calls {
+			states, _, err := state.callIntrinsic(call, conditions[i:i+1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(states) == 0 || states[0].Terminated {
+				return states
+			}
+			state = states[0]
+		}
+		return []Interpreter{state}
+	}
+}
+
+func TestAssumeConcolic(t *testing.T) {
+	requireExpressions(t)
+	x := intVar("x")
+	analyser := newTestAnalyser(newFakeSolver(4))
+	run := intrinsicRun(t, analyser, "guarded", compare(x, symbolic.GT, intConst(0)), compare(x, symbolic.NE, intConst(0)))
+
+	// Нулевой вход нарушает допущение: запуск отбрасывается, а вместо него
+	// исполняется вход, удовлетворяющий допущению. Инвертировать само
+	// допущение нельзя, поэтому нарушение Assert недостижимо.
+	results, err := analyser.concolicSearch(nil, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("concolicSearch ran %d paths, want 1", len(results))
+	}
+	final := results[0]
+	if final.Terminated || len(final.Findings) != 0 {
+		t.Errorf("unexpected finding %+v", final.Findings)
+	}
+	if final.ConcreteInputs["x"].(*symbolic.IntConstant).Value <= 0 {
+		t.Errorf("inputs %v violate the assumption", final.ConcreteInputs)
+	}
+	if len(final.Assumptions) != 1 || final.Assumptions[0] != 0 || len(final.Branches) != 2 {
+		t.Errorf("assumptions %v of branches %v, want the first branch", final.Assumptions, final.Branches)
+	}
+}
@@ -2,6 +2,7 @@
 package ssa
 
 import (
+	"go/ast"
 	"go/token"
 
 	"golang.org/x/tools/go/ssa"
@@ -10,6 +11,8 @@ import (
 // Builder отвечает за построение SSA из исходного кода Go
 type Builder struct {
 	fset *token.FileSet
+	// files — разобранные файлы, из комментариев которых читаются контракты
+	files []*ast.File
 }
 
 // NewBuilder создаёт новый экземпляр Builder
@@ -31,7 +34,9 @@ func (b *Builder) ParseAndBuildSSA(source string, funcName string) (*ssa.Functio
 	// 3. Поиск нужной функции по имени
 
 	// Подсказки:
-	// - Используйте parser.ParseFile для парсинга
+	// - Используйте parser.ParseFile для парсинга (с parser.ParseComments,
+	//   чтобы были доступны контракты //sym:requires и //sym:ensures),
+	//   и сохраните разобранный файл в b.files
 	// - Создайте packages.Config и загрузите пакет
 	// - Используйте ssautil.CreateProgram для создания SSA
 	// - Найдите функцию в SSA программе
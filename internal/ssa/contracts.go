@@ -0,0 +1,232 @@
+package ssa
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// Префиксы аннотаций контрактов в doc-комментарии функции
+const (
+	requiresPrefix = "//sym:requires "
+	ensuresPrefix  = "//sym:ensures "
+)
+
+// Clause — одно условие контракта
+type Clause struct {
+	// Text — условие в исходном виде, например "n >= 0"
+	Text string
+	Expr symbolic.SymbolicExpression
+	Pos  token.Position
+}
+
+// Contract — пред- и постусловия функции из аннотаций
+//
+//	//sym:requires n >= 0
+//	//sym:ensures result >= 1
+//
+// В requires доступны параметры функции, в ensures — также результаты:
+// именованные по имени, единственный безымянный как result, несколько
+// безымянных как result0, result1, ...
+type Contract struct {
+	Requires []Clause
+	Ensures  []Clause
+	// Results — переменные результатов в порядке их объявления.
+	// При проверке ensures они приравниваются возвращаемым значениям.
+	Results []*symbolic.SymbolicVariable
+}
+
+// Contract разбирает контракт функции. Для функции без аннотаций возвращает nil.
+// Исходный код должен быть разобран с parser.ParseComments.
+func (b *Builder) Contract(function *ssa.Function) (*Contract, error) {
+	return ParseContract(function, b.funcDecl(function))
+}
+
+// funcDecl находит объявление функции среди разобранных файлов
+func (b *Builder) funcDecl(function *ssa.Function) *ast.FuncDecl {
+	for _, file := range b.files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Name.Pos() == function.Pos() {
+				return funcDecl
+			}
+		}
+	}
+	return nil
+}
+
+// ParseContract разбирает аннотации //sym:requires и //sym:ensures
+// из doc-комментария объявления функции в символьные выражения.
+// Если decl равен nil, используется function.Syntax(): синтаксис
+// сохраняется только в программах, построенных в режиме ssa.GlobalDebug.
+func ParseContract(function *ssa.Function, decl *ast.FuncDecl) (*Contract, error) {
+	if decl == nil {
+		decl, _ = function.Syntax().(*ast.FuncDecl)
+	}
+	if decl == nil || decl.Doc == nil {
+		return nil, nil
+	}
+
+	scope := make(map[string]*symbolic.SymbolicVariable)
+	for _, param := range function.Params {
+		scope[param.Name()] = contractVariable(param.Name(), param.Type())
+	}
+	params := maps.Clone(scope)
+
+	contract := &Contract{}
+	results := function.Signature.Results()
+	for i := 0; i < results.Len(); i++ {
+		name := results.At(i).Name()
+		switch {
+		case name != "" && name != "_":
+		case results.Len() == 1:
+			name = "result"
+		default:
+			name = fmt.Sprintf("result%d", i)
+		}
+		variable := contractVariable("$"+name, results.At(i).Type())
+		scope[name] = variable
+		contract.Results = append(contract.Results, variable)
+	}
+
+	fset := function.Prog.Fset
+	for _, comment := range decl.Doc.List {
+		var text string
+		var clauses *[]Clause
+		var clauseScope map[string]*symbolic.SymbolicVariable
+		switch {
+		case strings.HasPrefix(comment.Text, requiresPrefix):
+			text, clauses, clauseScope = strings.TrimPrefix(comment.Text, requiresPrefix), &contract.Requires, params
+		case strings.HasPrefix(comment.Text, ensuresPrefix):
+			text, clauses, clauseScope = strings.TrimPrefix(comment.Text, ensuresPrefix), &contract.Ensures, scope
+		default:
+			continue
+		}
+
+		position := fset.Position(comment.Pos())
+		expr, err := parseClause(text, clauseScope)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", position, err)
+		}
+		*clauses = append(*clauses, Clause{Text: strings.TrimSpace(text), Expr: expr, Pos: position})
+	}
+
+	if len(contract.Requires) == 0 && len(contract.Ensures) == 0 {
+		return nil, nil
+	}
+	return contract, nil
+}
+
+// contractVariable создаёт переменную контракта для значения Go типа tpe.
+// Для неподдерживаемых типов возвращает nil: такие значения нельзя
+// использовать в условиях.
+func contractVariable(name string, tpe types.Type) *symbolic.SymbolicVariable {
+	basic, ok := tpe.Underlying().(*types.Basic)
+	switch {
+	case ok && basic.Info()&types.IsBoolean != 0:
+		return symbolic.NewSymbolicVariable(name, symbolic.BoolType)
+	case ok && basic.Info()&types.IsInteger != 0:
+		return symbolic.NewSymbolicVariable(name, symbolic.IntType)
+	default:
+		return nil
+	}
+}
+
+// parseClause разбирает условие контракта — выражение Go над переменными scope
+func parseClause(text string, scope map[string]*symbolic.SymbolicVariable) (symbolic.SymbolicExpression, error) {
+	expr, err := parser.ParseExpr(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract %q: %w", text, err)
+	}
+	result, err := translateClause(expr, scope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract %q: %w", text, err)
+	}
+	if result.Type() != symbolic.BoolType {
+		return nil, fmt.Errorf("contract %q is not a boolean expression", text)
+	}
+	return result, nil
+}
+
+// Операторы Go, допустимые в условиях контрактов
+var (
+	clauseBinaryOperators = map[token.Token]symbolic.BinaryOperator{
+		token.ADD: symbolic.ADD, token.SUB: symbolic.SUB, token.MUL: symbolic.MUL,
+		token.QUO: symbolic.DIV, token.REM: symbolic.MOD,
+		token.EQL: symbolic.EQ, token.NEQ: symbolic.NE,
+		token.LSS: symbolic.LT, token.LEQ: symbolic.LE,
+		token.GTR: symbolic.GT, token.GEQ: symbolic.GE,
+	}
+	clauseLogicalOperators = map[token.Token]symbolic.LogicalOperator{
+		token.LAND: symbolic.AND, token.LOR: symbolic.OR,
+	}
+)
+
+// translateClause переводит выражение Go в символьное выражение
+func translateClause(expr ast.Expr, scope map[string]*symbolic.SymbolicVariable) (symbolic.SymbolicExpression, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return translateClause(e.X, scope)
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return symbolic.NewBoolConstant(true), nil
+		case "false":
+			return symbolic.NewBoolConstant(false), nil
+		}
+		variable, ok := scope[e.Name]
+		if !ok || variable == nil {
+			return nil, fmt.Errorf("unknown or unsupported variable %s", e.Name)
+		}
+		return variable, nil
+	case *ast.BasicLit:
+		value := constant.MakeFromLiteral(e.Value, e.Kind, 0)
+		if value.Kind() != constant.Int {
+			return nil, fmt.Errorf("unsupported literal %s", e.Value)
+		}
+		number, exact := constant.Int64Val(value)
+		if !exact {
+			return nil, fmt.Errorf("literal %s overflows int64", e.Value)
+		}
+		return symbolic.NewIntConstant(number), nil
+	case *ast.UnaryExpr:
+		operand, err := translateClause(e.X, scope)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.NOT:
+			return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{operand}, symbolic.NOT), nil
+		case token.SUB:
+			return symbolic.NewBinaryOperation(symbolic.NewIntConstant(0), operand, symbolic.SUB), nil
+		case token.ADD:
+			return operand, nil
+		}
+		return nil, fmt.Errorf("unsupported operator %s", e.Op)
+	case *ast.BinaryExpr:
+		left, err := translateClause(e.X, scope)
+		if err != nil {
+			return nil, err
+		}
+		right, err := translateClause(e.Y, scope)
+		if err != nil {
+			return nil, err
+		}
+		if op, ok := clauseLogicalOperators[e.Op]; ok {
+			return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{left, right}, op), nil
+		}
+		if op, ok := clauseBinaryOperators[e.Op]; ok {
+			return symbolic.NewBinaryOperation(left, right, op), nil
+		}
+		return nil, fmt.Errorf("unsupported operator %s", e.Op)
+	default:
+		return nil, fmt.Errorf("unsupported expression %T", expr)
+	}
+}
@@ -0,0 +1,223 @@
+package ssa
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// requireExpressions пропускает тест, пока конструкторы выражений
+// из домашнего задания не реализованы
+func requireExpressions(t *testing.T) {
+	t.Helper()
+	defer func() {
+		if recover() != nil {
+			t.Skip("symbolic expression constructors are not implemented yet")
+		}
+	}()
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	_ = symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(0), symbolic.EQ).Type()
+	_ = symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{symbolic.NewBoolConstant(true)}, symbolic.NOT).Type()
+}
+
+// buildContracts строит SSA пакета и возвращает функции с их объявлениями
+func buildContracts(t *testing.T, source string) (map[string]*ssa.Function, *Builder) {
+	t.Helper()
+	builder := NewBuilder()
+	file, err := parser.ParseFile(builder.fset, "contracts.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.files = append(builder.files, file)
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	pkg, err := new(types.Config{Importer: importer.Default()}).Check("contracts", builder.fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	program := ssa.NewProgram(builder.fset, 0)
+	ssaPkg := program.CreatePackage(pkg, []*ast.File{file}, info, false)
+	ssaPkg.Build()
+
+	functions := make(map[string]*ssa.Function)
+	for _, member := range ssaPkg.Members {
+		if function, ok := member.(*ssa.Function); ok {
+			functions[function.Name()] = function
+		}
+	}
+	return functions, builder
+}
+
+const contractsSource = `package contracts
+
+//sym:requires n >= 0 && n <= 20
+//sym:ensures result >= 1
+func Factorial(n int) int { return 1 }
+
+// Div делит нацело
+//
+//sym:requires b != 0
+//sym:ensures q*b+r == a
+func Div(a, b int) (q, r int) { return a / b, a % b }
+
+//sym:ensures result0 == !flag && result1 == -x
+func Pair(x int, flag bool) (bool, int) { return !flag, -x }
+
+// Plain не имеет контракта
+func Plain() {}
+
+//sym:requires s != 0
+func Unsupported(s string) {}
+
+//sym:ensures n > 0
+func Input(n int) (out int) { return n }
+
+//sym:requires result > 0
+func ResultInRequires() int { return 1 }
+
+//sym:requires n +
+func Syntax(n int) {}
+
+//sym:requires n + 1
+func NotBoolean(n int) {}
+`
+
+func TestParseContract(t *testing.T) {
+	requireExpressions(t)
+	functions, builder := buildContracts(t, contractsSource)
+	tests := []struct {
+		function string
+		requires []string
+		ensures  []string
+		// assignments — присваивания, при которых условия должны выполняться
+		holds map[string]symbolic.SymbolicExpression
+		fails map[string]symbolic.SymbolicExpression
+	}{
+		{"Factorial", []string{"n >= 0 && n <= 20"}, []string{"result >= 1"},
+			map[string]symbolic.SymbolicExpression{"n": symbolic.NewIntConstant(3), "$result": symbolic.NewIntConstant(6)},
+			map[string]symbolic.SymbolicExpression{"n": symbolic.NewIntConstant(21), "$result": symbolic.NewIntConstant(0)}},
+		{"Div", []string{"b != 0"}, []string{"q*b+r == a"},
+			map[string]symbolic.SymbolicExpression{"a": symbolic.NewIntConstant(7), "b": symbolic.NewIntConstant(2), "$q": symbolic.NewIntConstant(3), "$r": symbolic.NewIntConstant(1)},
+			map[string]symbolic.SymbolicExpression{"a": symbolic.NewIntConstant(7), "b": symbolic.NewIntConstant(0), "$q": symbolic.NewIntConstant(3), "$r": symbolic.NewIntConstant(0)}},
+		{"Pair", nil, []string{"result0 == !flag && result1 == -x"},
+			map[string]symbolic.SymbolicExpression{"x": symbolic.NewIntConstant(2), "flag": symbolic.NewBoolConstant(false), "$result0": symbolic.NewBoolConstant(true), "$result1": symbolic.NewIntConstant(-2)},
+			map[string]symbolic.SymbolicExpression{"x": symbolic.NewIntConstant(2), "flag": symbolic.NewBoolConstant(true), "$result0": symbolic.NewBoolConstant(true), "$result1": symbolic.NewIntConstant(-2)}},
+	}
+	for _, test := range tests {
+		t.Run(test.function, func(t *testing.T) {
+			contract, err := builder.Contract(functions[test.function])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contract == nil {
+				t.Fatal("contract was not found")
+			}
+			check := func(clauses []Clause, want []string) {
+				if len(clauses) != len(want) {
+					t.Fatalf("parsed %d clauses, want %v", len(clauses), want)
+				}
+				for i, clause := range clauses {
+					if clause.Text != want[i] {
+						t.Errorf("clause text = %q, want %q", clause.Text, want[i])
+					}
+					if clause.Pos.Line == 0 {
+						t.Errorf("clause %q has no position", clause.Text)
+					}
+					if holds := evaluate(t, clause.Expr, test.holds); !holds {
+						t.Errorf("%q does not hold for %v", clause.Text, test.holds)
+					}
+				}
+			}
+			check(contract.Requires, test.requires)
+			check(contract.Ensures, test.ensures)
+
+			all := append(append([]Clause{}, contract.Requires...), contract.Ensures...)
+			violated := false
+			for _, clause := range all {
+				violated = violated || !evaluate(t, clause.Expr, test.fails)
+			}
+			if !violated {
+				t.Errorf("contract holds for %v", test.fails)
+			}
+		})
+	}
+}
+
+func evaluate(t *testing.T, expr symbolic.SymbolicExpression, assignment map[string]symbolic.SymbolicExpression) bool {
+	t.Helper()
+	value, err := symbolic.Evaluate(expr, assignment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value.(*symbolic.BoolConstant).Value
+}
+
+func TestContractResults(t *testing.T) {
+	requireExpressions(t)
+	functions, builder := buildContracts(t, contractsSource)
+	for name, want := range map[string][]string{
+		"Div":   {"$q", "$r"},
+		"Input": {"$out"},
+	} {
+		contract, err := builder.Contract(functions[name])
+		if err != nil || contract == nil {
+			t.Fatalf("%s: contract = %v, %v", name, contract, err)
+		}
+		if len(contract.Results) != len(want) {
+			t.Fatalf("%s: %d result variables, want %v", name, len(contract.Results), want)
+		}
+		for i, variable := range contract.Results {
+			if variable.Name != want[i] {
+				t.Errorf("%s: result %d = %s, want %s", name, i, variable.Name, want[i])
+			}
+		}
+	}
+}
+
+func TestParseContractErrors(t *testing.T) {
+	functions, builder := buildContracts(t, contractsSource)
+	if contract, err := builder.Contract(functions["Plain"]); contract != nil || err != nil {
+		t.Errorf("Plain: contract = %v, %v, want none", contract, err)
+	}
+	tests := []struct {
+		function string
+		message  string
+	}{
+		{"Unsupported", "unsupported variable s"},
+		{"ResultInRequires", "unknown or unsupported variable result"},
+		{"Syntax", "invalid contract"},
+	}
+	for _, test := range tests {
+		_, err := builder.Contract(functions[test.function])
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: error = %v, want %q", test.function, err, test.message)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), "contracts.go:") {
+			t.Errorf("%s: error %q has no position", test.function, err)
+		}
+	}
+}
+
+func TestParseClauseRejects(t *testing.T) {
+	scope := map[string]*symbolic.SymbolicVariable{"n": symbolic.NewSymbolicVariable("n", symbolic.IntType)}
+	for _, text := range []string{`n == "a"`, "n & 1 == 0", "1.5 < n", "f(n)", "n == 99999999999999999999", "^n == 0"} {
+		if expr, err := parseClause(text, scope); err == nil {
+			t.Errorf("parseClause(%q) = %v, expected error", text, expr)
+		}
+	}
+
+	requireExpressions(t)
+	if expr, err := parseClause("n + 1", scope); err == nil {
+		t.Errorf("parseClause(n + 1) = %v, expected a non-boolean error", expr)
+	}
+}
@@ -0,0 +1,115 @@
+package ssa
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// DotOption настраивает вывод графа потока управления
+type DotOption func(config *dotConfig)
+
+type dotConfig struct {
+	coverage map[int]int
+}
+
+// WithBlockCoverage накладывает на граф число проходов каждого блока
+// (по номеру блока), например, полученное из результатов анализа
+func WithBlockCoverage(counts map[int]int) DotOption {
+	return func(config *dotConfig) {
+		config.coverage = counts
+	}
+}
+
+// DOT возвращает граф потока управления функции в формате Graphviz:
+// блоки с инструкциями SSA, рёбра переходов (обратные рёбра циклов —
+// жирные), дерево доминаторов (пунктир) и выделенные заголовки циклов
+func (b *Builder) DOT(function *ssa.Function, options ...DotOption) string {
+	config := &dotConfig{}
+	for _, option := range options {
+		option(config)
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "digraph %q {\n", function.String())
+	builder.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	headers := loopHeaders(function)
+	for _, block := range function.Blocks {
+		lines := []string{fmt.Sprintf("block %d (%s)", block.Index, block.Comment)}
+		if headers[block] {
+			lines[0] += " loop header"
+		}
+		if config.coverage != nil {
+			lines = append(lines, fmt.Sprintf("hits: %d", config.coverage[block.Index]))
+		}
+		for _, instruction := range block.Instrs {
+			lines = append(lines, instructionText(instruction))
+		}
+
+		attributes := []string{fmt.Sprintf("label=\"%s\\l\"", escapeDot(lines))}
+		if headers[block] {
+			attributes = append(attributes, "penwidth=3", "color=blue")
+		}
+		if config.coverage != nil {
+			fill := "mistyrose"
+			if config.coverage[block.Index] > 0 {
+				fill = "palegreen"
+			}
+			attributes = append(attributes, "style=filled", "fillcolor="+fill)
+		}
+		fmt.Fprintf(&builder, "\tb%d [%s];\n", block.Index, strings.Join(attributes, ", "))
+	}
+
+	for _, block := range function.Blocks {
+		for i, successor := range block.Succs {
+			var attributes []string
+			if len(block.Succs) == 2 {
+				attributes = append(attributes, fmt.Sprintf("label=%q", []string{"true", "false"}[i]))
+			}
+			if successor.Dominates(block) {
+				attributes = append(attributes, "style=bold")
+			}
+			fmt.Fprintf(&builder, "\tb%d -> b%d [%s];\n", block.Index, successor.Index, strings.Join(attributes, ", "))
+		}
+	}
+	for _, block := range function.Blocks {
+		if idom := block.Idom(); idom != nil {
+			fmt.Fprintf(&builder, "\tb%d -> b%d [style=dashed, color=gray, constraint=false];\n", idom.Index, block.Index)
+		}
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// loopHeaders возвращает блоки, в которые ведут обратные рёбра: переход
+// из блока, доминируемого целевым
+func loopHeaders(function *ssa.Function) map[*ssa.BasicBlock]bool {
+	headers := make(map[*ssa.BasicBlock]bool)
+	for _, block := range function.Blocks {
+		for _, successor := range block.Succs {
+			if successor.Dominates(block) {
+				headers[successor] = true
+			}
+		}
+	}
+	return headers
+}
+
+// instructionText возвращает инструкцию вместе с именем её значения
+func instructionText(instruction ssa.Instruction) string {
+	if value, ok := instruction.(ssa.Value); ok && value.Name() != "" {
+		return fmt.Sprintf("%s = %s", value.Name(), instruction)
+	}
+	return instruction.String()
+}
+
+// escapeDot экранирует строки для метки Graphviz и выравнивает их по левому краю
+func escapeDot(lines []string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ")
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = replacer.Replace(line)
+	}
+	return strings.Join(escaped, `\l`)
+}
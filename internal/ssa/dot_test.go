@@ -0,0 +1,140 @@
+package ssa
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+const loopsSource = `package loops
+
+func Straight(x int) int {
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+func Sum(n int) int {
+	total := 0
+	for i := 0; i < n; i++ {
+		total += i
+	}
+	return total
+}
+
+func Nested(n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			count++
+		}
+	}
+	return count
+}
+
+func Forever() {
+	for {
+	}
+}
+`
+
+// headerComments возвращает комментарии блоков-заголовков циклов в порядке номеров
+func headerComments(function *ssa.Function) []string {
+	var comments []string
+	for block := range loopHeaders(function) {
+		comments = append(comments, fmt.Sprintf("%d:%s", block.Index, block.Comment))
+	}
+	sort.Strings(comments)
+	for i, comment := range comments {
+		comments[i] = comment[strings.Index(comment, ":")+1:]
+	}
+	return comments
+}
+
+func TestLoopHeaders(t *testing.T) {
+	functions, _ := buildContracts(t, loopsSource)
+	tests := []struct {
+		function string
+		headers  []string
+	}{
+		{"Straight", nil},
+		{"Sum", []string{"for.loop"}},
+		{"Nested", []string{"for.loop", "for.loop"}},
+		{"Forever", []string{"for.body"}},
+	}
+	for _, test := range tests {
+		got := headerComments(functions[test.function])
+		if strings.Join(got, ",") != strings.Join(test.headers, ",") {
+			t.Errorf("%s: loop headers = %v, want %v", test.function, got, test.headers)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	functions, builder := buildContracts(t, loopsSource)
+	sum := functions["Sum"]
+	var header *ssa.BasicBlock
+	for block := range loopHeaders(sum) {
+		header = block
+	}
+	dot := builder.DOT(sum)
+
+	for _, want := range []string{
+		"digraph \"contracts.Sum\" {\n",
+		fmt.Sprintf("\tb%d [label=\"block %d (for.loop) loop header\\l", header.Index, header.Index),
+		"penwidth=3, color=blue];\n",
+		"[label=\"true\"]",
+		"[label=\"false\"]",
+		"[style=dashed, color=gray, constraint=false];\n",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT misses %q:\n%s", want, dot)
+		}
+	}
+	// Обратное ребро цикла ведёт в заголовок и выделено жирным
+	backEdges := 0
+	for _, predecessor := range header.Preds {
+		if header.Dominates(predecessor) {
+			backEdges++
+			if !strings.Contains(dot, fmt.Sprintf("\tb%d -> b%d [style=bold];\n", predecessor.Index, header.Index)) {
+				t.Errorf("back edge b%d -> b%d is not bold:\n%s", predecessor.Index, header.Index, dot)
+			}
+		}
+	}
+	if backEdges != 1 {
+		t.Errorf("%d back edges into the loop header, want 1", backEdges)
+	}
+	if strings.Contains(dot, "hits:") || strings.Contains(dot, "fillcolor") {
+		t.Errorf("coverage drawn without WithBlockCoverage:\n%s", dot)
+	}
+}
+
+func TestDOTWithBlockCoverage(t *testing.T) {
+	functions, builder := buildContracts(t, loopsSource)
+	straight := functions["Straight"]
+	then := straight.Blocks[0].Succs[0]
+	dot := builder.DOT(straight, WithBlockCoverage(map[int]int{0: 2, then.Index: 2}))
+
+	for _, block := range straight.Blocks {
+		hits, fill := 0, "mistyrose"
+		if block.Index == 0 || block == then {
+			hits, fill = 2, "palegreen"
+		}
+		line := dot[strings.Index(dot, fmt.Sprintf("\tb%d [", block.Index)):]
+		line = line[:strings.Index(line, "\n")]
+		if !strings.Contains(line, fmt.Sprintf("\\lhits: %d\\l", hits)) || !strings.HasSuffix(line, "style=filled, fillcolor="+fill+"];") {
+			t.Errorf("block %d: %s, want %d hits filled %s", block.Index, line, hits, fill)
+		}
+	}
+}
+
+func TestEscapeDot(t *testing.T) {
+	got := escapeDot([]string{`t0 = "a\b"`, "multi\nline"})
+	if want := `t0 = \"a\\b\"\lmulti line`; got != want {
+		t.Errorf("escapeDot = %s, want %s", got, want)
+	}
+}
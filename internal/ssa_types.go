@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"go/types"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// expressionType возвращает тип символьного выражения для типа Go
+func expressionType(tpe types.Type) (symbolic.ExpressionType, bool) {
+	switch underlying := tpe.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case underlying.Info()&types.IsBoolean != 0:
+			return symbolic.BoolType, true
+		case underlying.Info()&types.IsInteger != 0:
+			return symbolic.IntType, true
+		}
+	case *types.Array, *types.Slice:
+		return symbolic.ArrayType, true
+	case *types.Chan:
+		// Канал представляется своим номером в планировщике
+		return symbolic.IntType, true
+	}
+	return 0, false
+}
+
+// zeroValue возвращает нулевое значение типа Go
+func zeroValue(tpe types.Type) (symbolic.SymbolicExpression, bool) {
+	if _, ok := tpe.Underlying().(*types.Chan); ok {
+		return symbolic.NewIntConstant(nilChannel), true
+	}
+	exprType, ok := expressionType(tpe)
+	switch {
+	case ok && exprType == symbolic.IntType:
+		return symbolic.NewIntConstant(0), true
+	case ok && exprType == symbolic.BoolType:
+		return symbolic.NewBoolConstant(false), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,333 @@
+package internal
+
+import (
+	"container/heap"
+	"math"
+	"slices"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// Ограничения эвристики слияния: объединение большего числа значений или
+// более длинных условий путей усложняет запросы сильнее, чем экономит
+// исследование отдельных состояний
+const (
+	mergeValueLimit     = 8
+	mergeConditionLimit = 256
+)
+
+// WithStateMerging включает слияние состояний, дошедших до одного блока
+// с несколькими предшественниками с одинаковым стеком вызовов. Память
+// Heap объединяется через memory.Merger; состояния с памятью, которая его
+// не реализует, сливаются, только если память у них общая.
+func WithStateMerging() Option {
+	return func(analyser *Analyser) {
+		analyser.MergeStates = true
+	}
+}
+
+// atJoin отмечает, что состояние исполнило φ-узлы текущего блока и, если
+// у блока несколько предшественников, может быть объединено с другими
+// состояниями в нём. Отметка снимается следующей исполненной инструкцией.
+// Интерпретатор вызывает atJoin после φ-узлов блока и возвращает состояние
+// в очередь через push.
+func (interpreter *Interpreter) atJoin() {
+	if len(interpreter.Blocks) == 0 {
+		return
+	}
+	if block := interpreter.Blocks[len(interpreter.Blocks)-1]; len(block.Preds) > 1 {
+		interpreter.joinPoint = block
+	}
+}
+
+// push добавляет состояние в очередь с приоритетом PathSelector. При
+// включённом слиянии состояние в точке соединения объединяется с
+// ожидающим там же состоянием, а если такого нет — откладывается до
+// исполнения остальных состояний, чтобы они успели дойти до этой точки.
+// Через push интерпретатор добавляет в очередь все новые состояния.
+func (analyser *Analyser) push(state Interpreter) {
+	if !analyser.MergeStates || state.joinPoint == nil {
+		heap.Push(&analyser.StatesQueue, &Item{value: state, priority: analyser.PathSelector.CalculatePriority(state)})
+		return
+	}
+	for _, item := range analyser.StatesQueue {
+		if merged, ok := analyser.merge(item.value, state); ok {
+			analyser.StatesQueue.update(item, merged, item.priority)
+			return
+		}
+	}
+	// Отложенные состояния извлекаются после остальных в порядке поступления
+	analyser.postponed++
+	heap.Push(&analyser.StatesQueue, &Item{value: state, priority: math.MinInt/2 - analyser.postponed})
+}
+
+// merge объединяет два состояния в одной точке соединения. Условие пути
+// результата — общая часть условий и дизъюнкция различающихся, значения,
+// различающиеся в состояниях, выбираются по условию пути первого, а
+// пройденные блоки и инструкции объединяют оба пути.
+// Возвращает false, если состояния несовместимы или слияние отвергнуто
+// эвристикой.
+func (analyser *Analyser) merge(first, second Interpreter) (Interpreter, bool) {
+	if !mergeable(first, second) {
+		return Interpreter{}, false
+	}
+	common, firstRest, secondRest := splitConditions(first.PathCondition, second.PathCondition)
+	if len(firstRest) == 0 || len(secondRest) == 0 {
+		return Interpreter{}, false
+	}
+	guard, otherwise := conjunction(firstRest), conjunction(secondRest)
+	if expressionSize(guard)+expressionSize(otherwise) > mergeConditionLimit {
+		return Interpreter{}, false
+	}
+
+	// Объединённое состояние занимает место первого в очереди и его узел
+	// в дереве исполнения, поэтому копируется без fork
+	merged := first
+	if first.Heap != second.Heap {
+		var ok bool
+		if merged.Heap, ok = first.Heap.(memory.Merger).Merge(second.Heap, guard); !ok {
+			return Interpreter{}, false
+		}
+	}
+	merged.CallStack = cloneCallStack(first.CallStack)
+	joiner := &valueJoiner{guard: guard}
+	for i := range merged.CallStack {
+		frame, other := &merged.CallStack[i], second.CallStack[i]
+		for name, value := range frame.LocalMemory {
+			otherValue, ok := other.LocalMemory[name]
+			if !ok {
+				// Значение определено лишь на одном из путей и после
+				// соединения не используется
+				delete(frame.LocalMemory, name)
+				continue
+			}
+			if frame.LocalMemory[name], ok = joiner.join(value, otherValue); !ok {
+				return Interpreter{}, false
+			}
+		}
+		if frame.ReturnValue != nil || other.ReturnValue != nil {
+			var ok bool
+			if frame.ReturnValue, ok = joiner.join(frame.ReturnValue, other.ReturnValue); !ok {
+				return Interpreter{}, false
+			}
+		}
+		if len(frame.ReturnValues) != len(other.ReturnValues) {
+			return Interpreter{}, false
+		}
+		frame.ReturnValues = slices.Clone(frame.ReturnValues)
+		for j := range frame.ReturnValues {
+			var ok bool
+			if frame.ReturnValues[j], ok = joiner.join(frame.ReturnValues[j], other.ReturnValues[j]); !ok {
+				return Interpreter{}, false
+			}
+		}
+	}
+	if joiner.joined > mergeValueLimit {
+		return Interpreter{}, false
+	}
+
+	merged.PathCondition = conjunction(append(common,
+		symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{guard, otherwise}, symbolic.OR)))
+	merged.Branches = commonPrefix(first.Branches, second.Branches)
+	merged.Blocks = joinPaths(first.Blocks, second.Blocks)
+	merged.Executed = joinPaths(first.Executed, second.Executed)
+	merged.Incomplete = first.Incomplete || second.Incomplete
+	analyser.mergedNode(first, second)
+	return merged, true
+}
+
+// mergeable проверяет, что состояния стоят в одной точке соединения с
+// одинаковым стеком вызовов и не содержат того, что нельзя объединить:
+// найденных ошибок, конкретизаций, горутин; память у них должна быть общей
+// или поддерживать memory.Merger
+func mergeable(first, second Interpreter) bool {
+	if first.joinPoint == nil || first.joinPoint != second.joinPoint ||
+		len(first.CallStack) != len(second.CallStack) {
+		return false
+	}
+	if first.Heap != second.Heap {
+		if _, ok := first.Heap.(memory.Merger); !ok || second.Heap == nil {
+			return false
+		}
+	}
+	for i := range first.CallStack {
+		if first.CallStack[i].Function != second.CallStack[i].Function {
+			return false
+		}
+	}
+	for _, state := range []Interpreter{first, second} {
+		if state.Terminated || state.Scheduler != nil || state.IsConcolic() ||
+			len(state.Findings) > 0 || len(state.Concretizations) > 0 || state.PathCondition == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// valueJoiner объединяет целые и булевы значения двух состояний выражением
+// выбора guard ? a : b. Различающиеся ссылки и массивы не объединяются.
+type valueJoiner struct {
+	guard  symbolic.SymbolicExpression
+	joined int
+}
+
+func (joiner *valueJoiner) join(mine, theirs symbolic.SymbolicExpression) (symbolic.SymbolicExpression, bool) {
+	if mine == theirs {
+		return mine, true
+	}
+	if mine == nil || theirs == nil {
+		return nil, false
+	}
+	if _, ok := mine.(*symbolic.Ref); ok {
+		return nil, false
+	}
+	if _, ok := theirs.(*symbolic.Ref); ok {
+		return nil, false
+	}
+	if mine.Type() != theirs.Type() {
+		return nil, false
+	}
+	if mine.String() == theirs.String() {
+		return mine, true
+	}
+
+	switch mine.Type() {
+	case symbolic.IntType, symbolic.BoolType:
+		joiner.joined++
+		return symbolic.Simplify(symbolic.NewITE(joiner.guard, mine, theirs)), true
+	default:
+		// Массивы объединяются вместе с памятью Heap (memory.Merger)
+		return nil, false
+	}
+}
+
+// splitConditions разбивает условия путей на общие конъюнкты, добавленные
+// до расхождения путей, и собственные конъюнкты каждого пути
+func splitConditions(first, second symbolic.SymbolicExpression) (common, firstRest, secondRest []symbolic.SymbolicExpression) {
+	firstConjuncts, secondConjuncts := conjuncts(first), conjuncts(second)
+	common = commonPrefix(firstConjuncts, secondConjuncts)
+	return common, firstConjuncts[len(common):], secondConjuncts[len(common):]
+}
+
+// conjuncts раскрывает вложенные конъюнкции, построенные addConstraint
+func conjuncts(condition symbolic.SymbolicExpression) []symbolic.SymbolicExpression {
+	if condition == nil {
+		return nil
+	}
+	operation, ok := condition.(*symbolic.LogicalOperation)
+	if !ok || operation.Operator != symbolic.AND {
+		return []symbolic.SymbolicExpression{condition}
+	}
+	var result []symbolic.SymbolicExpression
+	for _, operand := range operation.Operands {
+		result = append(result, conjuncts(operand)...)
+	}
+	return result
+}
+
+// conjunction строит конъюнкцию условий
+func conjunction(conditions []symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	switch len(conditions) {
+	case 0:
+		return symbolic.NewBoolConstant(true)
+	case 1:
+		return conditions[0]
+	default:
+		return symbolic.NewLogicalOperation(slices.Clone(conditions), symbolic.AND)
+	}
+}
+
+// commonPrefix возвращает общее начало последовательностей условий: пути
+// разделяют одни и те же выражения, добавленные до их расхождения
+func commonPrefix(first, second []symbolic.SymbolicExpression) []symbolic.SymbolicExpression {
+	n := 0
+	for n < len(first) && n < len(second) && first[n] == second[n] {
+		n++
+	}
+	return slices.Clip(first[:n])
+}
+
+// joinPaths объединяет последовательности двух путей, сошедшихся в одной
+// точке: первый путь до общего окончания, затем собственный участок второго
+// и общее окончание. Если у первого пути есть собственный участок, участок
+// второго начинается с последнего общего элемента, чтобы сохранить ребро,
+// по которому второй путь разошёлся с первым.
+func joinPaths[T comparable](first, second []T) []T {
+	prefix := 0
+	for prefix < len(first) && prefix < len(second) && first[prefix] == second[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(first)-prefix && suffix < len(second)-prefix &&
+		first[len(first)-1-suffix] == second[len(second)-1-suffix] {
+		suffix++
+	}
+	start := prefix
+	if prefix > 0 && prefix < len(first)-suffix {
+		start--
+	}
+	result := slices.Clone(first[:len(first)-suffix])
+	result = append(result, second[start:len(second)-suffix]...)
+	return append(result, first[len(first)-suffix:]...)
+}
+
+// mergedNode отмечает в дереве исполнения, что второе состояние влилось в первое
+func (analyser *Analyser) mergedNode(first, second Interpreter) {
+	if analyser.Tree == nil {
+		return
+	}
+	if node := analyser.Tree.node(second.Node); node != nil {
+		node.MergedInto = first.Node
+	}
+}
+
+// sizeCounter считает число узлов выражения (Visitor Pattern)
+type sizeCounter struct {
+	size int
+}
+
+// expressionSize возвращает число узлов выражения
+func expressionSize(expr symbolic.SymbolicExpression) int {
+	counter := &sizeCounter{}
+	expr.Accept(counter)
+	return counter.size
+}
+
+func (sc *sizeCounter) VisitVariable(expr *symbolic.SymbolicVariable) interface{} {
+	sc.size++
+	return nil
+}
+
+func (sc *sizeCounter) VisitIntConstant(expr *symbolic.IntConstant) interface{} {
+	sc.size++
+	return nil
+}
+
+func (sc *sizeCounter) VisitBoolConstant(expr *symbolic.BoolConstant) interface{} {
+	sc.size++
+	return nil
+}
+
+func (sc *sizeCounter) VisitBinaryOperation(expr *symbolic.BinaryOperation) interface{} {
+	sc.size++
+	expr.Left.Accept(sc)
+	expr.Right.Accept(sc)
+	return nil
+}
+
+func (sc *sizeCounter) VisitLogicalOperation(expr *symbolic.LogicalOperation) interface{} {
+	sc.size++
+	for _, operand := range expr.Operands {
+		operand.Accept(sc)
+	}
+	return nil
+}
+
+func (sc *sizeCounter) VisitITE(expr *symbolic.ITE) interface{} {
+	sc.size++
+	expr.Condition.Accept(sc)
+	expr.Then.Accept(sc)
+	expr.Else.Accept(sc)
+	return nil
+}
@@ -0,0 +1,378 @@
+package internal
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+const diamondSource = `package main
+
+func pick(x int) int {
+	y := 3
+	if x > 0 {
+		y = 2
+	} else {
+		y = 1
+	}
+	return y
+}
+`
+
+// diamond строит два состояния функции pick, дошедшие до точки соединения
+// по ветвям x > 0 и x <= 0 с общим условием x < 100
+func diamond(t *testing.T) (first, second Interpreter) {
+	t.Helper()
+	function := buildFunction(t, diamondSource, "pick")
+	entry := function.Blocks[0]
+	then, otherwise := entry.Succs[0], entry.Succs[1]
+	join := then.Succs[0]
+	x := intVar("x")
+	common := compare(x, symbolic.LT, intConst(100))
+	state := func(taken *ssa.BasicBlock, branch symbolic.SymbolicExpression, y int64) Interpreter {
+		interpreter := Interpreter{
+			CallStack: []CallStackFrame{{
+				Function:    function,
+				LocalMemory: map[string]symbolic.SymbolicExpression{"x": x, "y": intConst(y)},
+			}},
+			PathCondition: logical(symbolic.AND, common, branch),
+			Branches:      []symbolic.SymbolicExpression{branch},
+			Blocks:        []*ssa.BasicBlock{entry, taken, join},
+		}
+		interpreter.atJoin()
+		return interpreter
+	}
+	return state(then, compare(x, symbolic.GT, intConst(0)), 2), state(otherwise, compare(x, symbolic.LE, intConst(0)), 1)
+}
+
+func TestMerge(t *testing.T) {
+	requireExpressions(t)
+	first, second := diamond(t)
+	first.CallStack[0].LocalMemory["t0"] = boolVar("t0")
+
+	merged, ok := newTestAnalyser(newFakeSolver(2)).merge(first, second)
+	if !ok {
+		t.Fatal("states at the same join point were not merged")
+	}
+	condition, ok := merged.PathCondition.(*symbolic.LogicalOperation)
+	if !ok || condition.Operator != symbolic.AND || len(condition.Operands) != 2 ||
+		condition.Operands[0] != conjuncts(first.PathCondition)[0] {
+		t.Fatalf("path condition = %s, want the common conjunct and a disjunction", merged.PathCondition)
+	}
+	if either, ok := condition.Operands[1].(*symbolic.LogicalOperation); !ok || either.Operator != symbolic.OR ||
+		either.Operands[0] != first.Branches[0] || either.Operands[1] != second.Branches[0] {
+		t.Errorf("disjunction = %s", condition.Operands[1])
+	}
+
+	locals := merged.CallStack[0].LocalMemory
+	if locals["x"] != first.CallStack[0].LocalMemory["x"] {
+		t.Errorf("x = %s, equal values must not be wrapped", locals["x"])
+	}
+	y, ok := locals["y"].(*symbolic.ITE)
+	if !ok || y.Condition != first.Branches[0] || y.Then.String() != "2" || y.Else.String() != "1" {
+		t.Errorf("y = %s, want x > 0 ? 2 : 1", locals["y"])
+	}
+	if _, ok := locals["t0"]; ok {
+		t.Error("a value defined on one path only was kept")
+	}
+	if _, ok := first.CallStack[0].LocalMemory["y"].(*symbolic.IntConstant); !ok {
+		t.Error("merge changed the frame of the first state")
+	}
+
+	if len(merged.Branches) != 0 {
+		t.Errorf("branches = %v, the paths share no branch", merged.Branches)
+	}
+	entry, join := first.Blocks[0], first.Blocks[2]
+	blocks := []*ssa.BasicBlock{entry, first.Blocks[1], entry, second.Blocks[1], join}
+	if !slices.Equal(merged.Blocks, blocks) {
+		t.Errorf("blocks = %v, want both paths", merged.Blocks)
+	}
+	counts := BlockCounts(entry.Parent(), []Interpreter{merged})
+	if len(counts) != 4 {
+		t.Errorf("block counts = %v, want every block of the diamond", counts)
+	}
+	report := newTestAnalyser(newFakeSolver(2)).Coverage(entry.Parent(), []Interpreter{merged})
+	if report.CoveredBranches != report.Branches {
+		t.Errorf("branches %d/%d, the merged state covers both", report.CoveredBranches, report.Branches)
+	}
+}
+
+func TestMergeRejected(t *testing.T) {
+	requireExpressions(t)
+	heap := &memory.SymbolicMemory{}
+	tests := []struct {
+		name    string
+		prepare func(first, second *Interpreter)
+	}{
+		{"different join points", func(first, second *Interpreter) { second.joinPoint = second.Blocks[1] }},
+		{"not at a join point", func(first, second *Interpreter) { first.joinPoint, second.joinPoint = nil, nil }},
+		{"different heaps", func(first, second *Interpreter) { first.Heap, second.Heap = heap, &memory.SymbolicMemory{} }},
+		{"finding", func(first, second *Interpreter) { second.Findings = []Finding{{Kind: GoroutinePanic}} }},
+		{"terminated", func(first, second *Interpreter) { first.Terminated = true }},
+		{"same path condition", func(first, second *Interpreter) { second.PathCondition = first.PathCondition }},
+		{"different value types", func(first, second *Interpreter) {
+			second.CallStack[0].LocalMemory["y"] = boolVar("y")
+		}},
+		{"different result counts", func(first, second *Interpreter) {
+			first.CallStack[0].ReturnValues = []symbolic.SymbolicExpression{intConst(1)}
+		}},
+		{"too many joined values", func(first, second *Interpreter) {
+			for i := range mergeValueLimit {
+				name := string(rune('a' + i))
+				first.CallStack[0].LocalMemory[name] = intConst(int64(i))
+				second.CallStack[0].LocalMemory[name] = intConst(int64(-i - 1))
+			}
+		}},
+		{"condition too large", func(first, second *Interpreter) {
+			large := first.Branches[0]
+			for range mergeConditionLimit {
+				large = logical(symbolic.NOT, large)
+			}
+			second.PathCondition = logical(symbolic.AND, conjuncts(second.PathCondition)[0], large)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			first, second := diamond(t)
+			test.prepare(&first, &second)
+			if merged, ok := newTestAnalyser(newFakeSolver(2)).merge(first, second); ok {
+				t.Errorf("merged into %s", merged.PathCondition)
+			}
+		})
+	}
+
+	t.Run("shared heap", func(t *testing.T) {
+		first, second := diamond(t)
+		first.Heap, second.Heap = heap, heap
+		if merged, ok := newTestAnalyser(newFakeSolver(2)).merge(first, second); !ok || merged.Heap != heap {
+			t.Errorf("states sharing memory were not merged")
+		}
+	})
+}
+
+// fakeMemory — память для тестов слияния: значения полей по ссылке и номеру
+type fakeMemory struct {
+	cells map[fakeCell]symbolic.SymbolicExpression
+}
+
+type fakeCell struct {
+	ref   *symbolic.Ref
+	index int
+}
+
+func (mem *fakeMemory) Allocate(tpe symbolic.ExpressionType) *symbolic.Ref { return &symbolic.Ref{} }
+
+func (mem *fakeMemory) AssignField(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) {
+	mem.cells[fakeCell{ref, fieldIdx}] = value
+}
+
+func (mem *fakeMemory) GetFieldValue(ref *symbolic.Ref, fieldIdx int) symbolic.SymbolicExpression {
+	return mem.cells[fakeCell{ref, fieldIdx}]
+}
+
+func (mem *fakeMemory) AssignToArray(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression) {
+	mem.AssignField(ref, index, value)
+}
+
+func (mem *fakeMemory) GetFromArray(ref *symbolic.Ref, index int) symbolic.SymbolicExpression {
+	return mem.GetFieldValue(ref, index)
+}
+
+func (mem *fakeMemory) Clone() memory.Memory { return &fakeMemory{cells: maps.Clone(mem.cells)} }
+
+func (mem *fakeMemory) Merge(other memory.Memory, guard symbolic.SymbolicExpression) (memory.Memory, bool) {
+	theirs, ok := other.(*fakeMemory)
+	if !ok || len(theirs.cells) != len(mem.cells) {
+		return nil, false
+	}
+	merged := &fakeMemory{cells: make(map[fakeCell]symbolic.SymbolicExpression, len(mem.cells))}
+	for cell, value := range mem.cells {
+		otherValue, ok := theirs.cells[cell]
+		if !ok {
+			return nil, false
+		}
+		merged.cells[cell] = value
+		if value != otherValue {
+			merged.cells[cell] = symbolic.NewITE(guard, value, otherValue)
+		}
+	}
+	return merged, true
+}
+
+func TestMergeForkedHeap(t *testing.T) {
+	requireExpressions(t)
+	ref := &symbolic.Ref{}
+	prepare := func() (first, second Interpreter) {
+		first, second = diamond(t)
+		first.Heap = &fakeMemory{cells: map[fakeCell]symbolic.SymbolicExpression{{ref, 0}: intConst(0), {ref, 1}: intConst(5)}}
+		// Как после fork: у второго состояния своя копия памяти
+		second.Heap = first.Heap.Clone()
+		first.Heap.AssignField(ref, 0, intConst(2))
+		second.Heap.AssignField(ref, 0, intConst(1))
+		return first, second
+	}
+
+	first, second := prepare()
+	merged, ok := newTestAnalyser(newFakeSolver(2)).merge(first, second)
+	if !ok {
+		t.Fatal("states with forked memory were not merged")
+	}
+	field, ok := merged.Heap.GetFieldValue(ref, 0).(*symbolic.ITE)
+	if !ok || field.Condition != first.Branches[0] || field.Then.String() != "2" || field.Else.String() != "1" {
+		t.Errorf("field 0 = %s, want x > 0 ? 2 : 1", merged.Heap.GetFieldValue(ref, 0))
+	}
+	if value := merged.Heap.GetFieldValue(ref, 1); value.String() != "5" {
+		t.Errorf("field 1 = %s, equal values must not be wrapped", value)
+	}
+	if value := first.Heap.GetFieldValue(ref, 0); value.String() != "2" {
+		t.Errorf("merge changed the memory of the first state: field 0 = %s", value)
+	}
+
+	first, second = prepare()
+	second.Heap.AssignField(ref, 2, intConst(0))
+	if merged, ok := newTestAnalyser(newFakeSolver(2)).merge(first, second); ok {
+		t.Errorf("memory with different cells merged into %v", merged.Heap)
+	}
+	first, second = prepare()
+	second.Heap = nil
+	if _, ok := newTestAnalyser(newFakeSolver(2)).merge(first, second); ok {
+		t.Error("a state without memory was merged with one that has it")
+	}
+}
+
+func TestPushMergesStates(t *testing.T) {
+	requireExpressions(t)
+	first, second := diamond(t)
+	analyser := newTestAnalyser(newFakeSolver(2), WithStateMerging(), WithExecutionTree())
+	first.Node, second.Node = analyser.Tree.add(0), analyser.Tree.add(0)
+	analyser.push(first)
+	analyser.push(second)
+	if len(analyser.StatesQueue) != 1 {
+		t.Fatalf("%d states queued, want the merged one", len(analyser.StatesQueue))
+	}
+	if _, ok := analyser.StatesQueue[0].value.CallStack[0].LocalMemory["y"].(*symbolic.ITE); !ok {
+		t.Error("queued state is not the merged one")
+	}
+	if node := analyser.Tree.node(second.Node); node.MergedInto != first.Node {
+		t.Errorf("merged node points to %d, want %d", node.MergedInto, first.Node)
+	}
+
+	// Без WithStateMerging состояния не объединяются
+	analyser = newTestAnalyser(newFakeSolver(2))
+	analyser.push(first)
+	analyser.push(second)
+	if len(analyser.StatesQueue) != 2 {
+		t.Errorf("%d states queued, want 2", len(analyser.StatesQueue))
+	}
+}
+
+func TestSplitConditions(t *testing.T) {
+	a, b, c, d := boolVar("a"), boolVar("b"), boolVar("c"), boolVar("d")
+	// Разные узлы с одинаковым текстом не считаются общими
+	either, same := logical(symbolic.OR, a, b), logical(symbolic.OR, a, b)
+	tests := []struct {
+		name                    string
+		first, second           symbolic.SymbolicExpression
+		common, firstRest, rest []symbolic.SymbolicExpression
+	}{
+		{"diverged after a shared prefix", logical(symbolic.AND, logical(symbolic.AND, a, b), c), logical(symbolic.AND, a, b, d),
+			[]symbolic.SymbolicExpression{a, b}, []symbolic.SymbolicExpression{c}, []symbolic.SymbolicExpression{d}},
+		{"no shared prefix", c, d, nil, []symbolic.SymbolicExpression{c}, []symbolic.SymbolicExpression{d}},
+		{"one extends the other", a, logical(symbolic.AND, a, b),
+			[]symbolic.SymbolicExpression{a}, nil, []symbolic.SymbolicExpression{b}},
+		{"disjunction is a single conjunct", either, same,
+			nil, []symbolic.SymbolicExpression{either}, []symbolic.SymbolicExpression{same}},
+		{"missing condition", nil, a, nil, nil, []symbolic.SymbolicExpression{a}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			common, firstRest, secondRest := splitConditions(test.first, test.second)
+			if !slices.Equal(common, test.common) || !slices.Equal(firstRest, test.firstRest) || !slices.Equal(secondRest, test.rest) {
+				t.Errorf("split = %v, %v, %v; want %v, %v, %v", common, firstRest, secondRest, test.common, test.firstRest, test.rest)
+			}
+		})
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	a, b, c := boolVar("a"), boolVar("b"), boolVar("c")
+	tests := []struct {
+		first, second, want []symbolic.SymbolicExpression
+	}{
+		{[]symbolic.SymbolicExpression{a, b}, []symbolic.SymbolicExpression{a, c}, []symbolic.SymbolicExpression{a}},
+		{[]symbolic.SymbolicExpression{a, b}, []symbolic.SymbolicExpression{a, b, c}, []symbolic.SymbolicExpression{a, b}},
+		{[]symbolic.SymbolicExpression{a}, []symbolic.SymbolicExpression{boolVar("a")}, []symbolic.SymbolicExpression{}},
+		{nil, []symbolic.SymbolicExpression{a}, nil},
+	}
+	for _, test := range tests {
+		got := commonPrefix(test.first, test.second)
+		if !slices.Equal(got, test.want) {
+			t.Errorf("commonPrefix(%v, %v) = %v, want %v", test.first, test.second, got, test.want)
+		}
+		// Добавление к результату не должно портить исходную последовательность
+		if cap(got) != len(got) {
+			t.Errorf("prefix %v shares spare capacity with %v", got, test.first)
+		}
+	}
+}
+
+func TestJoinPaths(t *testing.T) {
+	tests := []struct {
+		first, second, want []int
+	}{
+		{[]int{0, 1, 3}, []int{0, 2, 3}, []int{0, 1, 0, 2, 3}},
+		{[]int{0, 1, 4, 5, 6}, []int{0, 1, 2, 3, 6}, []int{0, 1, 4, 5, 1, 2, 3, 6}},
+		{[]int{1, 3}, []int{2, 3}, []int{1, 2, 3}},
+		{[]int{0, 1, 3}, []int{0, 1, 3}, []int{0, 1, 3}},
+		{[]int{0, 3}, []int{0, 1, 3}, []int{0, 1, 3}},
+		{[]int{0, 1, 3}, []int{0, 3}, []int{0, 1, 0, 3}},
+		{nil, nil, nil},
+	}
+	for _, test := range tests {
+		if got := joinPaths(test.first, test.second); !slices.Equal(got, test.want) {
+			t.Errorf("joinPaths(%v, %v) = %v, want %v", test.first, test.second, got, test.want)
+		}
+	}
+}
+
+func TestValueJoiner(t *testing.T) {
+	guard := boolVar("g")
+	x, p := intVar("x"), boolVar("p")
+	ref := &symbolic.Ref{}
+	tests := []struct {
+		name         string
+		mine, theirs symbolic.SymbolicExpression
+		ok           bool
+		want         string
+		joined       int
+	}{
+		{"ints", x, intConst(1), true, "(g ? x : 1)", 1},
+		{"bools", p, symbolic.NewBoolConstant(true), true, "(g ? p : true)", 1},
+		{"same value", x, x, true, "x", 0},
+		{"equal text", x, intVar("x"), true, "x", 0},
+		{"missing value", x, nil, false, "", 0},
+		{"different types", x, p, false, "", 0},
+		{"arrays", symbolic.NewSymbolicVariable("a", symbolic.ArrayType), symbolic.NewSymbolicVariable("b", symbolic.ArrayType), false, "", 0},
+		{"reference and int", x, ref, false, "", 0},
+		{"int and reference", ref, x, false, "", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			joiner := &valueJoiner{guard: guard}
+			joined, ok := joiner.join(test.mine, test.theirs)
+			if ok != test.ok {
+				t.Fatalf("join = %v, %t; want ok = %t", joined, ok, test.ok)
+			}
+			if test.want != "" && joined.String() != test.want {
+				t.Errorf("join = %s, want %s", joined, test.want)
+			}
+			if joiner.joined != test.joined {
+				t.Errorf("joined = %d, want %d", joiner.joined, test.joined)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// FunctionModel — написанная вручную символьная модель функции.
+// Получает символьные аргументы вызова и возвращает символьный результат
+// (nil для функций без результата). Модель может сужать путь через
+// interpreter.addConstraint и работать с памятью через interpreter.Heap.
+type FunctionModel func(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error)
+
+// ModelRegistry хранит модели по полному имени функции, как его
+// возвращает (*ssa.Function).String(): "strings.Contains", "(*bytes.Buffer).Len"
+type ModelRegistry map[string]FunctionModel
+
+// DefaultModels возвращает модели часто используемых функций стандартной
+// библиотеки, символьное исполнение тел которых приводит к взрыву путей.
+//
+// math.Abs и sort.Ints зарегистрированы, но не моделируются: для float64 нет
+// символьного типа, а сортировка требует памяти с символьными индексами и
+// длинами массивов. Их тела не исполняются, а вызов обрабатывается как
+// неподдерживаемая инструкция (см. unmodelled). При ConcretizeUnsupported
+// sort.Ints пропускается, а math.Abs всё равно завершает состояние ошибкой:
+// его результату float64 нельзя сопоставить символьную переменную.
+func DefaultModels() ModelRegistry {
+	return ModelRegistry{
+		"errors.New":       modelErrorsNew,
+		"strings.Contains": modelStringsContains,
+		"bytes.Equal":      modelBytesEqual,
+		"strconv.Itoa":     modelStrconvItoa,
+		"math.Abs":         unmodelled("float64 has no symbolic type"),
+		"sort.Ints":        unmodelled("memory has no symbolic indices"),
+
+		"(*sync.Mutex).Lock":   modelMutexLock,
+		"(*sync.Mutex).Unlock": modelMutexUnlock,
+	}
+}
+
+// WithModel регистрирует пользовательскую модель функции,
+// заменяя встроенную модель с тем же именем
+func WithModel(name string, model FunctionModel) Option {
+	return func(analyser *Analyser) {
+		analyser.RegisterModel(name, model)
+	}
+}
+
+// RegisterModel регистрирует модель функции по её полному имени
+func (analyser *Analyser) RegisterModel(name string, model FunctionModel) {
+	if analyser.Models == nil {
+		analyser.Models = DefaultModels()
+	}
+	analyser.Models[name] = model
+}
+
+// callModel применяет модель к статическому вызову, если она зарегистрирована.
+// Обработчик вызовов должен обращаться к ней до входа в тело функции.
+func (interpreter *Interpreter) callModel(call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, bool, error) {
+	callee := call.StaticCallee()
+	if callee == nil {
+		return nil, false, nil
+	}
+	model, ok := interpreter.Analyser.Models[callee.String()]
+	if !ok {
+		return nil, false, nil
+	}
+	result, err := model(interpreter, call, args)
+	if err != nil {
+		return nil, true, fmt.Errorf("model of %s: %w", callee, err)
+	}
+	return result, true, nil
+}
+
+// freshValue создаёт свежую символьную переменную для результата модели
+func (interpreter *Interpreter) freshValue(prefix string, exprType symbolic.ExpressionType) *symbolic.SymbolicVariable {
+	interpreter.Analyser.opaqueCounter++
+	name := fmt.Sprintf("$%s%s_%d", interpreter.Analyser.opaquePrefix, prefix, interpreter.Analyser.opaqueCounter)
+	return symbolic.NewSymbolicVariable(name, exprType)
+}
+
+// modelErrorsNew: errors.New всегда возвращает новый ненулевой объект ошибки.
+// Сообщение хранится как массив байтов.
+func modelErrorsNew(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+	return interpreter.Heap.Allocate(symbolic.ArrayType), nil
+}
+
+// modelUnknownBool возвращает свежую булеву переменную: исследуются оба исхода
+// без исполнения тела функции
+func modelUnknownBool(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+	return interpreter.freshValue(call.StaticCallee().Name(), symbolic.BoolType), nil
+}
+
+// modelStringsContains: подстрока не длиннее строки, пустая подстрока
+// содержится в любой строке, а строка содержит саму себя. Длины входных
+// строк задаются переменными LengthVariable.
+func modelStringsContains(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+	if len(args) == 2 && args[0] == args[1] {
+		return symbolic.NewBoolConstant(true), nil
+	}
+	result := interpreter.freshValue("contains", symbolic.BoolType)
+	str, isVariable := args[0].(*symbolic.SymbolicVariable)
+	substr, isSubstrVariable := args[1].(*symbolic.SymbolicVariable)
+	if !isVariable || !isSubstrVariable {
+		return result, nil
+	}
+
+	length, substrLength := LengthVariable(str.Name), LengthVariable(substr.Name)
+	interpreter.addConstraint(symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{result}, symbolic.NOT),
+		symbolic.NewBinaryOperation(length, substrLength, symbolic.GE),
+	}, symbolic.OR))
+	interpreter.addConstraint(symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(substrLength, symbolic.NewIntConstant(0), symbolic.NE),
+		result,
+	}, symbolic.OR))
+	return result, nil
+}
+
+// unmodelled возвращает модель функции, которую нельзя выразить символьно.
+// Вызов обрабатывается как неподдерживаемая инструкция согласно
+// UnsupportedPolicy: при FailOnUnsupported состояние завершается ошибкой
+// UnsupportedError, при ConcretizeUnsupported вызов пропускается, а его
+// результат заменяется свежей переменной (см. opaqueResult). Инструкцию
+// вызова модель берёт из последнего step.
+func unmodelled(reason string) FunctionModel {
+	return func(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+		instruction, ok := interpreter.instruction.(ssa.CallInstruction)
+		if !ok || instruction.Common() != call {
+			return nil, fmt.Errorf("no symbolic model: %s", reason)
+		}
+		// Аргументы не влияют на результат, поэтому не конкретизируются
+		if _, err := interpreter.unsupported(instruction); err != nil {
+			return nil, fmt.Errorf("no symbolic model (%s): %w", reason, err)
+		}
+		// Результаты go и defer отбрасываются
+		value := instruction.Value()
+		if value == nil || call.Signature().Results().Len() == 0 {
+			return nil, nil
+		}
+		return interpreter.opaqueResult(value)
+	}
+}
+
+// modelBytesEqual: срез всегда равен самому себе, в остальных случаях
+// результат неизвестен
+func modelBytesEqual(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+	if len(args) == 2 && args[0] == args[1] {
+		return symbolic.NewBoolConstant(true), nil
+	}
+	return modelUnknownBool(interpreter, call, args)
+}
+
+// modelStrconvItoa: результат — свежая строка (массив байтов)
+func modelStrconvItoa(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+	return interpreter.freshValue("itoa", symbolic.ArrayType), nil
+}
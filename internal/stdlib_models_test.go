@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+const modelsSource = `package main
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strings"
+)
+
+func contains(s, substr string) bool { return strings.Contains(s, substr) }
+
+func equal(a, b []byte) bool { return bytes.Equal(a, b) }
+
+func abs(x float64) float64 { return math.Abs(x) }
+
+func sortInts(values []int) { sort.Ints(values) }
+
+func custom(x int) int { return fold(x) }
+
+func fold(x int) int { return x }
+`
+
+func TestDefaultModelsRegistered(t *testing.T) {
+	models := DefaultModels()
+	for _, name := range []string{"errors.New", "strings.Contains", "bytes.Equal", "strconv.Itoa", "math.Abs", "sort.Ints"} {
+		if models[name] == nil {
+			t.Errorf("no default model for %s", name)
+		}
+	}
+}
+
+func TestCallModel(t *testing.T) {
+	analyser := newTestAnalyser(newFakeSolver(4))
+	interpreter := Interpreter{Analyser: analyser}
+
+	call := firstCall(t, buildFunction(t, modelsSource, "equal"))
+	argument := symbolic.NewSymbolicVariable("a", symbolic.ArrayType)
+	result, handled, err := interpreter.callModel(&call.Call, []symbolic.SymbolicExpression{argument, argument})
+	if err != nil || !handled {
+		t.Fatalf("callModel(bytes.Equal) = %v, %t, %v", result, handled, err)
+	}
+	if result.String() != "true" {
+		t.Errorf("bytes.Equal(a, a) = %s, want true", result)
+	}
+
+	call = firstCall(t, buildFunction(t, modelsSource, "custom"))
+	if _, handled, _ := interpreter.callModel(&call.Call, []symbolic.SymbolicExpression{intVar("x")}); handled {
+		t.Error("callModel handled a function without a model")
+	}
+
+	analyser.RegisterModel("main.fold", func(interpreter *Interpreter, call *ssa.CallCommon, args []symbolic.SymbolicExpression) (symbolic.SymbolicExpression, error) {
+		return intConst(42), nil
+	})
+	result, handled, err = interpreter.callModel(&call.Call, []symbolic.SymbolicExpression{intVar("x")})
+	if err != nil || !handled || result.String() != "42" {
+		t.Errorf("user model: callModel = %v, %t, %v", result, handled, err)
+	}
+	if analyser.Models["strings.Contains"] == nil {
+		t.Error("registering a user model dropped the default models")
+	}
+}
+
+func TestUnmodelled(t *testing.T) {
+	tests := []struct {
+		name        string
+		function    string
+		policy      UnsupportedPolicy
+		unstepped   bool
+		unsupported bool
+		wantError   bool
+		wantResult  bool
+	}{
+		{"abs fails", "abs", FailOnUnsupported, false, true, true, false},
+		{"abs result has no symbolic type", "abs", ConcretizeUnsupported, false, false, true, false},
+		{"sort fails", "sortInts", FailOnUnsupported, false, true, true, false},
+		{"sort is skipped", "sortInts", ConcretizeUnsupported, false, false, false, false},
+		{"integer result is opaque", "custom", ConcretizeUnsupported, false, false, false, true},
+		{"call not marked by step", "sortInts", ConcretizeUnsupported, true, false, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyser := newTestAnalyser(newFakeSolver(4), WithUnsupportedPolicy(test.policy), WithModel("main.fold", unmodelled("test")))
+			interpreter := Interpreter{Analyser: analyser}
+			call := firstCall(t, buildFunction(t, modelsSource, test.function))
+			if !test.unstepped {
+				interpreter.step(call)
+			}
+			result, handled, err := interpreter.callModel(&call.Call, []symbolic.SymbolicExpression{intVar("x")})
+			if !handled {
+				t.Fatal("call was not handled by a model")
+			}
+			if (err != nil) != test.wantError {
+				t.Fatalf("callModel error = %v, wantError %t", err, test.wantError)
+			}
+			var unsupported *UnsupportedError
+			if errors.As(err, &unsupported) != test.unsupported {
+				t.Errorf("error %v, want UnsupportedError = %t", err, test.unsupported)
+			}
+			if err != nil && !strings.Contains(err.Error(), "no symbolic model") && !strings.Contains(err.Error(), "unsupported result type") {
+				t.Errorf("error %q does not explain the missing model", err)
+			}
+			if (result != nil) != test.wantResult {
+				t.Errorf("result = %v, want a result = %t", result, test.wantResult)
+			}
+			if result != nil && result.Type() != symbolic.IntType {
+				t.Errorf("opaque result %s is not an integer", result)
+			}
+			if err == nil && !interpreter.Incomplete {
+				t.Error("skipped call did not mark the state incomplete")
+			}
+		})
+	}
+}
+
+func TestStringsContainsModel(t *testing.T) {
+	requireExpressions(t)
+	str := symbolic.NewSymbolicVariable("s", symbolic.ArrayType)
+	substr := symbolic.NewSymbolicVariable("sub", symbolic.ArrayType)
+	call := firstCall(t, buildFunction(t, modelsSource, "contains"))
+	tests := []struct {
+		name     string
+		assume   func(result symbolic.SymbolicExpression) symbolic.SymbolicExpression
+		feasible bool
+	}{
+		{"contained substring is not longer", func(result symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+			return logical(symbolic.AND, result, compare(LengthVariable("sub"), symbolic.GT, LengthVariable("s")))
+		}, false},
+		{"empty substring is always contained", func(result symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+			return logical(symbolic.AND, logical(symbolic.NOT, result), compare(LengthVariable("sub"), symbolic.EQ, intConst(0)))
+		}, false},
+		{"other strings may not contain it", func(result symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+			return logical(symbolic.AND, logical(symbolic.NOT, result), compare(LengthVariable("sub"), symbolic.EQ, intConst(1)))
+		}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analyser := newTestAnalyser(newFakeSolver(2))
+			interpreter := Interpreter{Analyser: analyser}
+			result, _, err := interpreter.callModel(&call.Call, []symbolic.SymbolicExpression{str, substr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			interpreter.addConstraint(test.assume(result))
+			verdict, _, err := analyser.checkSat(interpreter.PathCondition)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (verdict == solver.SAT) != test.feasible {
+				t.Errorf("path %s is %s", interpreter.PathCondition, verdict)
+			}
+		})
+	}
+
+	interpreter := Interpreter{Analyser: newTestAnalyser(newFakeSolver(2))}
+	result, _, err := interpreter.callModel(&call.Call, []symbolic.SymbolicExpression{str, str})
+	if err != nil || result.String() != "true" {
+		t.Errorf("strings.Contains(s, s) = %v, %v", result, err)
+	}
+}
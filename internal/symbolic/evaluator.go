@@ -0,0 +1,166 @@
+package symbolic
+
+import "fmt"
+
+// evaluator вычисляет выражение при конкретных значениях переменных (Visitor Pattern).
+// Результат каждого Visit-метода — IntConstant, BoolConstant или error.
+type evaluator struct {
+	assignment map[string]SymbolicExpression
+}
+
+// Evaluate вычисляет выражение с семантикой Go при заданных значениях переменных.
+// Переменные без значения считаются равными нулевому значению своего типа.
+// Возвращает IntConstant или BoolConstant.
+func Evaluate(expr SymbolicExpression, assignment map[string]SymbolicExpression) (SymbolicExpression, error) {
+	result := expr.Accept(&evaluator{assignment: assignment})
+	if err, failed := result.(error); failed {
+		return nil, err
+	}
+	return result.(SymbolicExpression), nil
+}
+
+func (ev *evaluator) VisitVariable(expr *SymbolicVariable) interface{} {
+	if value, ok := ev.assignment[expr.Name]; ok {
+		return value
+	}
+	switch expr.ExprType {
+	case IntType:
+		return NewIntConstant(0)
+	case BoolType:
+		return NewBoolConstant(false)
+	default:
+		return fmt.Errorf("cannot evaluate variable %s of type %s", expr.Name, expr.ExprType)
+	}
+}
+
+func (ev *evaluator) VisitIntConstant(expr *IntConstant) interface{} {
+	return expr
+}
+
+func (ev *evaluator) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return expr
+}
+
+func (ev *evaluator) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	left := expr.Left.Accept(ev)
+	if err, failed := left.(error); failed {
+		return err
+	}
+	right := expr.Right.Accept(ev)
+	if err, failed := right.(error); failed {
+		return err
+	}
+
+	if l, ok := left.(*BoolConstant); ok {
+		r, ok := right.(*BoolConstant)
+		if !ok {
+			return fmt.Errorf("type mismatch in %s", expr.Operator)
+		}
+		switch expr.Operator {
+		case EQ:
+			return NewBoolConstant(l.Value == r.Value)
+		case NE:
+			return NewBoolConstant(l.Value != r.Value)
+		default:
+			return fmt.Errorf("operator %s is not defined on bool", expr.Operator)
+		}
+	}
+
+	l, lok := left.(*IntConstant)
+	r, rok := right.(*IntConstant)
+	if !lok || !rok {
+		return fmt.Errorf("type mismatch in %s", expr.Operator)
+	}
+	switch expr.Operator {
+	case ADD:
+		return NewIntConstant(l.Value + r.Value)
+	case SUB:
+		return NewIntConstant(l.Value - r.Value)
+	case MUL:
+		return NewIntConstant(l.Value * r.Value)
+	case DIV:
+		if r.Value == 0 {
+			return fmt.Errorf("integer divide by zero")
+		}
+		return NewIntConstant(l.Value / r.Value)
+	case MOD:
+		if r.Value == 0 {
+			return fmt.Errorf("integer divide by zero")
+		}
+		return NewIntConstant(l.Value % r.Value)
+	case EQ:
+		return NewBoolConstant(l.Value == r.Value)
+	case NE:
+		return NewBoolConstant(l.Value != r.Value)
+	case LT:
+		return NewBoolConstant(l.Value < r.Value)
+	case LE:
+		return NewBoolConstant(l.Value <= r.Value)
+	case GT:
+		return NewBoolConstant(l.Value > r.Value)
+	case GE:
+		return NewBoolConstant(l.Value >= r.Value)
+	default:
+		return fmt.Errorf("unsupported operator %s", expr.Operator)
+	}
+}
+
+func (ev *evaluator) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	values := make([]bool, 0, len(expr.Operands))
+	for _, operand := range expr.Operands {
+		result := operand.Accept(ev)
+		if err, failed := result.(error); failed {
+			return err
+		}
+		value, ok := result.(*BoolConstant)
+		if !ok {
+			return fmt.Errorf("operand of %s is not boolean", expr.Operator)
+		}
+		values = append(values, value.Value)
+	}
+
+	switch expr.Operator {
+	case AND:
+		for _, value := range values {
+			if !value {
+				return NewBoolConstant(false)
+			}
+		}
+		return NewBoolConstant(true)
+	case OR:
+		for _, value := range values {
+			if value {
+				return NewBoolConstant(true)
+			}
+		}
+		return NewBoolConstant(false)
+	case NOT:
+		if len(values) != 1 {
+			return fmt.Errorf("NOT expects exactly one operand")
+		}
+		return NewBoolConstant(!values[0])
+	case IMPLIES:
+		if len(values) != 2 {
+			return fmt.Errorf("IMPLIES expects exactly two operands")
+		}
+		return NewBoolConstant(!values[0] || values[1])
+	default:
+		return fmt.Errorf("unsupported operator %s", expr.Operator)
+	}
+}
+
+func (ev *evaluator) VisitITE(expr *ITE) interface{} {
+	result := expr.Condition.Accept(ev)
+	if err, failed := result.(error); failed {
+		return err
+	}
+	condition, ok := result.(*BoolConstant)
+	if !ok {
+		return fmt.Errorf("ite condition is not boolean")
+	}
+	// Вычисляется только выбранная ветвь, как в условном операторе Go
+	if condition.Value {
+		return expr.Then.Accept(ev)
+	}
+	return expr.Else.Accept(ev)
+}
@@ -0,0 +1,67 @@
+package symbolic
+
+import "testing"
+
+func TestEvaluate(t *testing.T) {
+	x, y := NewSymbolicVariable("x", IntType), NewSymbolicVariable("y", IntType)
+	p := NewSymbolicVariable("p", BoolType)
+	assignment := map[string]SymbolicExpression{"x": NewIntConstant(-7), "y": NewIntConstant(2), "p": NewBoolConstant(true)}
+	binary := func(left, right SymbolicExpression, op BinaryOperator) *BinaryOperation {
+		return &BinaryOperation{Left: left, Right: right, Operator: op}
+	}
+	logical := func(op LogicalOperator, operands ...SymbolicExpression) *LogicalOperation {
+		return &LogicalOperation{Operands: operands, Operator: op}
+	}
+	divideByZero := binary(x, NewIntConstant(0), DIV)
+	tests := []struct {
+		name string
+		expr SymbolicExpression
+		want string
+	}{
+		{"assigned variable", x, "-7"},
+		{"unassigned int", NewSymbolicVariable("z", IntType), "0"},
+		{"unassigned bool", NewSymbolicVariable("q", BoolType), "false"},
+		{"truncated division", binary(x, y, DIV), "-3"},
+		{"remainder sign", binary(x, y, MOD), "-1"},
+		{"comparison", binary(x, y, LT), "true"},
+		{"bool equality", binary(p, NewBoolConstant(false), NE), "true"},
+		{"implication", logical(IMPLIES, p, binary(x, y, GT)), "false"},
+		{"empty conjunction", logical(AND), "true"},
+		{"ite then", &ITE{Condition: p, Then: x, Else: y}, "-7"},
+		{"ite else", &ITE{Condition: logical(NOT, p), Then: x, Else: y}, "2"},
+		{"ite evaluates the chosen branch only", &ITE{Condition: p, Then: y, Else: divideByZero}, "2"},
+		{"nested ite", binary(&ITE{Condition: p, Then: x, Else: y}, NewIntConstant(1), ADD), "-6"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := Evaluate(test.expr, assignment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value.String() != test.want {
+				t.Errorf("Evaluate = %s, want %s", value, test.want)
+			}
+		})
+	}
+
+	errors := []struct {
+		name string
+		expr SymbolicExpression
+	}{
+		{"division by zero", divideByZero},
+		{"remainder by zero", binary(x, NewIntConstant(0), MOD)},
+		{"type mismatch", binary(x, p, EQ)},
+		{"ordering on bool", binary(p, p, LT)},
+		{"not with two operands", logical(NOT, p, p)},
+		{"non-boolean operand", logical(AND, x)},
+		{"array variable", NewSymbolicVariable("arr", ArrayType)},
+		{"ite condition fails", &ITE{Condition: binary(divideByZero, y, EQ), Then: x, Else: y}},
+		{"ite int condition", &ITE{Condition: x, Then: x, Else: y}},
+		{"ite chosen branch fails", &ITE{Condition: p, Then: divideByZero, Else: y}},
+	}
+	for _, test := range errors {
+		if value, err := Evaluate(test.expr, assignment); err == nil {
+			t.Errorf("%s: Evaluate = %s, want an error", test.name, value)
+		}
+	}
+}
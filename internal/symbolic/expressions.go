@@ -164,6 +164,40 @@ func (lo *LogicalOperation) Accept(visitor Visitor) interface{} {
 	return visitor.VisitLogicalOperation(lo)
 }
 
+// ITE представляет выражение выбора: condition ? then : else
+type ITE struct {
+	Condition SymbolicExpression
+	Then      SymbolicExpression
+	Else      SymbolicExpression
+}
+
+// NewITE создаёт выражение выбора. Условие должно быть булевым, а ветви —
+// одного типа.
+func NewITE(condition, then, otherwise SymbolicExpression) *ITE {
+	if condition.Type() != BoolType {
+		panic(fmt.Sprintf("ite condition %s is not boolean", condition))
+	}
+	if then.Type() != otherwise.Type() {
+		panic(fmt.Sprintf("ite branches have different types %s and %s", then.Type(), otherwise.Type()))
+	}
+	return &ITE{Condition: condition, Then: then, Else: otherwise}
+}
+
+// Type возвращает тип ветвей
+func (ite *ITE) Type() ExpressionType {
+	return ite.Then.Type()
+}
+
+// String возвращает строковое представление выражения выбора
+func (ite *ITE) String() string {
+	return fmt.Sprintf("(%s ? %s : %s)", ite.Condition, ite.Then, ite.Else)
+}
+
+// Accept реализует Visitor pattern
+func (ite *ITE) Accept(visitor Visitor) interface{} {
+	return visitor.VisitITE(ite)
+}
+
 // Операторы для бинарных выражений
 type BinaryOperator int
 
@@ -260,4 +294,3 @@ func (ref *Ref) Accept(visitor Visitor) interface{} {
 // - UnaryOperation (унарные операции: -x, !x)
 // - ArrayAccess (доступ к элементам массива: arr[index])
 // - FunctionCall (вызовы функций: f(x, y))
-// - ConditionalExpression (тернарный оператор: condition ? true_expr : false_expr)
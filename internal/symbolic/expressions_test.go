@@ -0,0 +1,63 @@
+package symbolic
+
+import "testing"
+
+// requireConstructors пропускает тест, пока конструкторы и методы выражений
+// из домашнего задания (NewBinaryOperation, NewLogicalOperation, String)
+// не реализованы
+func requireConstructors(t *testing.T) {
+	t.Helper()
+	defer func() {
+		if recover() != nil {
+			t.Skip("symbolic expression constructors are not implemented yet")
+		}
+	}()
+	p := NewSymbolicVariable("p", BoolType)
+	_ = NewLogicalOperation([]SymbolicExpression{p}, NOT).String()
+	_ = NewBinaryOperation(NewIntConstant(1), NewIntConstant(2), ADD).String()
+}
+
+// panics сообщает, паникует ли build
+func panics(build func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	build()
+	return false
+}
+
+func TestNewITE(t *testing.T) {
+	c := NewSymbolicVariable("c", BoolType)
+	a, b := NewSymbolicVariable("a", IntType), NewIntConstant(2)
+	tests := []struct {
+		name                       string
+		condition, then, otherwise SymbolicExpression
+		panics                     bool
+	}{
+		{"int branches", c, a, b, false},
+		{"bool branches", NewBoolConstant(true), c, NewBoolConstant(false), false},
+		{"int condition", a, a, b, true},
+		{"different branch types", c, a, c, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ite *ITE
+			if got := panics(func() { ite = NewITE(test.condition, test.then, test.otherwise) }); got != test.panics {
+				t.Fatalf("panicked = %t, want %t", got, test.panics)
+			}
+			if test.panics {
+				return
+			}
+			if ite.Condition != test.condition || ite.Then != test.then || ite.Else != test.otherwise {
+				t.Errorf("NewITE = %+v", ite)
+			}
+			if ite.Type() != test.then.Type() {
+				t.Errorf("Type() = %s, want %s", ite.Type(), test.then.Type())
+			}
+		})
+	}
+
+	if got, want := NewITE(c, a, b).String(), "(c ? a : 2)"; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package symbolic
+
+// simplifier упрощает выражения выбора (Visitor Pattern). Узлы, в которых
+// ничего не изменилось, возвращаются без копирования.
+type simplifier struct{}
+
+// Simplify возвращает выражение, в котором выражения выбора упрощены:
+//
+//	true ? a : b        → a
+//	c ? a : a           → a
+//	c ? true : false    → c
+//	c ? false : true    → !c
+//	!c ? a : b          → c ? b : a
+//	c ? (c ? a : b) : d → c ? a : d
+//	c ? a : (c ? b : d) → c ? a : d
+func Simplify(expr SymbolicExpression) SymbolicExpression {
+	return expr.Accept(simplifier{}).(SymbolicExpression)
+}
+
+func (s simplifier) VisitVariable(expr *SymbolicVariable) interface{} {
+	return expr
+}
+
+func (s simplifier) VisitIntConstant(expr *IntConstant) interface{} {
+	return expr
+}
+
+func (s simplifier) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return expr
+}
+
+func (s simplifier) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	left := expr.Left.Accept(s).(SymbolicExpression)
+	right := expr.Right.Accept(s).(SymbolicExpression)
+	if left == expr.Left && right == expr.Right {
+		return expr
+	}
+	return NewBinaryOperation(left, right, expr.Operator)
+}
+
+func (s simplifier) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	operands := make([]SymbolicExpression, len(expr.Operands))
+	changed := false
+	for i, operand := range expr.Operands {
+		operands[i] = operand.Accept(s).(SymbolicExpression)
+		changed = changed || operands[i] != operand
+	}
+	if !changed {
+		return expr
+	}
+	return NewLogicalOperation(operands, expr.Operator)
+}
+
+func (s simplifier) VisitITE(expr *ITE) interface{} {
+	condition := expr.Condition.Accept(s).(SymbolicExpression)
+	then := expr.Then.Accept(s).(SymbolicExpression)
+	otherwise := expr.Else.Accept(s).(SymbolicExpression)
+	return simplifyITE(expr, condition, then, otherwise)
+}
+
+// simplifyITE применяет правила Simplify к выражению выбора с уже
+// упрощёнными частями
+func simplifyITE(expr *ITE, condition, then, otherwise SymbolicExpression) SymbolicExpression {
+	if constant, ok := condition.(*BoolConstant); ok {
+		if constant.Value {
+			return then
+		}
+		return otherwise
+	}
+	if sameExpression(then, otherwise) {
+		return then
+	}
+	if thenConstant, ok := then.(*BoolConstant); ok {
+		if otherwiseConstant, ok := otherwise.(*BoolConstant); ok {
+			// Ветви различны, поэтому результат — условие или его отрицание
+			if thenConstant.Value && !otherwiseConstant.Value {
+				return condition
+			}
+			return NewLogicalOperation([]SymbolicExpression{condition}, NOT)
+		}
+	}
+	if negation, ok := condition.(*LogicalOperation); ok && negation.Operator == NOT && len(negation.Operands) == 1 {
+		return simplifyITE(nil, negation.Operands[0], otherwise, then)
+	}
+	if nested, ok := then.(*ITE); ok && sameExpression(nested.Condition, condition) {
+		return simplifyITE(nil, condition, nested.Then, otherwise)
+	}
+	if nested, ok := otherwise.(*ITE); ok && sameExpression(nested.Condition, condition) {
+		return simplifyITE(nil, condition, then, nested.Else)
+	}
+
+	if expr != nil && condition == expr.Condition && then == expr.Then && otherwise == expr.Else {
+		return expr
+	}
+	return NewITE(condition, then, otherwise)
+}
+
+// sameExpression проверяет синтаксическое совпадение выражений
+func sameExpression(first, second SymbolicExpression) bool {
+	return first == second || first.Type() == second.Type() && first.String() == second.String()
+}
@@ -0,0 +1,61 @@
+package symbolic
+
+import "testing"
+
+func TestSimplify(t *testing.T) {
+	c := NewSymbolicVariable("c", BoolType)
+	a, b, d := NewSymbolicVariable("a", IntType), NewSymbolicVariable("b", IntType), NewSymbolicVariable("d", IntType)
+	not := &LogicalOperation{Operands: []SymbolicExpression{c}, Operator: NOT}
+	unchanged := &ITE{Condition: c, Then: a, Else: b}
+	tests := []struct {
+		name string
+		expr SymbolicExpression
+		want string
+	}{
+		{"true condition", &ITE{Condition: NewBoolConstant(true), Then: a, Else: b}, "a"},
+		{"false condition", &ITE{Condition: NewBoolConstant(false), Then: a, Else: b}, "b"},
+		{"equal branches", &ITE{Condition: c, Then: a, Else: NewSymbolicVariable("a", IntType)}, "a"},
+		{"boolean selector", &ITE{Condition: c, Then: NewBoolConstant(true), Else: NewBoolConstant(false)}, "c"},
+		{"negated condition", &ITE{Condition: not, Then: a, Else: b}, "(c ? b : a)"},
+		{"same condition in then", &ITE{Condition: c, Then: &ITE{Condition: c, Then: a, Else: b}, Else: d}, "(c ? a : d)"},
+		{"same condition in else", &ITE{Condition: c, Then: a, Else: &ITE{Condition: c, Then: b, Else: d}}, "(c ? a : d)"},
+		{"nested constant condition", &ITE{Condition: c, Then: &ITE{Condition: NewBoolConstant(false), Then: a, Else: b}, Else: d}, "(c ? b : d)"},
+		{"simplified branch enables a rule", &ITE{Condition: c, Then: &ITE{Condition: NewBoolConstant(true), Then: a, Else: d}, Else: a}, "a"},
+		{"nothing to simplify", unchanged, "(c ? a : b)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Simplify(test.expr).String(); got != test.want {
+				t.Errorf("Simplify(%s) = %s, want %s", test.expr, got, test.want)
+			}
+		})
+	}
+
+	if Simplify(unchanged) != unchanged {
+		t.Error("an expression without changes was copied")
+	}
+	if Simplify(a) != a {
+		t.Error("a variable was copied")
+	}
+}
+
+func TestSimplifyWithConstructors(t *testing.T) {
+	requireConstructors(t)
+	c := NewSymbolicVariable("c", BoolType)
+	a, b := NewSymbolicVariable("a", IntType), NewSymbolicVariable("b", IntType)
+
+	negation, ok := Simplify(&ITE{Condition: c, Then: NewBoolConstant(false), Else: NewBoolConstant(true)}).(*LogicalOperation)
+	if !ok || negation.Operator != NOT || len(negation.Operands) != 1 || negation.Operands[0] != c {
+		t.Errorf("c ? false : true simplified to %v, want !c", negation)
+	}
+
+	// Выражения выбора упрощаются и внутри других операций
+	sum := &BinaryOperation{Left: &ITE{Condition: NewBoolConstant(true), Then: a, Else: b}, Right: b, Operator: ADD}
+	if simplified, ok := Simplify(sum).(*BinaryOperation); !ok || simplified.Left != a || simplified.Right != b || sum.Left == a {
+		t.Errorf("Simplify(%s) = %s", sum, Simplify(sum))
+	}
+	conjunction := &LogicalOperation{Operands: []SymbolicExpression{c, &ITE{Condition: c, Then: c, Else: c}}, Operator: AND}
+	if simplified, ok := Simplify(conjunction).(*LogicalOperation); !ok || simplified.Operands[1] != c {
+		t.Errorf("Simplify(%s) = %s", conjunction, Simplify(conjunction))
+	}
+}
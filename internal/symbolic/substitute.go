@@ -0,0 +1,48 @@
+package symbolic
+
+// substitutor заменяет переменные выражениями (Visitor Pattern)
+type substitutor struct {
+	bindings map[string]SymbolicExpression
+}
+
+// Substitute возвращает выражение, в котором переменные из bindings
+// заменены соответствующими выражениями. Остальные переменные сохраняются.
+func Substitute(expr SymbolicExpression, bindings map[string]SymbolicExpression) SymbolicExpression {
+	return expr.Accept(&substitutor{bindings: bindings}).(SymbolicExpression)
+}
+
+func (s *substitutor) VisitVariable(expr *SymbolicVariable) interface{} {
+	if value, ok := s.bindings[expr.Name]; ok {
+		return value
+	}
+	return expr
+}
+
+func (s *substitutor) VisitIntConstant(expr *IntConstant) interface{} {
+	return expr
+}
+
+func (s *substitutor) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return expr
+}
+
+func (s *substitutor) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	left := expr.Left.Accept(s).(SymbolicExpression)
+	right := expr.Right.Accept(s).(SymbolicExpression)
+	return NewBinaryOperation(left, right, expr.Operator)
+}
+
+func (s *substitutor) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	operands := make([]SymbolicExpression, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = operand.Accept(s).(SymbolicExpression)
+	}
+	return NewLogicalOperation(operands, expr.Operator)
+}
+
+func (s *substitutor) VisitITE(expr *ITE) interface{} {
+	condition := expr.Condition.Accept(s).(SymbolicExpression)
+	then := expr.Then.Accept(s).(SymbolicExpression)
+	otherwise := expr.Else.Accept(s).(SymbolicExpression)
+	return NewITE(condition, then, otherwise)
+}
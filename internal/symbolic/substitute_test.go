@@ -0,0 +1,45 @@
+package symbolic
+
+import "testing"
+
+func TestSubstitute(t *testing.T) {
+	x, y := NewSymbolicVariable("x", IntType), NewSymbolicVariable("y", IntType)
+	p := NewSymbolicVariable("p", BoolType)
+	bindings := map[string]SymbolicExpression{"x": NewIntConstant(3), "p": NewBoolConstant(true)}
+	tests := []struct {
+		name string
+		expr SymbolicExpression
+		want string
+	}{
+		{"bound variable", x, "3"},
+		{"free variable", y, "y"},
+		{"constant", NewIntConstant(5), "5"},
+		{"ite parts", &ITE{Condition: p, Then: x, Else: y}, "(true ? 3 : y)"},
+		{"nested ite", &ITE{Condition: p, Then: &ITE{Condition: p, Then: y, Else: x}, Else: x}, "(true ? (true ? y : 3) : 3)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Substitute(test.expr, bindings).String(); got != test.want {
+				t.Errorf("Substitute(%s) = %s, want %s", test.expr, got, test.want)
+			}
+		})
+	}
+
+	if Substitute(y, bindings) != y {
+		t.Error("a free variable was copied")
+	}
+}
+
+func TestSubstituteOperations(t *testing.T) {
+	requireConstructors(t)
+	x, p := NewSymbolicVariable("x", IntType), NewSymbolicVariable("p", BoolType)
+	bindings := map[string]SymbolicExpression{"x": NewIntConstant(3), "p": NewBoolConstant(false)}
+	sum, ok := Substitute(&BinaryOperation{Left: x, Right: x, Operator: ADD}, bindings).(*BinaryOperation)
+	if !ok || sum.Left != bindings["x"] || sum.Right != bindings["x"] || sum.Operator != ADD {
+		t.Errorf("x + x substituted to %v", sum)
+	}
+	negation, ok := Substitute(&LogicalOperation{Operands: []SymbolicExpression{p}, Operator: NOT}, bindings).(*LogicalOperation)
+	if !ok || negation.Operands[0] != bindings["p"] || negation.Operator != NOT {
+		t.Errorf("!p substituted to %v", negation)
+	}
+}
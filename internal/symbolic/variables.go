@@ -0,0 +1,55 @@
+package symbolic
+
+// variableCollector собирает символьные переменные выражения (Visitor Pattern)
+type variableCollector struct {
+	seen      map[string]bool
+	variables []*SymbolicVariable
+}
+
+// CollectVariables возвращает все различные символьные переменные выражений
+// в порядке их первого появления
+func CollectVariables(exprs ...SymbolicExpression) []*SymbolicVariable {
+	collector := &variableCollector{seen: make(map[string]bool)}
+	for _, expr := range exprs {
+		if expr != nil {
+			expr.Accept(collector)
+		}
+	}
+	return collector.variables
+}
+
+func (vc *variableCollector) VisitVariable(expr *SymbolicVariable) interface{} {
+	if !vc.seen[expr.Name] {
+		vc.seen[expr.Name] = true
+		vc.variables = append(vc.variables, expr)
+	}
+	return nil
+}
+
+func (vc *variableCollector) VisitIntConstant(expr *IntConstant) interface{} {
+	return nil
+}
+
+func (vc *variableCollector) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return nil
+}
+
+func (vc *variableCollector) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	expr.Left.Accept(vc)
+	expr.Right.Accept(vc)
+	return nil
+}
+
+func (vc *variableCollector) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	for _, operand := range expr.Operands {
+		operand.Accept(vc)
+	}
+	return nil
+}
+
+func (vc *variableCollector) VisitITE(expr *ITE) interface{} {
+	expr.Condition.Accept(vc)
+	expr.Then.Accept(vc)
+	expr.Else.Accept(vc)
+	return nil
+}
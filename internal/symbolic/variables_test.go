@@ -0,0 +1,33 @@
+package symbolic
+
+import "testing"
+
+func TestCollectVariables(t *testing.T) {
+	x, y := NewSymbolicVariable("x", IntType), NewSymbolicVariable("y", IntType)
+	p := NewSymbolicVariable("p", BoolType)
+	tests := []struct {
+		name  string
+		exprs []SymbolicExpression
+		want  []*SymbolicVariable
+	}{
+		{"constant", []SymbolicExpression{NewIntConstant(1)}, nil},
+		{"nil expression", []SymbolicExpression{nil, x}, []*SymbolicVariable{x}},
+		{"first appearance order", []SymbolicExpression{&BinaryOperation{Left: y, Right: x, Operator: ADD}, x}, []*SymbolicVariable{y, x}},
+		{"same name once", []SymbolicExpression{x, NewSymbolicVariable("x", IntType)}, []*SymbolicVariable{x}},
+		{"logical operands", []SymbolicExpression{&LogicalOperation{Operands: []SymbolicExpression{p, p}, Operator: AND}}, []*SymbolicVariable{p}},
+		{"ite parts", []SymbolicExpression{&ITE{Condition: p, Then: y, Else: x}}, []*SymbolicVariable{p, y, x}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := CollectVariables(test.exprs...)
+			if len(got) != len(test.want) {
+				t.Fatalf("CollectVariables = %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("variable %d = %s, want %s", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,79 @@
 package internal
 
-func GenerateTestFile(sourceFile string) string {
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/pkg/z3wrapper"
+)
+
+func GenerateTestFile(sourceFile string, options ...Option) string {
 	// TODO implement me
+	// Входные данные для тестов получайте через analyser.testInputs,
+	// чтобы учитывалась опция WithModelMinimization, а аргументы вызова
+	// форматируйте через testArguments.
 	panic("implement me")
 }
+
+// testArguments форматирует входные данные теста как аргументы вызова
+// функции. Параметры, отсутствующие в модели, получают нулевое значение.
+func testArguments(function *ssa.Function, inputs solver.Model) ([]string, error) {
+	args := make([]string, len(function.Params))
+	for i, param := range function.Params {
+		value, ok := inputs[param.Name()]
+		if !ok {
+			if value, ok = zeroValue(param.Type()); !ok {
+				return nil, fmt.Errorf("parameter %s of type %s is not supported", param.Name(), param.Type())
+			}
+		}
+		literal, err := goLiteral(value, param.Type())
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", param.Name(), err)
+		}
+		args[i] = literal
+	}
+	return args, nil
+}
+
+// goLiteral форматирует значение модели как литерал Go для параметра типа
+// tpe. Целое значение приводится к разрядности типа так же, как
+// преобразование типов в Go: модель солвера не ограничена диапазоном типа.
+func goLiteral(value symbolic.SymbolicExpression, tpe types.Type) (string, error) {
+	switch constant := value.(type) {
+	case *symbolic.BoolConstant:
+		return z3wrapper.GoLiteral(constant.Value)
+	case *symbolic.IntConstant:
+		basic, ok := tpe.Underlying().(*types.Basic)
+		if !ok || basic.Info()&types.IsInteger == 0 {
+			return "", fmt.Errorf("integer value %s for type %s", value, tpe)
+		}
+		return z3wrapper.GoLiteral(wrapInteger(basic, constant.Value))
+	default:
+		return "", fmt.Errorf("value %s has no Go literal", value)
+	}
+}
+
+// wrapInteger приводит значение к разрядности целого типа basic.
+// Беззнаковые значения возвращаются как uint64, знаковые — как int64.
+func wrapInteger(basic *types.Basic, value int64) interface{} {
+	switch basic.Kind() {
+	case types.Int8:
+		return int64(int8(value))
+	case types.Int16:
+		return int64(int16(value))
+	case types.Int32:
+		return int64(int32(value))
+	case types.Uint8:
+		return uint64(uint8(value))
+	case types.Uint16:
+		return uint64(uint16(value))
+	case types.Uint32:
+		return uint64(uint32(value))
+	case types.Uint, types.Uint64, types.Uintptr:
+		return uint64(value)
+	}
+	return value
+}
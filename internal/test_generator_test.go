@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestGoLiteral(t *testing.T) {
+	tests := []struct {
+		value symbolic.SymbolicExpression
+		tpe   types.Type
+		want  string
+	}{
+		{intConst(-5), types.Typ[types.Int], "-5"},
+		{intConst(200), types.Typ[types.Int8], "-56"},
+		{intConst(-1), types.Typ[types.Uint8], "255"},
+		{intConst(-1), types.Typ[types.Uint], "18446744073709551615"},
+		{intConst(1 << 40), types.Typ[types.Int32], "0"},
+		{intConst(7), types.NewNamed(types.NewTypeName(0, nil, "Celsius", nil), types.Typ[types.Int16], nil), "7"},
+		{symbolic.NewBoolConstant(true), types.Typ[types.Bool], "true"},
+	}
+	for _, test := range tests {
+		literal, err := goLiteral(test.value, test.tpe)
+		if err != nil || literal != test.want {
+			t.Errorf("goLiteral(%s, %s) = %q, %v; want %q", test.value, test.tpe, literal, err, test.want)
+		}
+	}
+
+	if literal, err := goLiteral(intConst(1), types.Typ[types.String]); err == nil {
+		t.Errorf("goLiteral for a string parameter = %q, want an error", literal)
+	}
+}
+
+func TestTestArguments(t *testing.T) {
+	function := buildFunction(t, `package main
+
+func f(a int8, ok bool, b uint16) {}
+`, "f")
+	args, err := testArguments(function, solver.Model{"a": intConst(-129), "b": intConst(-2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(args, ", "), "127, false, 65534"; got != want {
+		t.Errorf("testArguments = %s, want %s", got, want)
+	}
+
+	unsupported := buildFunction(t, `package main
+
+func g(s string) {}
+`, "g")
+	if args, err := testArguments(unsupported, solver.Model{}); err == nil {
+		t.Errorf("testArguments = %v, want an error for a string parameter", args)
+	}
+}
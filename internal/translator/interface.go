@@ -25,6 +25,7 @@ type ExpressionTranslator interface {
 	VisitBoolConstant(expr *symbolic.BoolConstant) (interface{}, error)
 	VisitBinaryOperation(expr *symbolic.BinaryOperation) (interface{}, error)
 	VisitLogicalOperation(expr *symbolic.LogicalOperation) (interface{}, error)
+	VisitITE(expr *symbolic.ITE) (interface{}, error)
 }
 
 // TranslationError представляет ошибку трансляции
@@ -0,0 +1,361 @@
+// Package translator содержит реализацию транслятора в текст SMT-LIB2
+package translator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// SMTLibTranslator транслирует символьные выражения в текст SMT-LIB2.
+// Результат не зависит от конкретного солвера и может быть передан
+// любому SMT-LIB2 совместимому инструменту (z3, cvc5, bitwuzla).
+type SMTLibTranslator struct {
+	// BitVectorWidth задаёт ширину битовых векторов для целых чисел.
+	// Если ширина равна нулю, целые числа кодируются сортом Int.
+	BitVectorWidth int
+
+	vars      map[string]symbolic.ExpressionType // Объявленные переменные
+	arrays    bool                               // Встречались ли массивы
+	nonLinear bool                               // Встречалась ли нелинейная арифметика
+	err       error                              // Первая ошибка трансляции
+}
+
+// NewSMTLibTranslator создаёт транслятор, кодирующий целые числа сортом Int
+func NewSMTLibTranslator() *SMTLibTranslator {
+	return &SMTLibTranslator{
+		vars: make(map[string]symbolic.ExpressionType),
+	}
+}
+
+// NewSMTLibBitVectorTranslator создаёт транслятор, кодирующий целые числа
+// битовыми векторами заданной ширины (например, 64 для int в Go)
+func NewSMTLibBitVectorTranslator(width int) *SMTLibTranslator {
+	translator := NewSMTLibTranslator()
+	translator.BitVectorWidth = width
+	return translator
+}
+
+// GetContext возвращает объявления всех встреченных переменных
+func (st *SMTLibTranslator) GetContext() interface{} {
+	return st.Declarations()
+}
+
+// Reset сбрасывает состояние транслятора
+func (st *SMTLibTranslator) Reset() {
+	st.vars = make(map[string]symbolic.ExpressionType)
+	st.arrays = false
+	st.nonLinear = false
+	st.err = nil
+}
+
+// TranslateExpression транслирует символьное выражение в терм SMT-LIB2 (string)
+func (st *SMTLibTranslator) TranslateExpression(expr symbolic.SymbolicExpression) (interface{}, error) {
+	return st.translate(expr)
+}
+
+// Declarations возвращает команды declare-const для всех встреченных переменных
+func (st *SMTLibTranslator) Declarations() string {
+	var builder strings.Builder
+	for _, name := range st.Variables() {
+		declaration, _ := st.Declaration(name)
+		builder.WriteString(declaration)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// Declaration возвращает команду declare-const для одной встреченной переменной
+func (st *SMTLibTranslator) Declaration(name string) (string, bool) {
+	exprType, exists := st.vars[name]
+	if !exists {
+		return "", false
+	}
+	return fmt.Sprintf("(declare-const %s %s)", quoteSymbol(name), st.sort(exprType)), true
+}
+
+// Variables возвращает имена всех встреченных переменных в отсортированном порядке
+func (st *SMTLibTranslator) Variables() []string {
+	names := make([]string, 0, len(st.vars))
+	for name := range st.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Logic возвращает наименьшую логику SMT-LIB, покрывающую встреченные выражения
+func (st *SMTLibTranslator) Logic() string {
+	logic := "QF_"
+	if st.arrays {
+		logic += "A"
+	}
+	switch {
+	case st.BitVectorWidth > 0:
+		logic += "BV"
+	case st.nonLinear:
+		logic += "NIA"
+	default:
+		logic += "LIA"
+	}
+	return logic
+}
+
+// Script строит самодостаточный SMT-LIB2 скрипт: логика, объявления,
+// утверждения, check-sat и get-model
+func (st *SMTLibTranslator) Script(assertions ...symbolic.SymbolicExpression) (string, error) {
+	terms := make([]string, 0, len(assertions))
+	for _, assertion := range assertions {
+		term, err := st.translate(assertion)
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, term)
+	}
+
+	var builder strings.Builder
+	// Строгие солверы (cvc5) принимают опции только до set-logic
+	builder.WriteString("(set-option :produce-models true)\n")
+	fmt.Fprintf(&builder, "(set-logic %s)\n", st.Logic())
+	builder.WriteString(st.Declarations())
+	for _, term := range terms {
+		fmt.Fprintf(&builder, "(assert %s)\n", term)
+	}
+	builder.WriteString("(check-sat)\n")
+	builder.WriteString("(get-model)\n")
+	return builder.String(), nil
+}
+
+// VisitVariable транслирует символьную переменную и запоминает её объявление
+func (st *SMTLibTranslator) VisitVariable(expr *symbolic.SymbolicVariable) interface{} {
+	if declared, exists := st.vars[expr.Name]; exists && declared != expr.ExprType {
+		st.fail(fmt.Sprintf("variable %s redeclared with type %s (was %s)", expr.Name, expr.ExprType, declared), expr)
+		return ""
+	}
+	if expr.ExprType == symbolic.ArrayType {
+		st.arrays = true
+	}
+	st.vars[expr.Name] = expr.ExprType
+	return quoteSymbol(expr.Name)
+}
+
+// VisitIntConstant транслирует целочисленную константу
+func (st *SMTLibTranslator) VisitIntConstant(expr *symbolic.IntConstant) interface{} {
+	if st.BitVectorWidth > 0 {
+		return st.bitVectorLiteral(expr.Value)
+	}
+	if expr.Value < 0 {
+		return fmt.Sprintf("(- %d)", -expr.Value)
+	}
+	return fmt.Sprintf("%d", expr.Value)
+}
+
+// VisitBoolConstant транслирует булеву константу
+func (st *SMTLibTranslator) VisitBoolConstant(expr *symbolic.BoolConstant) interface{} {
+	if expr.Value {
+		return "true"
+	}
+	return "false"
+}
+
+// VisitBinaryOperation транслирует бинарную операцию
+func (st *SMTLibTranslator) VisitBinaryOperation(expr *symbolic.BinaryOperation) interface{} {
+	left := st.visit(expr.Left)
+	right := st.visit(expr.Right)
+
+	switch expr.Operator {
+	case symbolic.MUL, symbolic.DIV, symbolic.MOD:
+		if !isConstant(expr.Left) && !isConstant(expr.Right) {
+			st.nonLinear = true
+		}
+	case symbolic.NE:
+		return fmt.Sprintf("(not (= %s %s))", left, right)
+	}
+	if st.BitVectorWidth == 0 && (expr.Operator == symbolic.DIV || expr.Operator == symbolic.MOD) {
+		return truncatedDivision(expr.Operator, left, right)
+	}
+
+	operator, ok := st.operator(expr.Operator)
+	if !ok {
+		st.fail(fmt.Sprintf("unsupported binary operator %s", expr.Operator), expr)
+		return ""
+	}
+	return fmt.Sprintf("(%s %s %s)", operator, left, right)
+}
+
+// VisitLogicalOperation транслирует логическую операцию
+func (st *SMTLibTranslator) VisitLogicalOperation(expr *symbolic.LogicalOperation) interface{} {
+	operands := make([]string, 0, len(expr.Operands))
+	for _, operand := range expr.Operands {
+		operands = append(operands, st.visit(operand))
+	}
+
+	switch expr.Operator {
+	case symbolic.AND, symbolic.OR:
+		if len(operands) == 0 {
+			return st.VisitBoolConstant(symbolic.NewBoolConstant(expr.Operator == symbolic.AND))
+		}
+		if len(operands) == 1 {
+			return operands[0]
+		}
+		name := "and"
+		if expr.Operator == symbolic.OR {
+			name = "or"
+		}
+		return fmt.Sprintf("(%s %s)", name, strings.Join(operands, " "))
+	case symbolic.NOT:
+		if len(operands) != 1 {
+			st.fail("NOT expects exactly one operand", expr)
+			return ""
+		}
+		return fmt.Sprintf("(not %s)", operands[0])
+	case symbolic.IMPLIES:
+		if len(operands) != 2 {
+			st.fail("IMPLIES expects exactly two operands", expr)
+			return ""
+		}
+		return fmt.Sprintf("(=> %s %s)", operands[0], operands[1])
+	default:
+		st.fail(fmt.Sprintf("unsupported logical operator %s", expr.Operator), expr)
+		return ""
+	}
+}
+
+// VisitITE транслирует выражение выбора
+func (st *SMTLibTranslator) VisitITE(expr *symbolic.ITE) interface{} {
+	return fmt.Sprintf("(ite %s %s %s)", st.visit(expr.Condition), st.visit(expr.Then), st.visit(expr.Else))
+}
+
+// Вспомогательные методы
+
+// translate транслирует выражение и возвращает первую возникшую ошибку
+func (st *SMTLibTranslator) translate(expr symbolic.SymbolicExpression) (string, error) {
+	st.err = nil
+	term := st.visit(expr)
+	if st.err != nil {
+		return "", st.err
+	}
+	return term, nil
+}
+
+// visit транслирует подвыражение в строку
+func (st *SMTLibTranslator) visit(expr symbolic.SymbolicExpression) string {
+	if expr == nil {
+		st.fail("nil expression", expr)
+		return ""
+	}
+	term, ok := expr.Accept(st).(string)
+	if !ok {
+		st.fail(fmt.Sprintf("unsupported expression %T", expr), expr)
+		return ""
+	}
+	return term
+}
+
+// fail запоминает первую ошибку трансляции
+func (st *SMTLibTranslator) fail(message string, expr symbolic.SymbolicExpression) {
+	if st.err == nil {
+		st.err = NewTranslationError(message, expr)
+	}
+}
+
+// sort возвращает сорт SMT-LIB2 для типа выражения
+func (st *SMTLibTranslator) sort(exprType symbolic.ExpressionType) string {
+	intSort := "Int"
+	if st.BitVectorWidth > 0 {
+		intSort = fmt.Sprintf("(_ BitVec %d)", st.BitVectorWidth)
+	}
+
+	switch exprType {
+	case symbolic.BoolType:
+		return "Bool"
+	case symbolic.ArrayType:
+		return fmt.Sprintf("(Array %s %s)", intSort, intSort)
+	default:
+		return intSort
+	}
+}
+
+// operator возвращает имя функции SMT-LIB2 для бинарного оператора
+func (st *SMTLibTranslator) operator(op symbolic.BinaryOperator) (string, bool) {
+	if st.BitVectorWidth > 0 {
+		// Семантика знакового деления битовых векторов совпадает с Go
+		operators := map[symbolic.BinaryOperator]string{
+			symbolic.ADD: "bvadd",
+			symbolic.SUB: "bvsub",
+			symbolic.MUL: "bvmul",
+			symbolic.DIV: "bvsdiv",
+			symbolic.MOD: "bvsrem",
+			symbolic.EQ:  "=",
+			symbolic.LT:  "bvslt",
+			symbolic.LE:  "bvsle",
+			symbolic.GT:  "bvsgt",
+			symbolic.GE:  "bvsge",
+		}
+		name, ok := operators[op]
+		return name, ok
+	}
+
+	operators := map[symbolic.BinaryOperator]string{
+		symbolic.ADD: "+",
+		symbolic.SUB: "-",
+		symbolic.MUL: "*",
+		symbolic.EQ:  "=",
+		symbolic.LT:  "<",
+		symbolic.LE:  "<=",
+		symbolic.GT:  ">",
+		symbolic.GE:  ">=",
+	}
+	name, ok := operators[op]
+	return name, ok
+}
+
+// truncatedDivision кодирует деление и остаток Go для сорта Int. div и mod
+// в SMT-LIB2 евклидовы (остаток неотрицателен), а в Go частное округляется
+// к нулю и знак остатка совпадает со знаком делимого. Для неотрицательного
+// делимого они совпадают, для отрицательного a/b = -((-a)/b) и
+// a%b = -((-a)%b).
+func truncatedDivision(op symbolic.BinaryOperator, left, right string) string {
+	name := "div"
+	if op == symbolic.MOD {
+		name = "mod"
+	}
+	return fmt.Sprintf("(ite (>= %[2]s 0) (%[1]s %[2]s %[3]s) (- (%[1]s (- %[2]s) %[3]s)))", name, left, right)
+}
+
+// bitVectorLiteral кодирует константу в дополнительном коде заданной ширины
+func (st *SMTLibTranslator) bitVectorLiteral(value int64) string {
+	bits := uint64(value)
+	if st.BitVectorWidth < 64 {
+		bits &= (uint64(1) << uint(st.BitVectorWidth)) - 1
+	}
+	return fmt.Sprintf("(_ bv%d %d)", bits, st.BitVectorWidth)
+}
+
+// isConstant проверяет, является ли выражение числовой константой
+func isConstant(expr symbolic.SymbolicExpression) bool {
+	_, ok := expr.(*symbolic.IntConstant)
+	return ok
+}
+
+// quoteSymbol экранирует имя, если оно не является простым символом SMT-LIB2.
+// В экранированных символах запрещены | и \, поэтому они, как и сам знак
+// экранирования #, заменяются кодами #7c, #5c и #23: разные имена остаются
+// разными символами.
+func quoteSymbol(name string) string {
+	if name == "" {
+		return "||"
+	}
+	for i, r := range name {
+		simple := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' ||
+			strings.ContainsRune("~!@$%^&*+-=<>.?/", r) || i > 0 && r >= '0' && r <= '9'
+		if !simple {
+			return "|" + symbolEscaper.Replace(name) + "|"
+		}
+	}
+	return name
+}
+
+var symbolEscaper = strings.NewReplacer("#", "#23", "|", "#7c", `\`, "#5c")
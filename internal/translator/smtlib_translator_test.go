@@ -0,0 +1,219 @@
+package translator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func variable(name string, exprType symbolic.ExpressionType) *symbolic.SymbolicVariable {
+	return symbolic.NewSymbolicVariable(name, exprType)
+}
+
+func binary(left, right symbolic.SymbolicExpression, op symbolic.BinaryOperator) *symbolic.BinaryOperation {
+	return &symbolic.BinaryOperation{Left: left, Right: right, Operator: op}
+}
+
+func logical(op symbolic.LogicalOperator, operands ...symbolic.SymbolicExpression) *symbolic.LogicalOperation {
+	return &symbolic.LogicalOperation{Operands: operands, Operator: op}
+}
+
+func TestSMTLibTranslateExpression(t *testing.T) {
+	x, y := variable("x", symbolic.IntType), variable("y", symbolic.IntType)
+	p := variable("p", symbolic.BoolType)
+	tests := []struct {
+		name  string
+		width int
+		expr  symbolic.SymbolicExpression
+		want  string
+	}{
+		{"negative constant", 0, symbolic.NewIntConstant(-5), "(- 5)"},
+		{"bit-vector constant", 8, symbolic.NewIntConstant(-1), "(_ bv255 8)"},
+		{"bool constant", 0, symbolic.NewBoolConstant(false), "false"},
+		{"addition", 0, binary(x, y, symbolic.ADD), "(+ x y)"},
+		{"not equal", 0, binary(x, y, symbolic.NE), "(not (= x y))"},
+		{"bit-vector comparison", 64, binary(x, y, symbolic.LT), "(bvslt x y)"},
+		{"bit-vector division", 64, binary(x, y, symbolic.DIV), "(bvsdiv x y)"},
+		{"truncated division", 0, binary(x, y, symbolic.DIV), "(ite (>= x 0) (div x y) (- (div (- x) y)))"},
+		{"truncated remainder", 0, binary(x, y, symbolic.MOD), "(ite (>= x 0) (mod x y) (- (mod (- x) y)))"},
+		{"empty conjunction", 0, logical(symbolic.AND), "true"},
+		{"single disjunct", 0, logical(symbolic.OR, p), "p"},
+		{"conjunction", 0, logical(symbolic.AND, p, binary(x, y, symbolic.LE)), "(and p (<= x y))"},
+		{"negation", 0, logical(symbolic.NOT, p), "(not p)"},
+		{"implication", 0, logical(symbolic.IMPLIES, p, p), "(=> p p)"},
+		{"quoted name", 0, variable("a b", symbolic.IntType), "|a b|"},
+		{"ite", 0, &symbolic.ITE{Condition: p, Then: x, Else: symbolic.NewIntConstant(-1)}, "(ite p x (- 1))"},
+		{"bit-vector ite", 8, &symbolic.ITE{Condition: binary(x, y, symbolic.GE), Then: x, Else: y}, "(ite (bvsge x y) x y)"},
+		{"nested ite", 0, &symbolic.ITE{Condition: p, Then: logical(symbolic.NOT, p), Else: &symbolic.ITE{Condition: p, Then: p, Else: p}},
+			"(ite p (not p) (ite p p p))"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			term, err := NewSMTLibBitVectorTranslator(test.width).TranslateExpression(test.expr)
+			if err != nil {
+				t.Fatalf("TranslateExpression(%s): %v", test.name, err)
+			}
+			if term != test.want {
+				t.Errorf("TranslateExpression = %s, want %s", term, test.want)
+			}
+		})
+	}
+}
+
+func TestSMTLibTranslateErrors(t *testing.T) {
+	p := variable("p", symbolic.BoolType)
+	tests := []struct {
+		name string
+		expr symbolic.SymbolicExpression
+	}{
+		{"not with two operands", logical(symbolic.NOT, p, p)},
+		{"implies with one operand", logical(symbolic.IMPLIES, p)},
+		{"redeclared variable", logical(symbolic.AND, p, variable("p", symbolic.IntType))},
+		{"unknown operator", binary(p, p, symbolic.BinaryOperator(100))},
+		{"ite without a branch", &symbolic.ITE{Condition: p, Then: p}},
+		{"ite with a failing condition", &symbolic.ITE{Condition: logical(symbolic.NOT), Then: p, Else: p}},
+	}
+	for _, test := range tests {
+		if _, err := NewSMTLibTranslator().TranslateExpression(test.expr); err == nil {
+			t.Errorf("%s: expected translation error", test.name)
+		}
+	}
+}
+
+// TestSMTLibDivisionSemantics проверяет, что деление и остаток сорта Int
+// совпадают с Go при всех сочетаниях знаков
+func TestSMTLibDivisionSemantics(t *testing.T) {
+	for _, pair := range [][2]int64{{7, 2}, {-7, 2}, {7, -2}, {-7, -2}, {6, 3}, {-6, 3}, {0, -5}} {
+		a, b := pair[0], pair[1]
+		for op, want := range map[symbolic.BinaryOperator]int64{symbolic.DIV: a / b, symbolic.MOD: a % b} {
+			expr := binary(symbolic.NewIntConstant(a), symbolic.NewIntConstant(b), op)
+			term, err := NewSMTLibTranslator().TranslateExpression(expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, rest := evaluateTerm(t, tokenize(term.(string)))
+			if len(rest) != 0 {
+				t.Fatalf("trailing tokens %v in %s", rest, term)
+			}
+			if got != want {
+				t.Errorf("%d %s %d: SMT-LIB %s = %d, Go = %d", a, op, b, term, got, want)
+			}
+		}
+	}
+}
+
+func TestSMTLibScript(t *testing.T) {
+	x := variable("x", symbolic.IntType)
+	st := NewSMTLibTranslator()
+	script, err := st.Script(binary(x, binary(x, x, symbolic.MUL), symbolic.GT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "(set-option :produce-models true)\n(set-logic QF_NIA)\n(declare-const x Int)\n(assert (> x (* x x)))\n(check-sat)\n(get-model)\n"
+	if script != want {
+		t.Errorf("Script =\n%s\nwant\n%s", script, want)
+	}
+}
+
+func TestSMTLibLogic(t *testing.T) {
+	x, y := variable("x", symbolic.IntType), variable("y", symbolic.IntType)
+	tests := []struct {
+		width int
+		expr  symbolic.SymbolicExpression
+		want  string
+	}{
+		{0, binary(x, symbolic.NewIntConstant(2), symbolic.MUL), "QF_LIA"},
+		{0, binary(x, y, symbolic.MUL), "QF_NIA"},
+		{0, variable("a", symbolic.ArrayType), "QF_ALIA"},
+		{64, binary(x, y, symbolic.MUL), "QF_BV"},
+	}
+	for _, test := range tests {
+		st := NewSMTLibBitVectorTranslator(test.width)
+		if _, err := st.TranslateExpression(test.expr); err != nil {
+			t.Fatal(err)
+		}
+		if logic := st.Logic(); logic != test.want {
+			t.Errorf("Logic() for %#v = %s, want %s", test.expr, logic, test.want)
+		}
+	}
+}
+
+func TestQuoteSymbolIsInjective(t *testing.T) {
+	names := []string{"x", "a|b", "a_b", "a#7cb", "a\\b", "a#5cb", "a#b", "1x", "|x|", "x y", ""}
+	seen := make(map[string]string)
+	for _, name := range names {
+		symbol := quoteSymbol(name)
+		if previous, ok := seen[symbol]; ok {
+			t.Errorf("names %q and %q share symbol %s", previous, name, symbol)
+		}
+		seen[symbol] = name
+		if inner := strings.TrimSuffix(strings.TrimPrefix(symbol, "|"), "|"); strings.ContainsAny(inner, "|\\") {
+			t.Errorf("quoteSymbol(%q) = %s contains a forbidden character", name, symbol)
+		}
+	}
+}
+
+// tokenize разбивает терм SMT-LIB2 на скобки и атомы
+func tokenize(term string) []string {
+	return strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(term))
+}
+
+// evaluateTerm вычисляет целочисленный терм без переменных с евклидовыми
+// div и mod, как это делает SMT-LIB2 солвер
+func evaluateTerm(t *testing.T, tokens []string) (int64, []string) {
+	t.Helper()
+	if tokens[0] != "(" {
+		value, err := strconv.ParseInt(tokens[0], 10, 64)
+		if err != nil {
+			t.Fatalf("unexpected atom %s", tokens[0])
+		}
+		return value, tokens[1:]
+	}
+	operator, rest := tokens[1], tokens[2:]
+	var args []int64
+	for rest[0] != ")" {
+		var value int64
+		value, rest = evaluateTerm(t, rest)
+		args = append(args, value)
+	}
+	rest = rest[1:]
+
+	switch {
+	case operator == "-" && len(args) == 1:
+		return -args[0], rest
+	case operator == "-":
+		return args[0] - args[1], rest
+	case operator == ">=":
+		return boolValue(args[0] >= args[1]), rest
+	case operator == "ite":
+		if args[0] != 0 {
+			return args[1], rest
+		}
+		return args[2], rest
+	case operator == "div" || operator == "mod":
+		a, b := args[0], args[1]
+		remainder := a % b
+		if remainder < 0 {
+			if b > 0 {
+				remainder += b
+			} else {
+				remainder -= b
+			}
+		}
+		if operator == "mod" {
+			return remainder, rest
+		}
+		return (a - remainder) / b, rest
+	}
+	t.Fatalf("unexpected operator %s", operator)
+	return 0, nil
+}
+
+func boolValue(value bool) int64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -2,6 +2,8 @@
 package translator
 
 import (
+	"fmt"
+
 	"github.com/ebukreev/go-z3/z3"
 	"symbolic-execution-course/internal/symbolic"
 )
@@ -42,7 +44,11 @@ func (zt *Z3Translator) Close() {
 
 // TranslateExpression транслирует символьное выражение в Z3
 func (zt *Z3Translator) TranslateExpression(expr symbolic.SymbolicExpression) (interface{}, error) {
-	return expr.Accept(zt), nil
+	result := expr.Accept(zt)
+	if err, ok := result.(*TranslationError); ok {
+		return nil, err
+	}
+	return result, nil
 }
 
 // TODO: Реализуйте следующие методы в рамках домашнего задания
@@ -107,6 +113,29 @@ func (zt *Z3Translator) VisitLogicalOperation(expr *symbolic.LogicalOperation) i
 	panic("не реализовано")
 }
 
+// VisitITE транслирует выражение выбора в Z3. Если части выражения не
+// транслируются в значения нужных сортов, возвращает *TranslationError,
+// который TranslateExpression передаёт как ошибку.
+func (zt *Z3Translator) VisitITE(expr *symbolic.ITE) interface{} {
+	translated := make([]interface{}, 3)
+	for i, part := range []symbolic.SymbolicExpression{expr.Condition, expr.Then, expr.Else} {
+		translated[i] = part.Accept(zt)
+		if err, ok := translated[i].(*TranslationError); ok {
+			return err
+		}
+	}
+	condition, ok := translated[0].(z3.Bool)
+	if !ok {
+		return NewTranslationError(fmt.Sprintf("ite condition translated to %T, not a boolean", translated[0]), expr)
+	}
+	then, thenOk := translated[1].(z3.Value)
+	otherwise, otherwiseOk := translated[2].(z3.Value)
+	if !thenOk || !otherwiseOk || expr.Then.Type() != expr.Else.Type() {
+		return NewTranslationError(fmt.Sprintf("ite branches translated to %T and %T", translated[1], translated[2]), expr)
+	}
+	return condition.IfThenElse(then, otherwise)
+}
+
 // Вспомогательные методы
 
 // createZ3Variable создаёт Z3 переменную соответствующего типа
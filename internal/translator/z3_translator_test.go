@@ -0,0 +1,62 @@
+package translator
+
+import (
+	"errors"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// requireZ3Translator пропускает тест, пока трансляция переменных и
+// констант в Z3 из домашнего задания не реализована
+func requireZ3Translator(t *testing.T) {
+	t.Helper()
+	defer func() {
+		if recover() != nil {
+			t.Skip("Z3 translation of variables and constants is not implemented yet")
+		}
+	}()
+	translator := NewZ3Translator()
+	for _, expr := range []symbolic.SymbolicExpression{
+		variable("x", symbolic.IntType), variable("p", symbolic.BoolType),
+		symbolic.NewIntConstant(1), symbolic.NewBoolConstant(true),
+	} {
+		_, _ = translator.TranslateExpression(expr)
+	}
+}
+
+func TestZ3VisitITE(t *testing.T) {
+	requireZ3Translator(t)
+	x, y := variable("x", symbolic.IntType), variable("y", symbolic.IntType)
+	p := variable("p", symbolic.BoolType)
+	tests := []struct {
+		name string
+		expr *symbolic.ITE
+		ok   bool
+	}{
+		{"int branches", &symbolic.ITE{Condition: p, Then: x, Else: symbolic.NewIntConstant(1)}, true},
+		{"bool branches", &symbolic.ITE{Condition: symbolic.NewBoolConstant(false), Then: p, Else: p}, true},
+		{"nested", &symbolic.ITE{Condition: p, Then: &symbolic.ITE{Condition: p, Then: x, Else: y}, Else: y}, true},
+		{"int condition", &symbolic.ITE{Condition: x, Then: x, Else: y}, false},
+		{"different branch sorts", &symbolic.ITE{Condition: p, Then: x, Else: p}, false},
+		{"failing nested ite", &symbolic.ITE{Condition: p, Then: &symbolic.ITE{Condition: y, Then: x, Else: y}, Else: y}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			translated, err := NewZ3Translator().TranslateExpression(test.expr)
+			if test.ok {
+				if err != nil || translated == nil {
+					t.Errorf("TranslateExpression = %v, %v", translated, err)
+				}
+				return
+			}
+			var translationError *TranslationError
+			if !errors.As(err, &translationError) || translated != nil {
+				t.Fatalf("TranslateExpression = %v, %v; want a TranslationError", translated, err)
+			}
+			if translationError.Expression == nil {
+				t.Error("TranslationError does not point to the expression")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"slices"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// UnknownPolicy определяет обработку состояния, для которого солвер
+// вернул UNKNOWN (тайм-аут, лимит ресурсов, неполнота теории)
+type UnknownPolicy int
+
+const (
+	// DropUnknown отбрасывает состояние, как если бы путь был невыполним
+	DropUnknown UnknownPolicy = iota
+	// KeepUnknown продолжает исполнение, помечая состояние как Incomplete
+	KeepUnknown
+	// ConcretizeUnknown фиксирует входные переменные значениями из модели
+	// родительского состояния и повторяет проверку для упрощённого условия
+	ConcretizeUnknown
+)
+
+// String возвращает строковое представление политики
+func (policy UnknownPolicy) String() string {
+	switch policy {
+	case DropUnknown:
+		return "drop"
+	case KeepUnknown:
+		return "keep"
+	case ConcretizeUnknown:
+		return "concretize"
+	default:
+		return "unknown"
+	}
+}
+
+// resolveState проверяет выполнимость пути нового состояния.
+// Возвращает nil, если состояние нужно отбросить.
+func (analyser *Analyser) resolveState(interpreter Interpreter) (*Interpreter, error) {
+	result, model, err := analyser.checkSat(interpreter.PathCondition)
+	if err != nil {
+		return nil, err
+	}
+	analyser.recordVerdict(interpreter, result)
+	analyser.recordNodeVerdict(interpreter, result)
+
+	switch result {
+	case solver.SAT:
+		interpreter.Model = model
+		return &interpreter, nil
+	case solver.UNSAT:
+		return nil, nil
+	}
+
+	switch analyser.UnknownPolicy {
+	case KeepUnknown:
+		interpreter.Incomplete = true
+		return &interpreter, nil
+	case ConcretizeUnknown:
+		return analyser.concretizeState(interpreter)
+	default:
+		return nil, nil
+	}
+}
+
+// concretizeState добавляет к условию пути равенства переменных их значениям
+// из модели родительского состояния и записывает их в Concretizations.
+// Полученное состояние покрывает лишь часть входов исходного пути и поэтому
+// помечается как Incomplete.
+func (analyser *Analyser) concretizeState(interpreter Interpreter) (*Interpreter, error) {
+	constraints := []symbolic.SymbolicExpression{interpreter.PathCondition}
+	var concretizations []Concretization
+	for _, variable := range symbolic.CollectVariables(interpreter.PathCondition) {
+		if value, ok := interpreter.Model[variable.Name]; ok {
+			constraints = append(constraints, symbolic.NewBinaryOperation(variable, value, symbolic.EQ))
+			concretizations = append(concretizations, Concretization{Expression: variable, Value: value})
+		}
+	}
+	if len(constraints) == 1 {
+		return nil, nil
+	}
+
+	condition := symbolic.NewLogicalOperation(constraints, symbolic.AND)
+	result, model, err := analyser.checkSat(condition)
+	if err != nil || result != solver.SAT {
+		return nil, err
+	}
+
+	interpreter.PathCondition = condition
+	interpreter.Model = model
+	interpreter.Concretizations = append(slices.Clip(interpreter.Concretizations), concretizations...)
+	interpreter.Incomplete = true
+	return &interpreter, nil
+}
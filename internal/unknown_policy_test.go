@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/solver"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestResolveState(t *testing.T) {
+	positive := compare(intVar("x"), symbolic.GT, intConst(0))
+	tests := []struct {
+		name       string
+		condition  symbolic.SymbolicExpression
+		unknown    bool
+		policy     UnknownPolicy
+		kept       bool
+		incomplete bool
+	}{
+		{"sat", positive, false, DropUnknown, true, false},
+		{"unsat", logical(symbolic.AND, positive, compare(intVar("x"), symbolic.LT, intConst(0))), false, KeepUnknown, false, false},
+		{"unknown dropped", positive, true, DropUnknown, false, false},
+		{"unknown kept", positive, true, KeepUnknown, true, true},
+		{"unknown without a model to concretize", positive, true, ConcretizeUnknown, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := newFakeSolver(2)
+			backend.unknown = test.unknown
+			analyser := newTestAnalyser(backend, WithUnknownPolicy(test.policy))
+			state, err := analyser.resolveState(Interpreter{PathCondition: test.condition})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (state != nil) != test.kept {
+				t.Fatalf("state = %v, want kept = %t", state, test.kept)
+			}
+			if state == nil {
+				return
+			}
+			if state.Incomplete != test.incomplete {
+				t.Errorf("Incomplete = %t, want %t", state.Incomplete, test.incomplete)
+			}
+			if state.PathCondition != test.condition || len(state.Concretizations) != 0 {
+				t.Errorf("path condition %s changed to %s", test.condition, state.PathCondition)
+			}
+			if !test.unknown && state.Model["x"].(*symbolic.IntConstant).Value <= 0 {
+				t.Errorf("model %v does not satisfy %s", state.Model, test.condition)
+			}
+		})
+	}
+}
+
+func TestResolveStateConcretize(t *testing.T) {
+	requireExpressions(t)
+	x, y := intVar("x"), intVar("y")
+	condition := compare(compare(x, symbolic.MUL, y), symbolic.GT, intConst(0))
+	parent := solver.Model{"x": intConst(1), "y": intConst(2)}
+
+	t.Run("concretized", func(t *testing.T) {
+		backend := newFakeSolver(2)
+		backend.unknownChecks = 1
+		analyser := newTestAnalyser(backend, WithUnknownPolicy(ConcretizeUnknown))
+		state, err := analyser.resolveState(Interpreter{PathCondition: condition, Model: parent})
+		if err != nil || state == nil {
+			t.Fatalf("resolveState = %v, %v", state, err)
+		}
+		if !state.Incomplete {
+			t.Error("a concretized state must be incomplete")
+		}
+		if len(state.Concretizations) != 2 {
+			t.Fatalf("concretizations = %v, want x and y", state.Concretizations)
+		}
+		for i, variable := range []*symbolic.SymbolicVariable{x, y} {
+			concretization := state.Concretizations[i]
+			if concretization.Expression != variable || concretization.Value != parent[variable.Name] || concretization.Instruction != nil {
+				t.Errorf("concretization %d = %+v, want %s = %s", i, concretization, variable, parent[variable.Name])
+			}
+		}
+		if state.Model["x"].String() != "1" || state.Model["y"].String() != "2" {
+			t.Errorf("model = %v, want the parent values", state.Model)
+		}
+		if conjunction, ok := state.PathCondition.(*symbolic.LogicalOperation); !ok || len(conjunction.Operands) != 3 || conjunction.Operands[0] != condition {
+			t.Errorf("path condition = %s", state.PathCondition)
+		}
+	})
+
+	t.Run("still unknown", func(t *testing.T) {
+		backend := newFakeSolver(2)
+		backend.unknown = true
+		analyser := newTestAnalyser(backend, WithUnknownPolicy(ConcretizeUnknown))
+		if state, err := analyser.resolveState(Interpreter{PathCondition: condition, Model: parent}); state != nil || err != nil {
+			t.Errorf("resolveState = %v, %v; want the state dropped", state, err)
+		}
+	})
+
+	t.Run("parent values do not satisfy the path", func(t *testing.T) {
+		backend := newFakeSolver(2)
+		backend.unknownChecks = 1
+		analyser := newTestAnalyser(backend, WithUnknownPolicy(ConcretizeUnknown))
+		stale := solver.Model{"x": intConst(-1), "y": intConst(2)}
+		if state, err := analyser.resolveState(Interpreter{PathCondition: condition, Model: stale}); state != nil || err != nil {
+			t.Errorf("resolveState = %v, %v; want the state dropped", state, err)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+// Package spec позволяет записывать свойства анализируемого кода, не изменяя его.
+// Символьный интерпретатор распознаёт Assume и Assert как встроенные функции:
+// Assume сужает условие пути, Assert проверяет условие на всех путях и
+// сообщает о контрпримере. При обычном исполнении функции проверяют
+// условие во время выполнения.
+package spec
+
+// AssertionError — паника при нарушении Assert во время обычного исполнения
+type AssertionError struct {
+	Message string
+}
+
+func (ae *AssertionError) Error() string {
+	if ae.Message == "" {
+		return "spec: assertion failed"
+	}
+	return "spec: assertion failed: " + ae.Message
+}
+
+// Assume ограничивает анализ входами, для которых cond истинно.
+// При обычном исполнении не делает ничего.
+func Assume(cond bool) {}
+
+// Assert требует, чтобы cond было истинно на каждом пути исполнения
+func Assert(cond bool) {
+	if !cond {
+		panic(&AssertionError{})
+	}
+}
+
+// AssertMessage работает как Assert и добавляет message к сообщению о нарушении
+func AssertMessage(cond bool, message string) {
+	if !cond {
+		panic(&AssertionError{Message: message})
+	}
+}
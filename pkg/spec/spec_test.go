@@ -0,0 +1,48 @@
+package spec
+
+import (
+	"errors"
+	"testing"
+)
+
+// isIdentityMatrix — упрощённая версия примера из final_tests
+func isIdentityMatrix(matrix [][]int) bool {
+	for i := range matrix {
+		if len(matrix[i]) != len(matrix) {
+			return false
+		}
+		for j := range matrix[i] {
+			expected := 0
+			if i == j {
+				expected = 1
+			}
+			if matrix[i][j] != expected {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestAssertHolds(t *testing.T) {
+	matrix := [][]int{{1, 0}, {0, 1}}
+	Assume(len(matrix) == 2)
+	Assert(isIdentityMatrix(matrix))
+	AssertMessage(matrix[0][0] == 1, "diagonal")
+}
+
+func TestAssertViolation(t *testing.T) {
+	defer func() {
+		var assertion *AssertionError
+		err, _ := recover().(error)
+		if !errors.As(err, &assertion) {
+			t.Fatalf("Expected AssertionError panic, got %v", err)
+		}
+		if assertion.Error() != "spec: assertion failed: not identity" {
+			t.Errorf("Unexpected message: %s", assertion.Error())
+		}
+	}()
+
+	AssertMessage(isIdentityMatrix([][]int{{1, 1}, {0, 1}}), "not identity")
+	t.Fatal("Expected panic")
+}
@@ -0,0 +1,159 @@
+// Package symanalysis подключает символьный анализатор к go/analysis.
+// Analyzer можно добавить в multichecker или запустить через
+// go vet -vettool (см. cmd/symvet).
+package symanalysis
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal"
+)
+
+// Analyzer исследует пути каждой функции пакета с ограниченным бюджетом
+// и сообщает о найденных паниках, нарушениях spec.Assert и контрактов, а
+// также о ветвях, невыполнимость которых доказана на всех путях
+var Analyzer = &analysis.Analyzer{
+	Name:     "symbolic",
+	Doc:      "report panics, assertion and contract violations and provably dead branches found by bounded symbolic execution",
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+	Run:      run,
+}
+
+var (
+	stepBudget   int
+	queryTimeout time.Duration
+)
+
+func init() {
+	Analyzer.Flags.IntVar(&stepBudget, "budget", 10000, "maximum number of executed instructions per function")
+	Analyzer.Flags.DurationVar(&queryTimeout, "query-timeout", time.Second, "time limit for a single solver query")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	input := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	var errs []error
+	for _, function := range input.SrcFuncs {
+		if !analysable(function) {
+			continue
+		}
+		// Ошибка в одной функции не прерывает анализ остальных
+		if err := check(pass, function); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", function.Name(), err))
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// analysable отбирает функции, с которых можно начать анализ: замыкания
+// исследуются вместе с объемлющими функциями, обобщённые — не поддерживаются.
+// Объявленные в исходном коде init SSA называет init#1, init#2, ...
+func analysable(function *ssa.Function) bool {
+	return function.Parent() == nil && function.Blocks != nil &&
+		function.TypeParams().Len() == 0 &&
+		function.Name() != "init" && !strings.HasPrefix(function.Name(), "init#")
+}
+
+// check исследует функцию и сообщает о найденных на её путях ошибках
+func check(pass *analysis.Pass, function *ssa.Function) error {
+	analyser := internal.NewAnalyser(
+		internal.WithStepBudget(stepBudget),
+		internal.WithQueryTimeout(queryTimeout),
+		internal.WithModelMinimization(),
+	)
+	analyser.Package = function.Pkg
+
+	finals, err := analyse(analyser, function)
+	if err != nil {
+		return err
+	}
+	reported := make(map[string]bool)
+	for _, final := range finals {
+		if len(final.Findings) == 0 {
+			continue
+		}
+		summary, err := analyser.Summary(final)
+		if err != nil {
+			return err
+		}
+		for _, finding := range summary.Findings {
+			pos := position(pass, finding.Location)
+			if !pos.IsValid() {
+				pos = function.Pos()
+			}
+			key := fmt.Sprint(pos, finding.Kind, finding.Message)
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+
+			diagnostic := analysis.Diagnostic{
+				Pos:      pos,
+				Category: finding.Kind.String(),
+				Message: fmt.Sprintf("%s: %s (inputs: %s)",
+					finding.Kind, finding.Message, internal.FormatInputs(summary.Inputs)),
+			}
+			if finding.Message == internal.DivideByZeroMessage {
+				diagnostic.SuggestedFixes = divisorGuard(pass, pos)
+			}
+			pass.Report(diagnostic)
+		}
+	}
+
+	for _, branch := range analyser.DeadBranches(function) {
+		if !branch.Proven {
+			continue
+		}
+		location := branch.Location
+		pass.Report(analysis.Diagnostic{
+			Pos:      position(pass, &location),
+			Category: "dead-branch",
+			Message: fmt.Sprintf("%t branch of if %s is provably dead under analysed bounds: %s is unsatisfiable",
+				branch.Direction, branch.Condition, strings.Join(branch.Proof, " ∨ ")),
+		})
+	}
+	return nil
+}
+
+// analyse исследует функцию, превращая панику анализатора (например, на
+// неподдерживаемой инструкции) в ошибку этой функции
+func analyse(analyser *internal.Analyser, function *ssa.Function) (finals []internal.Interpreter, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("analysis panicked: %v", r)
+		}
+	}()
+	return analyser.AnalyseFunction(function), nil
+}
+
+// position переводит позицию ошибки в token.Pos файла пакета
+func position(pass *analysis.Pass, location *internal.Location) token.Pos {
+	if location == nil {
+		return token.NoPos
+	}
+	for _, file := range pass.Files {
+		tokenFile := pass.Fset.File(file.Pos())
+		if tokenFile == nil || tokenFile.Name() != location.File || location.Line > tokenFile.LineCount() {
+			continue
+		}
+		return tokenFile.LineStart(location.Line) + token.Pos(max(location.Column-1, 0))
+	}
+	return token.NoPos
+}
+
+// enclosingFile возвращает файл пакета, содержащий pos
+func enclosingFile(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.Pos() <= pos && pos <= file.End() {
+			return file
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package symanalysis
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal"
+)
+
+const analyzedSource = `package q
+
+func Div(a, b int) int { return a / b }
+
+func Generic[T any](x T) T { return x }
+
+func Closure() func() int {
+	return func() int { return 1 }
+}
+
+func init() {}
+`
+
+// newSSAPass строит проход go/analysis вместе с результатом buildssa
+func newSSAPass(t *testing.T, diagnostics *[]analysis.Diagnostic) *analysis.Pass {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "q.go", analyzedSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("q", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	program := ssa.NewProgram(fset, 0)
+	ssaPkg := program.CreatePackage(pkg, []*ast.File{file}, info, false)
+	ssaPkg.Build()
+
+	var functions []*ssa.Function
+	for _, member := range ssaPkg.Members {
+		if function, ok := member.(*ssa.Function); ok {
+			functions = append(functions, function)
+			functions = append(functions, function.AnonFuncs...)
+		}
+	}
+	return &analysis.Pass{
+		Fset:      fset,
+		Files:     []*ast.File{file},
+		Pkg:       pkg,
+		TypesInfo: info,
+		ResultOf:  map[*analysis.Analyzer]interface{}{buildssa.Analyzer: &buildssa.SSA{Pkg: ssaPkg, SrcFuncs: functions}},
+		Report:    func(diagnostic analysis.Diagnostic) { *diagnostics = append(*diagnostics, diagnostic) },
+	}
+}
+
+func TestAnalysable(t *testing.T) {
+	var diagnostics []analysis.Diagnostic
+	pass := newSSAPass(t, &diagnostics)
+	var selected []string
+	for _, function := range pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA).SrcFuncs {
+		if analysable(function) {
+			selected = append(selected, function.Name())
+		}
+	}
+	if strings.Join(selected, ",") != "Closure,Div" && strings.Join(selected, ",") != "Div,Closure" {
+		t.Errorf("analysable functions = %v, want Div and Closure", selected)
+	}
+}
+
+func TestRunRecoversFromAnalyserPanics(t *testing.T) {
+	var diagnostics []analysis.Diagnostic
+	pass := newSSAPass(t, &diagnostics)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("run panicked: %v", r)
+		}
+	}()
+	_, err := run(pass)
+	if err == nil {
+		// Анализатор реализован: паник нет, и деление на ноль найдено
+		if len(diagnostics) == 0 {
+			t.Error("no diagnostics for Div")
+		}
+		return
+	}
+	// Ошибка сообщается для каждой функции, а не только для первой
+	for _, name := range []string{"Div: analysis panicked", "Closure: analysis panicked"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not mention %q", err, name)
+		}
+	}
+}
+
+func TestPosition(t *testing.T) {
+	var diagnostics []analysis.Diagnostic
+	pass := newSSAPass(t, &diagnostics)
+	tests := []struct {
+		location *internal.Location
+		want     string
+	}{
+		{&internal.Location{File: "q.go", Line: 3, Column: 35}, "/ b"},
+		{&internal.Location{File: "q.go", Line: 3}, "func Div"},
+		{&internal.Location{File: "other.go", Line: 3}, ""},
+		{&internal.Location{File: "q.go", Line: 100}, ""},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		pos := position(pass, test.location)
+		if test.want == "" {
+			if pos.IsValid() {
+				t.Errorf("position(%v) = %s, want none", test.location, pass.Fset.Position(pos))
+			}
+			continue
+		}
+		if offset := pass.Fset.Position(pos).Offset; !strings.HasPrefix(analyzedSource[offset:], test.want) {
+			t.Errorf("position(%v) points at %q, want %q", test.location, analyzedSource[offset:offset+len(test.want)], test.want)
+		}
+	}
+}
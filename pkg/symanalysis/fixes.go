@@ -0,0 +1,209 @@
+package symanalysis
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// divisorGuard предлагает проверку делителя на ноль перед оператором,
+// в котором в позиции pos выполняется целочисленное деление. Исправление
+// предлагается, только если оператор непосредственно вложен в блок.
+func divisorGuard(pass *analysis.Pass, pos token.Pos) []analysis.SuggestedFix {
+	file := enclosingFile(pass, pos)
+	if file == nil {
+		return nil
+	}
+
+	var divisor ast.Expr
+	var path []ast.Node
+	var stack []ast.Node
+	ast.Inspect(file, func(node ast.Node) bool {
+		if divisor != nil {
+			return false
+		}
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, node)
+		if y, opPos := divisorOf(node); y != nil && opPos == pos && variableInteger(pass, y) {
+			divisor = ast.Unparen(y)
+			path = append([]ast.Node{}, stack...)
+		}
+		return true
+	})
+	if divisor == nil {
+		return nil
+	}
+
+	statement, results := guardedStatement(path)
+	if statement == nil {
+		return nil
+	}
+	var text bytes.Buffer
+	if err := printer.Fprint(&text, pass.Fset, divisor); err != nil {
+		return nil
+	}
+	imports := &fileImports{pass: pass, file: file}
+	indent := strings.Repeat("\t", max(pass.Fset.Position(statement.Pos()).Column-1, 0))
+	guard := fmt.Sprintf("if %s == 0 {\n%s\treturn%s\n%s}\n%s",
+		text.String(), indent, zeroResults(pass, results, imports.qualifier), indent, indent)
+
+	return []analysis.SuggestedFix{{
+		Message: fmt.Sprintf("guard against zero divisor %s", text.String()),
+		TextEdits: append([]analysis.TextEdit{{
+			Pos:     statement.Pos(),
+			End:     statement.Pos(),
+			NewText: []byte(guard),
+		}}, imports.edits()...),
+	}}
+}
+
+// divisorOf возвращает делитель и позицию, которую SSA даёт делению:
+// оператор в выражении и левую часть в присваивании /= или %=
+func divisorOf(node ast.Node) (ast.Expr, token.Pos) {
+	switch node := node.(type) {
+	case *ast.BinaryExpr:
+		if node.Op == token.QUO || node.Op == token.REM {
+			return node.Y, node.OpPos
+		}
+	case *ast.AssignStmt:
+		if (node.Tok == token.QUO_ASSIGN || node.Tok == token.REM_ASSIGN) && len(node.Rhs) == 1 {
+			return node.Rhs[0], node.Pos()
+		}
+	}
+	return nil, token.NoPos
+}
+
+// variableInteger проверяет, что выражение — целое и не константа
+func variableInteger(pass *analysis.Pass, expr ast.Expr) bool {
+	value, ok := pass.TypesInfo.Types[expr]
+	if !ok || value.Value != nil {
+		return false
+	}
+	basic, ok := value.Type.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0
+}
+
+// guardedStatement находит на пути от корня до деления ближайший оператор и
+// результаты объемлющей функции. Оператор возвращается, только если он
+// находится прямо в блоке: перед ним можно вставить проверку.
+func guardedStatement(path []ast.Node) (ast.Stmt, *ast.FieldList) {
+	var statement ast.Stmt
+	for i := len(path) - 1; i > 0; i-- {
+		switch node := path[i].(type) {
+		case *ast.FuncLit:
+			return statement, node.Type.Results
+		case *ast.FuncDecl:
+			return statement, node.Type.Results
+		case ast.Stmt:
+			if statement != nil {
+				continue
+			}
+			switch path[i-1].(type) {
+			case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+				statement = node
+			default:
+				// Деление в заголовке else if или в другом неблочном
+				// контексте: проверка перед внешним оператором изменила бы
+				// поведение на других ветвях
+				return nil, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// zeroResults возвращает нулевые значения результатов функции для return
+func zeroResults(pass *analysis.Pass, results *ast.FieldList, qualifier types.Qualifier) string {
+	if results == nil || len(results.List) == 0 || len(results.List[0].Names) > 0 {
+		// Без результатов или с именованными результатами достаточно return
+		return ""
+	}
+	var values []string
+	for _, field := range results.List {
+		value := zeroLiteral(pass.TypesInfo.TypeOf(field.Type), qualifier)
+		for range max(len(field.Names), 1) {
+			values = append(values, value)
+		}
+	}
+	return " " + strings.Join(values, ", ")
+}
+
+// zeroLiteral возвращает литерал нулевого значения типа
+func zeroLiteral(t types.Type, qualifier types.Qualifier) string {
+	if t == nil {
+		return "nil"
+	}
+	switch underlying := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case underlying.Info()&types.IsNumeric != 0:
+			return "0"
+		case underlying.Info()&types.IsString != 0:
+			return `""`
+		case underlying.Info()&types.IsBoolean != 0:
+			return "false"
+		}
+	case *types.Struct, *types.Array:
+		return types.TypeString(t, qualifier) + "{}"
+	}
+	return "nil"
+}
+
+// fileImports пишет пакеты так, как они импортированы в файле, и собирает
+// пакеты, которые нужно импортировать для исправления
+type fileImports struct {
+	pass    *analysis.Pass
+	file    *ast.File
+	missing []*types.Package
+}
+
+// qualifier возвращает имя пакета в файле: имя импорта или имя пакета
+func (imports *fileImports) qualifier(pkg *types.Package) string {
+	if pkg == imports.pass.Pkg {
+		return ""
+	}
+	for _, spec := range imports.file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || path != pkg.Path() {
+			continue
+		}
+		if spec.Name == nil {
+			return pkg.Name()
+		}
+		switch spec.Name.Name {
+		case "_":
+			continue
+		case ".":
+			return ""
+		}
+		return spec.Name.Name
+	}
+	if !slices.ContainsFunc(imports.missing, func(missing *types.Package) bool { return missing.Path() == pkg.Path() }) {
+		imports.missing = append(imports.missing, pkg)
+	}
+	return pkg.Name()
+}
+
+// edits добавляет импорты пакетов, которых нет в файле, после объявления пакета
+func (imports *fileImports) edits() []analysis.TextEdit {
+	var edits []analysis.TextEdit
+	for _, pkg := range imports.missing {
+		edits = append(edits, analysis.TextEdit{
+			Pos:     imports.file.Name.End(),
+			End:     imports.file.Name.End(),
+			NewText: []byte(fmt.Sprintf("\n\nimport %q", pkg.Path())),
+		})
+	}
+	return edits
+}
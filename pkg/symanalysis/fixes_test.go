@@ -0,0 +1,154 @@
+package symanalysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const source = `package p
+
+import (
+	str "strings"
+	"time"
+)
+
+type Pair struct{ a, b int }
+
+func DivBy(a, b int) int {
+	return a / b
+}
+
+func Split(a, b int) (Pair, error) {
+	if a%b == 0 {
+		return Pair{a, b}, nil
+	}
+	return Pair{}, nil
+}
+
+func Shrink(a, b int) {
+	a /= b
+}
+
+func Else(a, b int) bool {
+	if a > 0 {
+		return true
+	} else if a/b > 1 {
+		return false
+	}
+	return false
+}
+
+func Constant(a int) int {
+	return a / 2
+}
+
+func Twice(a, b int) int {
+	return a/2 + b/a
+}
+
+func Imported(a, b int) (str.Builder, time.Time, error) {
+	_ = a % b
+	return str.Builder{}, time.Time{}, nil
+}
+
+func Local(a, b int) [2]Pair {
+	return [2]Pair{{a / b, 0}}
+}
+
+func Nested(a, b, c int) int {
+	return a / (b - c)
+}
+`
+
+// newPass разбирает и проверяет типы исходного текста так, как это
+// делает драйвер go/analysis
+func newPass(t *testing.T) *analysis.Pass {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("p", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &analysis.Pass{Fset: fset, Files: []*ast.File{file}, Pkg: pkg, TypesInfo: info}
+}
+
+// at возвращает позицию первого вхождения substring в исходный текст
+func at(pass *analysis.Pass, substring string) token.Pos {
+	return pass.Fset.File(pass.Files[0].Pos()).Pos(strings.Index(source, substring))
+}
+
+func TestDivisorGuard(t *testing.T) {
+	pass := newPass(t)
+	tests := []struct {
+		// at и offset задают позицию, которую SSA даёт делению
+		at     string
+		offset int
+		guard  string
+	}{
+		{"/ b\n}", 0, "if b == 0 {\n\t\treturn 0\n\t}\n\t"},
+		{"%b == 0", 0, "if b == 0 {\n\t\treturn Pair{}, nil\n\t}\n\t"},
+		{"a /= b", 0, "if b == 0 {\n\t\treturn\n\t}\n\t"},
+		{"/b > 1", 0, ""},
+		{"/ 2", 0, ""},
+		{"/2 + b/a", 0, ""},
+		{"/2 + b/a", 6, "if a == 0 {\n\t\treturn 0\n\t}\n\t"},
+		{"% b\n", 0, "if b == 0 {\n\t\treturn str.Builder{}, time.Time{}, nil\n\t}\n\t"},
+		{"{a / b, 0}", 3, "if b == 0 {\n\t\treturn [2]Pair{}\n\t}\n\t"},
+		{"/ (b - c)", 0, "if b - c == 0 {\n\t\treturn 0\n\t}\n\t"},
+		// Позиция на той же строке, но не у оператора деления
+		{"return a / b", 0, ""},
+		{"- c)", 0, ""},
+	}
+	for _, test := range tests {
+		name := fmt.Sprintf("%s+%d", test.at, test.offset)
+		fixes := divisorGuard(pass, at(pass, test.at)+token.Pos(test.offset))
+		if test.guard == "" {
+			if len(fixes) != 0 {
+				t.Errorf("%s: unexpected fix %q", name, fixes[0].TextEdits[0].NewText)
+			}
+			continue
+		}
+		if len(fixes) != 1 || len(fixes[0].TextEdits) != 1 {
+			t.Fatalf("%s: expected one fix with one edit, got %v", name, fixes)
+		}
+		if got := string(fixes[0].TextEdits[0].NewText); got != test.guard {
+			t.Errorf("%s: got guard %q, want %q", name, got, test.guard)
+		}
+	}
+}
+
+func TestFileImports(t *testing.T) {
+	pass := newPass(t)
+	imports := &fileImports{pass: pass, file: pass.Files[0]}
+	tests := []struct {
+		pkg  *types.Package
+		want string
+	}{
+		{pass.Pkg, ""},
+		{types.NewPackage("strings", "strings"), "str"},
+		{types.NewPackage("time", "time"), "time"},
+		{types.NewPackage("encoding/json", "json"), "json"},
+		{types.NewPackage("encoding/json", "json"), "json"},
+	}
+	for _, test := range tests {
+		if got := imports.qualifier(test.pkg); got != test.want {
+			t.Errorf("qualifier(%s) = %q, want %q", test.pkg.Path(), got, test.want)
+		}
+	}
+	edits := imports.edits()
+	if len(edits) != 1 || string(edits[0].NewText) != "\n\nimport \"encoding/json\"" || edits[0].Pos != pass.Files[0].Name.End() {
+		t.Errorf("edits = %v, want one import of encoding/json after the package clause", edits)
+	}
+}
@@ -0,0 +1,471 @@
+package z3wrapper
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// ArrayValue представляет значение массива в модели: значение по умолчанию
+// и явно заданные элементы, упорядоченные по возрастанию индекса
+type ArrayValue struct {
+	Default interface{}
+	Entries []ArrayEntry
+}
+
+// ArrayEntry — явно заданный элемент массива
+type ArrayEntry struct {
+	Index interface{}
+	Value interface{}
+}
+
+// Elements возвращает первые length элементов массива с индексами 0..length-1
+func (av ArrayValue) Elements(length int) []interface{} {
+	elements := make([]interface{}, length)
+	for i := range elements {
+		elements[i] = av.Default
+	}
+	for _, entry := range av.Entries {
+		index, ok := entry.Index.(*big.Int)
+		if ok && index.IsInt64() && index.Int64() >= 0 && index.Int64() < int64(length) {
+			elements[index.Int64()] = entry.Value
+		}
+	}
+	return elements
+}
+
+// Reference представляет элемент неинтерпретируемого сорта (символьную ссылку).
+// Ссылки с одинаковыми Sort и ID обозначают один и тот же объект.
+type Reference struct {
+	Sort string
+	ID   int
+}
+
+// GetValue получает значение выражения произвольного поддерживаемого сорта:
+//
+//	Int           -> *big.Int
+//	BV            -> *big.Int (знаковая интерпретация)
+//	Bool          -> bool
+//	Real          -> *big.Rat
+//	FloatingPoint -> float64 (включая NaN и ±Inf)
+//	Array         -> ArrayValue
+//	Uninterpreted -> Reference
+func (s *Solver) GetValue(model *z3.Model, value z3.Value) (interface{}, error) {
+	switch typed := value.(type) {
+	case z3.Int:
+		return s.GetBigIntValue(model, typed)
+	case z3.BV:
+		return s.GetBVValue(model, typed, true)
+	case z3.Bool:
+		return s.GetBoolValue(model, typed)
+	case z3.Real:
+		return s.GetRealValue(model, typed)
+	case z3.Float:
+		return s.GetFloatValue(model, typed)
+	case z3.Array:
+		return s.GetArrayValue(model, typed)
+	case z3.Uninterpreted:
+		return s.GetReferenceValue(model, typed)
+	default:
+		return nil, fmt.Errorf("unsupported sort %s", value.Sort())
+	}
+}
+
+// GetBigIntValue получает значение целочисленной переменной произвольной величины
+func (s *Solver) GetBigIntValue(model *z3.Model, variable z3.Int) (*big.Int, error) {
+	value := model.Eval(variable, true)
+	if value == nil {
+		return nil, fmt.Errorf("variable not found in model")
+	}
+	result, isLiteral := value.(z3.Int).AsBigInt()
+	if !isLiteral {
+		return nil, fmt.Errorf("value %s is not an integer literal", value)
+	}
+	return result, nil
+}
+
+// GetBVValue получает значение битового вектора как знаковое или беззнаковое число
+func (s *Solver) GetBVValue(model *z3.Model, variable z3.BV, signed bool) (*big.Int, error) {
+	value := model.Eval(variable, true)
+	if value == nil {
+		return nil, fmt.Errorf("variable not found in model")
+	}
+
+	literal := value.(z3.BV)
+	var result *big.Int
+	var isLiteral bool
+	if signed {
+		result, isLiteral = literal.AsBigSigned()
+	} else {
+		result, isLiteral = literal.AsBigUnsigned()
+	}
+	if !isLiteral {
+		return nil, fmt.Errorf("value %s is not a bit-vector literal", value)
+	}
+	return result, nil
+}
+
+// GetRealValue получает значение вещественной переменной как рациональное число
+func (s *Solver) GetRealValue(model *z3.Model, variable z3.Real) (*big.Rat, error) {
+	value := model.Eval(variable, true)
+	if value == nil {
+		return nil, fmt.Errorf("variable not found in model")
+	}
+	result, isLiteral := value.(z3.Real).AsBigRat()
+	if !isLiteral {
+		return nil, fmt.Errorf("value %s is not a rational literal", value)
+	}
+	return result, nil
+}
+
+// GetFloatValue получает значение числа с плавающей точкой, включая NaN, ±Inf и -0
+func (s *Solver) GetFloatValue(model *z3.Model, variable z3.Float) (float64, error) {
+	value := model.Eval(variable, true)
+	if value == nil {
+		return 0, fmt.Errorf("variable not found in model")
+	}
+
+	result, isLiteral := value.(z3.Float).AsBigFloat()
+	if !isLiteral {
+		return 0, fmt.Errorf("value %s is not a floating-point literal", value)
+	}
+	if result == nil {
+		return math.NaN(), nil
+	}
+	if result.IsInf() {
+		return math.Inf(result.Sign()), nil
+	}
+	float, _ := result.Float64()
+	return float, nil
+}
+
+// GetStringValue получает значение строки. В go-z3 нет теории строк,
+// поэтому строка кодируется массивом байтов chars и длиной length.
+func (s *Solver) GetStringValue(model *z3.Model, chars z3.Array, length z3.Int) (string, error) {
+	size, err := s.GetBigIntValue(model, length)
+	if err != nil {
+		return "", fmt.Errorf("string length: %w", err)
+	}
+	if !size.IsInt64() || size.Sign() < 0 {
+		return "", fmt.Errorf("invalid string length %s", size)
+	}
+
+	domain, _ := chars.Sort().DomainAndRange()
+	var builder strings.Builder
+	for i := int64(0); i < size.Int64(); i++ {
+		char, err := s.GetValue(model, chars.Select(s.ctx.FromInt(i, domain)))
+		if err != nil {
+			return "", fmt.Errorf("string element %d: %w", i, err)
+		}
+		code, ok := char.(*big.Int)
+		if !ok {
+			return "", fmt.Errorf("string element %d is not a number", i)
+		}
+		builder.WriteByte(byte(code.Int64()))
+	}
+	return builder.String(), nil
+}
+
+// GetArrayValue получает значение массива: значение по умолчанию и явные элементы.
+// Поддерживаются массивы с целочисленными и битовыми индексами.
+func (s *Solver) GetArrayValue(model *z3.Model, variable z3.Array) (ArrayValue, error) {
+	value := model.Eval(variable, true)
+	if value == nil {
+		return ArrayValue{}, fmt.Errorf("variable not found in model")
+	}
+
+	domain, _ := variable.Sort().DomainAndRange()
+	if domain.Kind() != z3.KindInt && domain.Kind() != z3.KindBV {
+		return ArrayValue{}, fmt.Errorf("unsupported array index sort %s", domain)
+	}
+
+	indices, err := storedIndices(value.String(), model.String())
+	if err != nil {
+		return ArrayValue{}, err
+	}
+
+	result := ArrayValue{}
+	for _, index := range indices {
+		element, err := s.GetValue(model, variable.Select(s.ctx.FromBigInt(index, domain)))
+		if err != nil {
+			return ArrayValue{}, fmt.Errorf("array element %s: %w", index, err)
+		}
+		result.Entries = append(result.Entries, ArrayEntry{Index: index, Value: element})
+	}
+
+	// Значение по умолчанию берётся по индексу, не встречающемуся среди явных
+	outside := big.NewInt(0)
+	if len(indices) > 0 {
+		outside.Add(indices[len(indices)-1], big.NewInt(1))
+	}
+	result.Default, err = s.GetValue(model, variable.Select(s.ctx.FromBigInt(outside, domain)))
+	if err != nil {
+		return ArrayValue{}, fmt.Errorf("array default: %w", err)
+	}
+	return result, nil
+}
+
+// GetReferenceValue получает элемент неинтерпретируемого сорта
+func (s *Solver) GetReferenceValue(model *z3.Model, variable z3.Uninterpreted) (Reference, error) {
+	value := model.Eval(variable, true)
+	if value == nil {
+		return Reference{}, fmt.Errorf("variable not found in model")
+	}
+
+	// Z3 именует элементы вселенной сорта как "Sort!val!N"
+	name := value.String()
+	separator := strings.LastIndex(name, "!")
+	id, err := strconv.Atoi(name[separator+1:])
+	if separator < 0 || err != nil {
+		return Reference{}, fmt.Errorf("unexpected reference value %s", name)
+	}
+	return Reference{Sort: variable.Sort().String(), ID: id}, nil
+}
+
+// GoLiteral форматирует значение из модели как литерал Go.
+// Массивы форматируются как тело составного литерала с явными индексами,
+// к которому генератор тестов добавляет тип: []int{1: 5, 3: 7}.
+func GoLiteral(value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case int64:
+		return strconv.FormatInt(typed, 10), nil
+	case uint64:
+		return strconv.FormatUint(typed, 10), nil
+	case *big.Int:
+		return typed.String(), nil
+	case *big.Rat:
+		float, _ := typed.Float64()
+		return GoLiteral(float)
+	case float64:
+		switch {
+		case math.IsNaN(typed):
+			return "math.NaN()", nil
+		case math.IsInf(typed, 1):
+			return "math.Inf(1)", nil
+		case math.IsInf(typed, -1):
+			return "math.Inf(-1)", nil
+		case typed == 0 && math.Signbit(typed):
+			return "math.Copysign(0, -1)", nil
+		}
+		return strconv.FormatFloat(typed, 'g', -1, 64), nil
+	case string:
+		return strconv.Quote(typed), nil
+	case []interface{}:
+		elements := make([]string, 0, len(typed))
+		for _, element := range typed {
+			literal, err := GoLiteral(element)
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, literal)
+		}
+		return "{" + strings.Join(elements, ", ") + "}", nil
+	case ArrayValue:
+		elements := make([]string, 0, len(typed.Entries))
+		for _, entry := range typed.Entries {
+			index, err := GoLiteral(entry.Index)
+			if err != nil {
+				return "", err
+			}
+			element, err := GoLiteral(entry.Value)
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, index+": "+element)
+		}
+		return "{" + strings.Join(elements, ", ") + "}", nil
+	default:
+		return "", fmt.Errorf("value of type %T has no Go literal", value)
+	}
+}
+
+// storedIndices разбирает интерпретацию массива вида
+// (store (store ((as const (Array Int Int)) 0) 1 5) 2 7) или
+// (_ as-array k!0) и возвращает индексы явно заданных элементов по
+// возрастанию. Интерпретация функции k!0 ищется в тексте модели modelText.
+func storedIndices(text string, modelText string) ([]*big.Int, error) {
+	expr, _, err := parseSExpr(text)
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := expr.([]interface{}); ok && len(list) == 3 && list[0] == "_" && list[1] == "as-array" {
+		name, _ := list[2].(string)
+		return functionIndices(name, modelText)
+	}
+
+	var indices []*big.Int
+	for {
+		list, ok := expr.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unsupported array interpretation %s", text)
+		}
+		if len(list) == 2 && isConstArray(list[0]) {
+			break
+		}
+		if len(list) != 4 || list[0] != "store" {
+			return nil, fmt.Errorf("unsupported array interpretation %s", text)
+		}
+
+		index, err := parseNumeral(list[2])
+		if err != nil {
+			return nil, err
+		}
+		indices = append(indices, index)
+		expr = list[1]
+	}
+	return sortedIndices(indices), nil
+}
+
+// functionIndices находит в тексте модели интерпретацию функции name
+//
+//	k!0 -> {
+//	  1 -> 5
+//	  3 -> 7
+//	  else -> (ite (= x!0 4) 9 0)
+//	}
+//
+// и возвращает аргументы её явных записей вместе с числами, с которыми
+// аргумент сравнивается в ветке else
+func functionIndices(name string, modelText string) ([]*big.Int, error) {
+	lines := strings.Split(modelText, "\n")
+	start := slices.Index(lines, name+" -> {")
+	if start < 0 {
+		return nil, fmt.Errorf("interpretation of %s not found in model", name)
+	}
+
+	var indices []*big.Int
+	for _, line := range lines[start+1:] {
+		line = strings.TrimSpace(line)
+		if line == "}" {
+			return sortedIndices(indices), nil
+		}
+		argument, value, found := strings.Cut(line, " -> ")
+		if !found {
+			// Функция без явных записей печатается одним значением else
+			argument, value = "else", line
+		}
+		if argument != "else" {
+			expr, _, err := parseSExpr(argument)
+			if err != nil {
+				return nil, err
+			}
+			index, err := parseNumeral(expr)
+			if err != nil {
+				return nil, err
+			}
+			indices = append(indices, index)
+			continue
+		}
+		expr, _, err := parseSExpr(value)
+		if err != nil {
+			return nil, err
+		}
+		indices = append(indices, comparedNumerals(expr)...)
+	}
+	return nil, fmt.Errorf("unterminated interpretation of %s", name)
+}
+
+// comparedNumerals собирает числа из равенств (= x!0 N) выражения
+func comparedNumerals(expr interface{}) []*big.Int {
+	list, ok := expr.([]interface{})
+	if !ok {
+		return nil
+	}
+	if len(list) == 3 && list[0] == "=" {
+		for _, operand := range list[1:] {
+			if number, err := parseNumeral(operand); err == nil {
+				return []*big.Int{number}
+			}
+		}
+	}
+	var numbers []*big.Int
+	for _, item := range list {
+		numbers = append(numbers, comparedNumerals(item)...)
+	}
+	return numbers
+}
+
+// sortedIndices упорядочивает индексы по возрастанию и убирает повторы
+func sortedIndices(indices []*big.Int) []*big.Int {
+	sort.Slice(indices, func(i, j int) bool { return indices[i].Cmp(indices[j]) < 0 })
+	return slices.CompactFunc(indices, func(a, b *big.Int) bool { return a.Cmp(b) == 0 })
+}
+
+// isConstArray проверяет, что выражение имеет вид (as const SORT)
+func isConstArray(expr interface{}) bool {
+	list, ok := expr.([]interface{})
+	return ok && len(list) == 3 && list[0] == "as" && list[1] == "const"
+}
+
+// parseNumeral разбирает целочисленный литерал: 5, (- 5), #x05, #b101, (_ bv5 8)
+func parseNumeral(expr interface{}) (*big.Int, error) {
+	switch typed := expr.(type) {
+	case string:
+		base, digits := 10, typed
+		switch {
+		case strings.HasPrefix(typed, "#x"):
+			base, digits = 16, typed[2:]
+		case strings.HasPrefix(typed, "#b"):
+			base, digits = 2, typed[2:]
+		}
+		if number, ok := new(big.Int).SetString(digits, base); ok {
+			return number, nil
+		}
+	case []interface{}:
+		if len(typed) == 2 && typed[0] == "-" {
+			number, err := parseNumeral(typed[1])
+			if err != nil {
+				return nil, err
+			}
+			return number.Neg(number), nil
+		}
+		if len(typed) == 3 && typed[0] == "_" {
+			if digits, ok := typed[1].(string); ok && strings.HasPrefix(digits, "bv") {
+				return parseNumeral(strings.TrimPrefix(digits, "bv"))
+			}
+		}
+	}
+	return nil, fmt.Errorf("unsupported numeral %v", expr)
+}
+
+// parseSExpr разбирает S-выражение: атомы — string, списки — []interface{}
+func parseSExpr(text string) (interface{}, string, error) {
+	text = strings.TrimLeft(text, " \t\r\n")
+	if text == "" {
+		return nil, "", fmt.Errorf("unexpected end of expression")
+	}
+
+	if text[0] != '(' {
+		end := strings.IndexAny(text, " \t\r\n()")
+		if end < 0 {
+			end = len(text)
+		}
+		return text[:end], text[end:], nil
+	}
+
+	list := []interface{}{}
+	rest := text[1:]
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n")
+		if rest == "" {
+			return nil, "", fmt.Errorf("unbalanced parentheses in %s", text)
+		}
+		if rest[0] == ')' {
+			return list, rest[1:], nil
+		}
+		item, tail, err := parseSExpr(rest)
+		if err != nil {
+			return nil, "", err
+		}
+		list = append(list, item)
+		rest = tail
+	}
+}
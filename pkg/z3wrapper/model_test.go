@@ -0,0 +1,193 @@
+package z3wrapper
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+func TestGetBigIntAndBVValues(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+	ctx := solver.Context()
+
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	x := solver.CreateIntVar("x")
+	b := ctx.BVConst("b", 8)
+	solver.Assert(x.Eq(ctx.FromBigInt(huge, ctx.IntSort()).(z3.Int)))
+	solver.Assert(b.Eq(ctx.FromInt(-1, ctx.BVSort(8)).(z3.BV)))
+
+	if result := solver.Check(); result != SAT {
+		t.Fatalf("Expected sat, got %s", result)
+	}
+	model := solver.Model()
+
+	xVal, err := solver.GetBigIntValue(model, x)
+	if err != nil || xVal.Cmp(huge) != 0 {
+		t.Errorf("Expected x = %s, got %v (%v)", huge, xVal, err)
+	}
+	if _, err := solver.GetIntValue(model, x); err == nil {
+		t.Error("Expected overflow error for int64 extraction")
+	}
+
+	signed, err := solver.GetBVValue(model, b, true)
+	if err != nil || signed.Int64() != -1 {
+		t.Errorf("Expected signed b = -1, got %v (%v)", signed, err)
+	}
+	unsigned, err := solver.GetBVValue(model, b, false)
+	if err != nil || unsigned.Int64() != 255 {
+		t.Errorf("Expected unsigned b = 255, got %v (%v)", unsigned, err)
+	}
+}
+
+func TestGetFloatSpecialValues(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+	ctx := solver.Context()
+
+	double := ctx.FloatSort(11, 53)
+	nan := ctx.Const("nan", double).(z3.Float)
+	inf := ctx.Const("inf", double).(z3.Float)
+	half := ctx.Const("half", double).(z3.Float)
+	solver.Assert(nan.IsNaN())
+	solver.Assert(inf.IsInfinite())
+	solver.Assert(inf.IsNegative())
+	solver.Assert(half.Eq(ctx.FromFloat64(0.5, double)))
+
+	if result := solver.Check(); result != SAT {
+		t.Fatalf("Expected sat, got %s", result)
+	}
+	model := solver.Model()
+
+	if value, err := solver.GetFloatValue(model, nan); err != nil || !math.IsNaN(value) {
+		t.Errorf("Expected NaN, got %v (%v)", value, err)
+	}
+	if value, err := solver.GetFloatValue(model, inf); err != nil || !math.IsInf(value, -1) {
+		t.Errorf("Expected -Inf, got %v (%v)", value, err)
+	}
+	if value, err := solver.GetFloatValue(model, half); err != nil || value != 0.5 {
+		t.Errorf("Expected 0.5, got %v (%v)", value, err)
+	}
+}
+
+func TestGetArrayAndStringValues(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+	ctx := solver.Context()
+
+	array := ctx.Const("array", ctx.ArraySort(ctx.IntSort(), ctx.IntSort())).(z3.Array)
+	solver.Assert(array.Select(solver.CreateIntLit(1)).(z3.Int).Eq(solver.CreateIntLit(5)))
+	solver.Assert(array.Select(solver.CreateIntLit(3)).(z3.Int).Eq(solver.CreateIntLit(7)))
+
+	chars := ctx.Const("chars", ctx.ArraySort(ctx.IntSort(), ctx.BVSort(8))).(z3.Array)
+	length := solver.CreateIntVar("length")
+	solver.Assert(length.Eq(solver.CreateIntLit(2)))
+	solver.Assert(chars.Select(solver.CreateIntLit(0)).(z3.BV).Eq(ctx.FromInt('o', ctx.BVSort(8)).(z3.BV)))
+	solver.Assert(chars.Select(solver.CreateIntLit(1)).(z3.BV).Eq(ctx.FromInt('k', ctx.BVSort(8)).(z3.BV)))
+
+	if result := solver.Check(); result != SAT {
+		t.Fatalf("Expected sat, got %s", result)
+	}
+	model := solver.Model()
+
+	value, err := solver.GetArrayValue(model, array)
+	if err != nil {
+		t.Fatalf("Error getting array value: %v", err)
+	}
+	elements := value.Elements(4)
+	if elements[1].(*big.Int).Int64() != 5 || elements[3].(*big.Int).Int64() != 7 {
+		t.Errorf("Expected elements 1 and 3 to be 5 and 7, got %v", elements)
+	}
+
+	str, err := solver.GetStringValue(model, chars, length)
+	if err != nil || str != "ok" {
+		t.Errorf("Expected \"ok\", got %q (%v)", str, err)
+	}
+}
+
+func TestStoredIndices(t *testing.T) {
+	model := "a -> (_ as-array k!0)\n" +
+		"k!0 -> {\n  3 -> 7\n  (- 1) -> 5\n  3 -> 8\n  else -> (ite (= x!0 10) 2 0)\n}\n" +
+		"k!1 -> {\n  4\n}\n"
+	cases := []struct {
+		text     string
+		expected string
+		ok       bool
+	}{
+		{"(store (store ((as const (Array Int Int)) 0) 2 7) 1 5)", "[1 2]", true},
+		{"(store ((as const (Array Int Int)) 0) #x02 7)", "[2]", true},
+		{"(_ as-array k!0)", "[-1 3 10]", true},
+		{"(_ as-array k!1)", "[]", true},
+		{"(_ as-array k!2)", "", false},
+		{"(lambda ((x Int)) 0)", "", false},
+	}
+
+	for _, c := range cases {
+		indices, err := storedIndices(c.text, model)
+		if (err == nil) != c.ok {
+			t.Errorf("%s: unexpected error %v", c.text, err)
+			continue
+		}
+		if c.ok && fmt.Sprint(indices) != c.expected {
+			t.Errorf("%s: expected indices %s, got %v", c.text, c.expected, indices)
+		}
+	}
+}
+
+func TestGetReferenceValues(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+	ctx := solver.Context()
+
+	ref := ctx.UninterpretedSort("Ref")
+	p := ctx.Const("p", ref).(z3.Uninterpreted)
+	q := ctx.Const("q", ref).(z3.Uninterpreted)
+	r := ctx.Const("r", ref).(z3.Uninterpreted)
+	solver.Assert(p.NE(q))
+	solver.Assert(p.Eq(r))
+
+	if result := solver.Check(); result != SAT {
+		t.Fatalf("Expected sat, got %s", result)
+	}
+	model := solver.Model()
+
+	pVal, _ := solver.GetReferenceValue(model, p)
+	qVal, _ := solver.GetReferenceValue(model, q)
+	rVal, err := solver.GetReferenceValue(model, r)
+	if err != nil {
+		t.Fatalf("Error getting reference value: %v", err)
+	}
+	if pVal == qVal || pVal != rVal {
+		t.Errorf("Expected p == r != q, got p=%v q=%v r=%v", pVal, qVal, rVal)
+	}
+}
+
+func TestGoLiteral(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{true, "true"},
+		{big.NewInt(-42), "-42"},
+		{math.NaN(), "math.NaN()"},
+		{math.Inf(-1), "math.Inf(-1)"},
+		{0.25, "0.25"},
+		{"a\"b", `"a\"b"`},
+		{[]interface{}{big.NewInt(1), big.NewInt(2)}, "{1, 2}"},
+		{ArrayValue{Default: big.NewInt(0), Entries: []ArrayEntry{{big.NewInt(2), big.NewInt(9)}}}, "{2: 9}"},
+	}
+
+	for _, c := range cases {
+		literal, err := GoLiteral(c.value)
+		if err != nil {
+			t.Errorf("Error formatting %v: %v", c.value, err)
+			continue
+		}
+		if literal != c.expected {
+			t.Errorf("Expected %s, got %s", c.expected, literal)
+		}
+	}
+}
@@ -3,15 +3,41 @@
 package z3wrapper
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ebukreev/go-z3/z3"
-	"strconv"
+	"math"
+	"time"
 )
 
 // Solver представляет обёртку над Z3 solver
 type Solver struct {
-	ctx    *z3.Context
-	solver *z3.Solver
+	ctx           *z3.Context
+	solver        *z3.Solver
+	reasonUnknown string
+}
+
+// Result представляет результат проверки выполнимости
+type Result int
+
+const (
+	UNSAT Result = iota
+	SAT
+	UNKNOWN // solver не смог определить выполнимость (тайм-аут, лимит ресурсов, неполнота теории)
+)
+
+// String возвращает строковое представление результата
+func (r Result) String() string {
+	switch r {
+	case UNSAT:
+		return "unsat"
+	case SAT:
+		return "sat"
+	case UNKNOWN:
+		return "unknown"
+	default:
+		return "invalid"
+	}
 }
 
 // NewSolver создаёт новый экземпляр Z3 solver
@@ -26,6 +52,15 @@ func NewSolver() *Solver {
 	}
 }
 
+// NewSolverWithContext создаёт solver в существующем контексте Z3,
+// например, в контексте транслятора символьных выражений
+func NewSolverWithContext(ctx *z3.Context) *Solver {
+	return &Solver{
+		ctx:    ctx,
+		solver: z3.NewSolver(ctx),
+	}
+}
+
 // Close освобождает ресурсы solver'а
 func (s *Solver) Close() {
 	// В этой версии Z3 нет метода Close для solver и context
@@ -42,10 +77,45 @@ func (s *Solver) Assert(constraint z3.Bool) {
 	s.solver.Assert(constraint)
 }
 
-// Check проверяет выполнимость текущих ограничений
-func (s *Solver) Check() (bool, error) {
+// Check проверяет выполнимость текущих ограничений.
+// Тайм-аут и исчерпание лимита ресурсов дают UNKNOWN, причину
+// можно получить через ReasonUnknown.
+func (s *Solver) Check() Result {
+	s.reasonUnknown = ""
 	sat, err := s.solver.Check()
-	return sat, err
+
+	var unknown *z3.ErrSatUnknown
+	switch {
+	case errors.As(err, &unknown):
+		s.reasonUnknown = unknown.Reason
+		return UNKNOWN
+	case sat:
+		return SAT
+	default:
+		return UNSAT
+	}
+}
+
+// ReasonUnknown возвращает причину последнего результата UNKNOWN
+func (s *Solver) ReasonUnknown() string {
+	return s.reasonUnknown
+}
+
+// SetTimeout задаёт ограничение времени на один Check (0 — без ограничения).
+// Параметр относится к контексту и действует на все его solver'ы.
+func (s *Solver) SetTimeout(timeout time.Duration) {
+	milliseconds := uint(math.MaxUint32)
+	if timeout > 0 {
+		milliseconds = uint(timeout.Milliseconds())
+	}
+	s.ctx.Config().SetUint("timeout", milliseconds)
+}
+
+// SetResourceLimit задаёт детерминированный лимит ресурсов Z3 на один Check
+// (0 — без ограничения). В отличие от тайм-аута не зависит от нагрузки машины.
+// Параметр относится к контексту и действует на все его solver'ы.
+func (s *Solver) SetResourceLimit(limit uint) {
+	s.ctx.Config().SetUint("rlimit", limit)
 }
 
 // Model возвращает модель, если ограничения выполнимы
@@ -78,43 +148,41 @@ func (s *Solver) CreateIntLit(value int64) z3.Int {
 	return s.ctx.FromInt(value, s.ctx.IntSort()).(z3.Int)
 }
 
-// IsSatisfiable проверяет, выполнимы ли текущие ограничения
+// IsSatisfiable проверяет, выполнимы ли текущие ограничения.
+// Результат UNKNOWN возвращается как ошибка.
 func (s *Solver) IsSatisfiable() (bool, error) {
-	return s.solver.Check()
+	switch s.Check() {
+	case SAT:
+		return true, nil
+	case UNSAT:
+		return false, nil
+	default:
+		return false, fmt.Errorf("satisfiability unknown: %s", s.reasonUnknown)
+	}
 }
 
 // GetIntValue получает значение целочисленной переменной из модели
 func (s *Solver) GetIntValue(model *z3.Model, variable z3.Int) (int64, error) {
-	value := model.Eval(variable, false)
-	if value == nil {
-		return 0, fmt.Errorf("variable not found in model")
-	}
-
-	// Конвертируем в строку и затем в int64
-	str := value.String()
-	result, err := strconv.ParseInt(str, 10, 64)
+	value, err := s.GetBigIntValue(model, variable)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse integer value: %v", err)
+		return 0, err
 	}
-
-	return result, nil
+	if !value.IsInt64() {
+		return 0, fmt.Errorf("integer value %s does not fit into int64", value)
+	}
+	return value.Int64(), nil
 }
 
 // GetBoolValue получает значение булевой переменной из модели
 func (s *Solver) GetBoolValue(model *z3.Model, variable z3.Bool) (bool, error) {
-	value := model.Eval(variable, false)
+	value := model.Eval(variable, true)
 	if value == nil {
 		return false, fmt.Errorf("variable not found in model")
 	}
 
-	// Проверяем строковое представление
-	str := value.String()
-	switch str {
-	case "true":
-		return true, nil
-	case "false":
-		return false, nil
-	default:
-		return false, fmt.Errorf("unexpected boolean value: %s", str)
+	result, isLiteral := value.(z3.Bool).AsBool()
+	if !isLiteral {
+		return false, fmt.Errorf("unexpected boolean value: %s", value)
 	}
+	return result, nil
 }
@@ -2,6 +2,9 @@ package z3wrapper
 
 import (
 	"testing"
+	"time"
+
+	"github.com/ebukreev/go-z3/z3"
 )
 
 func TestSolverBasicOperations(t *testing.T) {
@@ -148,3 +151,69 @@ func TestBooleanOperations(t *testing.T) {
 		t.Errorf("Expected b = false, got %v", bVal)
 	}
 }
+
+// assertFactorization добавляет трудную для solver'а задачу разложения
+// 64-битного числа на два множителя
+func assertFactorization(solver *Solver) {
+	ctx := solver.Context()
+	x := ctx.BVConst("x", 64)
+	y := ctx.BVConst("y", 64)
+	one := ctx.FromInt(1, ctx.BVSort(64)).(z3.BV)
+	bound := ctx.FromInt(4000000000, ctx.BVSort(64)).(z3.BV)
+
+	solver.Assert(x.Mul(y).Eq(ctx.FromInt(1000000016000000063, ctx.BVSort(64)).(z3.BV)))
+	solver.Assert(x.UGT(one))
+	solver.Assert(y.UGT(one))
+	solver.Assert(x.ULT(bound))
+	solver.Assert(y.ULT(bound))
+}
+
+// assertFermatCubes добавляет x³ + y³ = z³ в положительных целых: по
+// теореме Ферма решений нет, но доказать это solver не может, поэтому
+// проверка завершается только по тайм-ауту
+func assertFermatCubes(solver *Solver) {
+	x, y, z := solver.CreateIntVar("x"), solver.CreateIntVar("y"), solver.CreateIntVar("z")
+	zero := solver.CreateIntLit(0)
+	solver.Assert(x.GT(zero))
+	solver.Assert(y.GT(zero))
+	solver.Assert(z.GT(zero))
+	solver.Assert(x.Mul(x, x).Add(y.Mul(y, y)).Eq(z.Mul(z, z)))
+}
+
+func TestSolverTimeoutUnknown(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+
+	assertFermatCubes(solver)
+	solver.SetTimeout(100 * time.Millisecond)
+
+	if result := solver.Check(); result != UNKNOWN {
+		t.Fatalf("Expected unknown after timeout, got %s", result)
+	}
+	if solver.ReasonUnknown() == "" {
+		t.Error("Expected non-empty reason for unknown result")
+	}
+	if _, err := solver.IsSatisfiable(); err == nil {
+		t.Error("Expected IsSatisfiable to report unknown result as error")
+	}
+}
+
+func TestSolverResourceLimit(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+
+	assertFactorization(solver)
+	solver.SetResourceLimit(10000)
+
+	if result := solver.Check(); result != UNKNOWN {
+		t.Fatalf("Expected unknown after exceeding resource limit, got %s", result)
+	}
+
+	// Снятие лимита не должно мешать простым запросам
+	solver.SetResourceLimit(0)
+	other := NewSolverWithContext(solver.Context())
+	other.Assert(solver.CreateIntVar("z").Eq(solver.CreateIntLit(3)))
+	if result := other.Check(); result != SAT {
+		t.Fatalf("Expected sat without resource limit, got %s", result)
+	}
+}